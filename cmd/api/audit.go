@@ -0,0 +1,102 @@
+// Filename: cmd/api/audit.go
+package main
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// recordAudit adds a change made by the current request to the audit log. before and after are the
+// entity as it was and as it is now; pass nil for before when creating and for after when deleting.
+// The change has already been saved by the time this runs, so a failure to record it is logged rather
+// than reported to the client.
+func (app *appDependencies) recordAudit(r *http.Request, entityType string, entityID int64, action string, before, after any) {
+	changes, err := data.AuditChanges(before, after)
+	if err != nil {
+		app.logError(r, err)
+		return
+	}
+
+	event := &data.AuditEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+		RequestID:  app.contextGetRequestID(r),
+		IPAddress:  clientIP(r),
+	}
+
+	// Registration and password resets happen before anyone is signed in
+	if user, ok := r.Context().Value(contextKeyUser).(*data.User); ok && !user.IsAnonymous() {
+		event.ActorID = &user.ID
+	}
+
+	if err := app.models.Audit.Insert(event); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// clientIP returns the address of the client that sent the request, without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// listAuditEventsHandler returns the audit log, newest first, filtered by actor, entity, action and date
+//
+//	@Summary		List audit events
+//	@Description	Retrieve recorded changes with optional filtering by actor, entity, action and date range
+//	@Tags			audit
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			actor_id	query		int		false	"Filter by the user who made the change"
+//	@Param			entity_type	query		string	false	"Filter by entity type, such as officer or training_enrollment"
+//	@Param			entity_id	query		int		false	"Filter by entity ID"
+//	@Param			action		query		string	false	"Filter by action, such as create, update or delete"
+//	@Param			from		query		string	false	"Only changes on or after this date (YYYY-MM-DD)"
+//	@Param			to			query		string	false	"Only changes on or before this date (YYYY-MM-DD)"
+//	@Success		200			{object}	envelope
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/audit [get]
+func (app *appDependencies) listAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	v := validator.New()
+
+	auditFilters := data.AuditFilters{
+		ActorID:    app.getOptionalInt64QueryParameter(query, "actor_id", v),
+		EntityType: app.getSingleQueryParameter(query, "entity_type", ""),
+		EntityID:   app.getOptionalInt64QueryParameter(query, "entity_id", v),
+		Action:     app.getSingleQueryParameter(query, "action", ""),
+		From:       app.getOptionalDateQueryParameter(query, "from", v),
+		To:         app.getOptionalDateQueryParameter(query, "to", v),
+	}
+
+	if auditFilters.From != nil && auditFilters.To != nil {
+		v.Check(!auditFilters.To.Before(*auditFilters.From), "to", "must not be before from")
+	}
+
+	filters := app.readFilters(query, "-created_at", 20, []string{"id", "created_at", "entity_type", "action", "-id", "-created_at", "-entity_type", "-action"}, v)
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	events, metadata, err := app.models.Audit.GetAll(auditFilters, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"audit_events": events, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+)
+
+func TestListAuditEventsHandler(t *testing.T) {
+	t.Log("=== Testing List Audit Events Handler ===")
+
+	officer, user, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(user.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	officerID := strconv.FormatInt(officer.ID, 10)
+	newRegulationNumber := officer.RegulationNumber + "A"
+
+	// Update the officer through the request ID middleware so the event is tied to the request
+	body, _ := json.Marshal(map[string]any{"regulation_number": newRegulationNumber})
+	req := httptest.NewRequest(http.MethodPatch, "/v1/officers/"+officerID, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "audit-test-"+officerID)
+	req = setURLParam(req, "id", officerID)
+	req = setUserContext(req, adminUser)
+
+	rec := httptest.NewRecorder()
+	testApp.requestID(http.HandlerFunc(testApp.updateOfficerHandler)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Failed to update test officer: status %d", rec.Code)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{
+			name:           "Filter by entity",
+			query:          fmt.Sprintf("entity_type=%s&entity_id=%d&action=update", data.AuditEntityOfficer, officer.ID),
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Filter by actor and entity",
+			query:          fmt.Sprintf("actor_id=%d&entity_type=%s&entity_id=%d", adminUser.ID, data.AuditEntityOfficer, officer.ID),
+			expectedStatus: http.StatusOK,
+			expectedCount:  1,
+		},
+		{
+			name:           "Date range before the change",
+			query:          fmt.Sprintf("entity_type=%s&entity_id=%d&to=2000-01-01", data.AuditEntityOfficer, officer.ID),
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name:           "End date before start date",
+			query:          "from=2025-02-01&to=2025-01-01",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid actor ID",
+			query:          "actor_id=abc",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/audit?"+tt.query, nil)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.listAuditEventsHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				AuditEvents []*data.AuditEvent `json:"audit_events"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(response.AuditEvents) != tt.expectedCount {
+				t.Fatalf("Expected %d audit events; got %d", tt.expectedCount, len(response.AuditEvents))
+			}
+
+			for _, event := range response.AuditEvents {
+				if event.ActorID == nil || *event.ActorID != adminUser.ID {
+					t.Errorf("Expected actor %d; got %v", adminUser.ID, event.ActorID)
+				}
+				if event.RequestID != "audit-test-"+officerID {
+					t.Errorf("Expected request ID %q; got %q", "audit-test-"+officerID, event.RequestID)
+				}
+
+				var changes struct {
+					Before map[string]any `json:"before"`
+					After  map[string]any `json:"after"`
+				}
+				if err := json.Unmarshal(event.Changes, &changes); err != nil {
+					t.Fatalf("Failed to decode changes: %v", err)
+				}
+				if changes.Before["regulation_number"] != officer.RegulationNumber || changes.After["regulation_number"] != newRegulationNumber {
+					t.Errorf("Expected regulation number change to be recorded; got %s", event.Changes)
+				}
+				if _, ok := changes.After["rank_id"]; ok {
+					t.Error("Expected unchanged fields to be left out of the diff")
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Filename: cmd/api/bulk_enrollments.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// bulkEnrollSessionHandler enrolls a list of officers, or every officer matching a selector, into a
+// session in one transaction and reports the outcome for each officer
+//
+//	@Summary		Bulk enroll officers in a session
+//	@Description	Enroll up to 500 officers listed in officer_ids, or every officer matching formation_id, posting_id, rank_id or region_id, in one transaction. Each officer is reported as enrolled, waitlisted, already_enrolled or rejected, with a summary of the counts. Officers who would be double booked are rejected unless override_schedule_clash is set by a user allowed to override clashes.
+//	@Tags			training-sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int							true	"Training session ID"
+//	@Param			enrollments	body		BulkEnrollSessionRequest_T	true	"Officers to enroll"
+//	@Success		200			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/sessions/{id}/enrollments/bulk [post]
+func (app *appDependencies) bulkEnrollSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		OfficerIDs            []int64 `json:"officer_ids"`
+		FormationID           *int64  `json:"formation_id"`
+		PostingID             *int64  `json:"posting_id"`
+		RankID                *int64  `json:"rank_id"`
+		RegionID              *int64  `json:"region_id"`
+		EnrollmentStatusID    int64   `json:"enrollment_status_id"`
+		ProgressStatusID      int64   `json:"progress_status_id"`
+		OverrideScheduleClash bool    `json:"override_schedule_clash"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hasSelector := input.FormationID != nil || input.PostingID != nil || input.RankID != nil || input.RegionID != nil
+
+	v := validator.New()
+	v.Check(len(input.OfficerIDs) > 0 || hasSelector, "officer_ids", "must be provided, or use formation_id, posting_id, rank_id or region_id")
+	v.Check(len(input.OfficerIDs) == 0 || !hasSelector, "officer_ids", "must not be combined with a selector")
+	v.Check(len(input.OfficerIDs) <= data.MaxBulkEnrollment, "officer_ids", fmt.Sprintf("must not contain more than %d officers", data.MaxBulkEnrollment))
+	for _, id := range input.OfficerIDs {
+		if id < 1 {
+			v.AddError("officer_ids", "must only contain positive IDs")
+			break
+		}
+	}
+	v.Check(input.EnrollmentStatusID >= 0, "enrollment_status_id", "must be a positive integer")
+	v.Check(input.ProgressStatusID >= 0, "progress_status_id", "must be a positive integer")
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	scope := app.contextGetScope(r)
+
+	_, err = app.models.TrainingSession.GetInScope(sessionID, scope)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	officerIDs := input.OfficerIDs
+	if scope.Limited() && !hasSelector {
+		outside, err := app.models.Officer.OutsideScope(officerIDs, scope)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		v.Check(len(outside) == 0, "officer_ids", fmt.Sprintf("officers %v are not in a region or formation you are assigned to", outside))
+
+		if !v.IsEmpty() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	if hasSelector {
+		officerIDs, err = app.selectOfficerIDs(input.RankID, input.PostingID, input.FormationID, input.RegionID, scope)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		v.Check(len(officerIDs) > 0, "officer_ids", "no officers match the selector")
+		v.Check(len(officerIDs) <= data.MaxBulkEnrollment, "officer_ids", fmt.Sprintf("the selector matches more than %d officers", data.MaxBulkEnrollment))
+
+		if !v.IsEmpty() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	// Unknown statuses would otherwise be reported against every officer as a missing record
+	if err := app.checkBulkEnrollmentStatuses(v, input.EnrollmentStatusID, input.ProgressStatusID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.OverrideScheduleClash && !app.canOverrideScheduleClash(w, r) {
+		return
+	}
+
+	results, err := app.models.TrainingEnrollment.BulkInsert(sessionID, input.EnrollmentStatusID, input.ProgressStatusID, officerIDs, input.OverrideScheduleClash)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrForeignKeyViolation):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	summary := map[string]int{
+		data.BulkEnrollmentEnrolled:        0,
+		data.BulkEnrollmentWaitlisted:      0,
+		data.BulkEnrollmentAlreadyEnrolled: 0,
+		data.BulkEnrollmentRejected:        0,
+	}
+	for _, result := range results {
+		summary[result.Status]++
+		// Officers who were already enrolled keep their existing enrollment, so only new ones are recorded
+		if result.Status == data.BulkEnrollmentEnrolled || result.Status == data.BulkEnrollmentWaitlisted {
+			app.recordAudit(r, data.AuditEntityTrainingEnrollment, *result.EnrollmentID, data.AuditActionCreate, nil, result)
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"results": results, "summary": summary}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// checkBulkEnrollmentStatuses records an error for an enrollment or progress status that does not exist.
+// Zero IDs are left for BulkInsert to default.
+func (app *appDependencies) checkBulkEnrollmentStatuses(v *validator.Validator, enrollmentStatusID, progressStatusID int64) error {
+	if enrollmentStatusID > 0 {
+		if _, err := app.models.EnrollmentStatus.Get(enrollmentStatusID); err != nil {
+			if !errors.Is(err, data.ErrRecordNotFound) {
+				return err
+			}
+			v.AddError("enrollment_status_id", "must be an existing enrollment status")
+		}
+	}
+
+	if progressStatusID > 0 {
+		if _, err := app.models.ProgressStatus.Get(progressStatusID); err != nil {
+			if !errors.Is(err, data.ErrRecordNotFound) {
+				return err
+			}
+			v.AddError("progress_status_id", "must be an existing progress status")
+		}
+	}
+
+	return nil
+}
+
+// selectOfficerIDs pages through every officer matching the selector. It stops once more than
+// data.MaxBulkEnrollment officers are found, so callers can reject selectors that are too broad. Officers
+// outside scope are never selected.
+func (app *appDependencies) selectOfficerIDs(rankID, postingID, formationID, regionID *int64, scope *data.Scope) ([]int64, error) {
+	filters := data.Filters{Page: 1, PageSize: 100, Sort: "id", SortSafelist: []string{"id"}, Scope: scope}
+
+	ids := []int64{}
+
+	for {
+		officers, metadata, err := app.models.Officer.GetAll("", rankID, postingID, formationID, regionID, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, officer := range officers {
+			ids = append(ids, officer.ID)
+		}
+
+		if filters.Page >= metadata.LastPage || len(ids) > data.MaxBulkEnrollment {
+			return ids, nil
+		}
+
+		filters.Page++
+	}
+}
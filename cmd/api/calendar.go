@@ -0,0 +1,232 @@
+// Filename: cmd/api/calendar.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/calendar"
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// listTrainingSessionsCalendarHandler publishes training sessions as an iCalendar feed, filtered like
+// listTrainingSessionsHandler
+//
+//	@Summary		Training sessions calendar feed
+//	@Description	Publish training sessions as an RFC 5545 iCalendar feed, filtered like the session list. Calendar apps can subscribe by passing a calendar token in the token query parameter instead of an Authorization header.
+//	@Tags			calendar
+//	@Produce		text/calendar
+//	@Security		ApiKeyAuth
+//	@Param			token				query		string	false	"Calendar token, for subscribing without an Authorization header"
+//	@Param			from				query		string	false	"Earliest session date (YYYY-MM-DD, default a year ago)"
+//	@Param			facilitator_id		query		int		false	"Filter by facilitator ID"
+//	@Param			workshop_id			query		int		false	"Filter by workshop ID"
+//	@Param			formation_id		query		int		false	"Filter by formation ID"
+//	@Param			region_id			query		int		false	"Filter by region ID"
+//	@Param			training_status_id	query		int		false	"Filter by training status ID"
+//	@Param			session_date		query		string	false	"Filter by session date (YYYY-MM-DD)"
+//	@Success		200					{string}	string
+//	@Failure		401					{object}	errorResponse
+//	@Failure		403					{object}	errorResponse
+//	@Failure		422					{object}	errorResponse
+//	@Failure		500					{object}	errorResponse
+//	@Router			/v1/training/sessions.ics [get]
+func (app *appDependencies) listTrainingSessionsCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	v := validator.New()
+
+	filters := data.CalendarFilters{
+		FacilitatorID: app.getOptionalInt64QueryParameter(query, "facilitator_id", v),
+		WorkshopID:    app.getOptionalInt64QueryParameter(query, "workshop_id", v),
+		FormationID:   app.getOptionalInt64QueryParameter(query, "formation_id", v),
+		RegionID:      app.getOptionalInt64QueryParameter(query, "region_id", v),
+		StatusID:      app.getOptionalInt64QueryParameter(query, "training_status_id", v),
+		SessionDate:   app.getOptionalDateQueryParameter(query, "session_date", v),
+		From:          app.calendarFrom(query, v),
+		Scope:         app.contextGetScope(r),
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	app.writeCalendar(w, r, "Training Sessions", "training-sessions", filters)
+}
+
+// showFacilitatorCalendarHandler publishes the sessions a user facilitates as an iCalendar feed, limited
+// to the regions and formations of the caller's scope
+//
+//	@Summary		Facilitator calendar feed
+//	@Description	Publish the sessions a user facilitates as an RFC 5545 iCalendar feed, limited to the regions and formations of the caller's scope. Calendar apps can subscribe by passing a calendar token in the token query parameter.
+//	@Tags			calendar
+//	@Produce		text/calendar
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int		true	"User ID"
+//	@Param			token	query		string	false	"Calendar token, for subscribing without an Authorization header"
+//	@Param			from	query		string	false	"Earliest session date (YYYY-MM-DD, default a year ago)"
+//	@Success		200		{string}	string
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/users/{id}/facilitation.ics [get]
+func (app *appDependencies) showFacilitatorCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	v := validator.New()
+	filters := data.CalendarFilters{
+		FacilitatorID: &user.ID,
+		From:          app.calendarFrom(r.URL.Query(), v),
+		Scope:         app.contextGetScope(r),
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	name := fmt.Sprintf("Facilitation - %s %s", user.FirstName, user.LastName)
+	app.writeCalendar(w, r, name, "facilitation-"+strconv.FormatInt(user.ID, 10), filters)
+}
+
+// showOfficerCalendarHandler publishes the sessions an officer is enrolled in as an iCalendar feed.
+// Waitlisted places are marked tentative, and cancelled or withdrawn enrollments as cancelled.
+//
+//	@Summary		Officer schedule calendar feed
+//	@Description	Publish the sessions an officer is enrolled in as an RFC 5545 iCalendar feed. Waitlisted places are marked tentative, and cancelled or withdrawn enrollments as cancelled. Calendar apps can subscribe by passing a calendar token in the token query parameter.
+//	@Tags			calendar
+//	@Produce		text/calendar
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int		true	"Officer ID"
+//	@Param			token	query		string	false	"Calendar token, for subscribing without an Authorization header"
+//	@Param			from	query		string	false	"Earliest session date (YYYY-MM-DD, default a year ago)"
+//	@Success		200		{string}	string
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/officers/{id}/schedule.ics [get]
+func (app *appDependencies) showOfficerCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	officer, err := app.models.Officer.GetInScope(id, app.contextGetScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	v := validator.New()
+	filters := data.CalendarFilters{
+		OfficerID: &officer.ID,
+		From:      app.calendarFrom(r.URL.Query(), v),
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	name := fmt.Sprintf("Training Schedule - %s", officer.RegulationNumber)
+	app.writeCalendar(w, r, name, "schedule-"+strconv.FormatInt(officer.ID, 10), filters)
+}
+
+// calendarFrom reads the optional from query parameter, defaulting to a year ago so feeds keep
+// recent history without growing forever
+func (app *appDependencies) calendarFrom(query url.Values, v *validator.Validator) time.Time {
+	if from := app.getOptionalDateQueryParameter(query, "from", v); from != nil {
+		return *from
+	}
+	return time.Now().AddDate(-1, 0, 0)
+}
+
+// writeCalendar fetches the sessions matching the filters and sends them as an .ics document
+func (app *appDependencies) writeCalendar(w http.ResponseWriter, r *http.Request, name, filename string, filters data.CalendarFilters) {
+	events, err := app.models.TrainingSession.GetCalendarEvents(filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	feed := calendar.Calendar{Name: name}
+	for _, event := range events {
+		feed.Events = append(feed.Events, app.calendarEvent(event))
+	}
+
+	body := calendar.Encode(feed)
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+".ics"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// calendarEvent converts a session into a feed event. Session times are stored without a time zone,
+// so they are read in the configured calendar time zone.
+func (app *appDependencies) calendarEvent(event *data.CalendarEvent) calendar.Event {
+	at := func(clock time.Time) time.Time {
+		year, month, day := event.SessionDate.Date()
+		return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), 0, app.calendarLocation)
+	}
+
+	description := "Facilitator: " + event.FacilitatorName
+	if event.Notes != nil && *event.Notes != "" {
+		description += "\n\n" + *event.Notes
+	}
+
+	e := calendar.Event{
+		UID:          fmt.Sprintf("training-session-%d@police-training", event.SessionID),
+		Summary:      event.WorkshopName,
+		Description:  description,
+		Start:        at(event.StartTime),
+		End:          at(event.EndTime),
+		Status:       calendar.StatusConfirmed,
+		LastModified: event.UpdatedAt,
+	}
+
+	if event.Location != nil {
+		e.Location = *event.Location
+	}
+
+	switch {
+	case event.Cancelled:
+		e.Status = calendar.StatusCancelled
+	case event.Waitlisted:
+		e.Status = calendar.StatusTentative
+		e.Summary = "Waitlisted: " + e.Summary
+	}
+
+	return e
+}
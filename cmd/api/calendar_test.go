@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+)
+
+func TestFacilitatorCalendarFeed(t *testing.T) {
+	t.Log("=== Testing Facilitator Calendar Feed ===")
+
+	session := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(session.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	facilitatorID := strconv.FormatInt(session.FacilitatorID, 10)
+
+	calendarToken, err := testApp.models.Token.New(adminUser.ID, time.Hour, data.ScopeCalendar)
+	if err != nil {
+		t.Fatalf("Failed to create calendar token: %v", err)
+	}
+	defer testApp.models.Token.DeleteAllForUser(data.ScopeCalendar, adminUser.ID) // Cleanup
+
+	authToken := createTokenForSeededUser(t, adminUser.ID)
+
+	uid := fmt.Sprintf("UID:training-session-%d@police-training", session.ID)
+
+	tests := []struct {
+		name           string
+		token          string
+		cancel         bool
+		expectedStatus int
+		expected       []string
+	}{
+		{
+			name:           "Calendar token in the query string",
+			token:          calendarToken.Plaintext,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"STATUS:CONFIRMED", "LOCATION:Test Training Room"},
+		},
+		{
+			name:           "Cancelled sessions stay in the feed",
+			token:          calendarToken.Plaintext,
+			cancel:         true,
+			expectedStatus: http.StatusOK,
+			expected:       []string{"STATUS:CANCELLED"},
+		},
+		{
+			name:           "Authentication tokens are not accepted in the query string",
+			token:          authToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "No token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	handler := testApp.authenticateCalendarFeed(testApp.requirePermissions("training:sessions:view")(http.HandlerFunc(testApp.showFacilitatorCalendarHandler)))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			if tt.cancel {
+				status, err := testApp.models.TrainingStatus.GetByName("Cancelled")
+				if err != nil {
+					t.Skip("Cancelled training status not found")
+				}
+				session.TrainingStatusID = status.ID
+				if err := testApp.models.TrainingSession.Update(session); err != nil {
+					t.Fatalf("Failed to cancel session: %v", err)
+				}
+			}
+
+			path := fmt.Sprintf("/v1/users/%s/facilitation.ics?token=%s", facilitatorID, tt.token)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req = setURLParam(req, "id", facilitatorID)
+			req = setUserContext(req, data.AnonymousUser)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+				t.Errorf("Expected text/calendar content type; got %q", ct)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+			feed := string(body)
+
+			if !strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n") {
+				t.Error("Expected response body to be an iCalendar document")
+			}
+
+			// Only look at the test session's event, the facilitator may have others
+			start := strings.Index(feed, uid)
+			if start < 0 {
+				t.Fatalf("Expected feed to contain %q", uid)
+			}
+			event := feed[start:]
+			event = event[:strings.Index(event, "END:VEVENT")]
+
+			for _, want := range tt.expected {
+				if !strings.Contains(event, want) {
+					t.Errorf("Expected event to contain %q", want)
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestFacilitatorCalendarFeedScope(t *testing.T) {
+	t.Log("=== Testing Facilitator Calendar Feed Scope ===")
+
+	session := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(session.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	facilitatorID := strconv.FormatInt(session.FacilitatorID, 10)
+	uid := fmt.Sprintf("UID:training-session-%d@police-training", session.ID)
+
+	tests := []struct {
+		name          string
+		scope         *data.Scope
+		expectSession bool
+	}{
+		{name: "National scope", scope: &data.Scope{National: true}, expectSession: true},
+		{name: "Scope holding the session's formation", scope: &data.Scope{FormationIDs: []int64{session.FormationID}}, expectSession: true},
+		{name: "Scope holding another formation", scope: &data.Scope{FormationIDs: []int64{session.FormationID + 1000}}, expectSession: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/users/"+facilitatorID+"/facilitation.ics", nil)
+			req = setURLParam(setUserContext(req, adminUser), "id", facilitatorID)
+			req = testApp.contextSetScope(req, tt.scope)
+
+			rec := httptest.NewRecorder()
+			testApp.showFacilitatorCalendarHandler(rec, req)
+
+			t.Logf("Step: Received status code %d", rec.Code)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+			}
+
+			if got := strings.Contains(rec.Body.String(), uid); got != tt.expectSession {
+				t.Errorf("Expected the session in the feed: %v; got %v", tt.expectSession, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+// Filename: cmd/api/certificates.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/certificate"
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"github.com/julienschmidt/httprouter"
+)
+
+// showCertificatePDFHandler renders the certificate of a completed enrollment as a PDF document
+//
+//	@Summary		Download a certificate
+//	@Description	Render the certificate issued for a completed enrollment as a PDF document, showing the officer, workshop, credit hours, completion date and certificate number
+//	@Tags			certificates
+//	@Produce		application/pdf
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Training enrollment ID"
+//	@Success		200	{file}		file
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/training/enrollments/{id}/certificate.pdf [get]
+func (app *appDependencies) showCertificatePDFHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if _, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	details, err := app.models.Certificate.GetForEnrollment(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrCertificateNotIssued):
+			app.certificateNotIssuedResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	pdf, err := certificate.Render(certificate.Certificate{
+		OfficerName:      details.FirstName + " " + details.LastName,
+		RegulationNumber: details.RegulationNumber,
+		Rank:             details.Rank,
+		Workshop:         details.WorkshopName,
+		CreditHours:      details.CreditHours,
+		CompletionDate:   details.CompletionDate,
+		Number:           details.CertificateNumber,
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", "certificate-"+details.CertificateNumber+".pdf"))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
+
+// verifyCertificateHandler lets anyone confirm a certificate number is genuine. Only details printed on
+// the certificate itself are returned.
+//
+//	@Summary		Verify a certificate
+//	@Description	Public endpoint confirming a certificate number is genuine and still valid. Signed numbers are checked against the certificate signing key as well as the database, and signature_verified reports whether that check was made.
+//	@Tags			certificates
+//	@Produce		json
+//	@Param			number	path		string	true	"Certificate number"
+//	@Success		200		{object}	envelope
+//	@Failure		404		{object}	errorResponse
+//	@Failure		410		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/certificates/verify/{number} [get]
+func (app *appDependencies) verifyCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	number := httprouter.ParamsFromContext(r.Context()).ByName("number")
+
+	// Numbers issued before signing was enabled carry no signature and are checked against the database only
+	signatureVerified := false
+	if app.signer != nil && certificate.HasSignature(number) {
+		if _, err := app.signer.Verify(number); err != nil {
+			v := validator.New()
+			v.AddError("number", "has an invalid signature")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		signatureVerified = true
+	}
+
+	revoked, err := app.models.Certificate.IsRevoked(number)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if revoked {
+		app.revokedCertificateNumberResponse(w, r)
+		return
+	}
+
+	verification, err := app.models.Certificate.GetByNumber(number)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"certificate": verification, "signature_verified": signatureVerified}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showCertificatePublicKeyHandler publishes the key for verifying certificate numbers offline
+//
+//	@Summary		Get the certificate signing public key
+//	@Description	Public endpoint returning the PEM encoded Ed25519 public key that certificate number signatures can be checked against offline. Returns 404 when certificate signing is not configured.
+//	@Tags			certificates
+//	@Produce		application/x-pem-file
+//	@Success		200	{string}	string
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/certificates/public-key [get]
+func (app *appDependencies) showCertificatePublicKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if app.signer == nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	key, err := app.signer.PublicKeyPEM()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.WriteHeader(http.StatusOK)
+	w.Write(key)
+}
+
+// revokeCertificateHandler withdraws the certificate issued for an enrollment, recording the reason
+//
+//	@Summary		Revoke a certificate
+//	@Description	Revoke the certificate issued for an enrollment with a reason. The certificate number then fails public verification.
+//	@Tags			certificates
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int								true	"Training enrollment ID"
+//	@Param			revocation	body		CertificateRevocationRequest_T	true	"Reason for the revocation"
+//	@Success		200			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		409			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/enrollments/{id}/certificate/revoke [post]
+func (app *appDependencies) revokeCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateRevocationReason(v, input.Reason)
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	revocation, err := app.models.Certificate.Revoke(id, user.ID, input.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrCertificateNotIssued):
+			app.certificateNotIssuedResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, id, data.AuditActionRevokeCertificate, nil, revocation)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "certificate revoked successfully", "revocation": revocation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// reissueCertificateHandler replaces the certificate of an enrollment with a newly numbered one,
+// revoking the current certificate first when it is still valid
+//
+//	@Summary		Reissue a certificate
+//	@Description	Issue a new certificate number for an enrollment that still counts as present and completed, revoking the current certificate with the given reason when it is still valid
+//	@Tags			certificates
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int								true	"Training enrollment ID"
+//	@Param			reissue		body		CertificateRevocationRequest_T	true	"Reason for the reissue"
+//	@Success		200			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		409			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/enrollments/{id}/certificate/reissue [post]
+func (app *appDependencies) reissueCertificateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateRevocationReason(v, input.Reason)
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var sign func(string) string
+	if app.signer != nil {
+		sign = app.signer.Sign
+	}
+
+	user := app.contextGetUser(r)
+
+	certificateNumber, err := app.models.Certificate.Reissue(id, user.ID, input.Reason, sign)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrCertificateNotIssued):
+			app.certificateNotIssuedResponse(w, r)
+		case errors.Is(err, data.ErrAttendanceNotPresent), errors.Is(err, data.ErrProgressIncomplete):
+			if errors.Is(err, data.ErrAttendanceNotPresent) {
+				v.AddError("attendance_status_id", "must count as present before a certificate can be reissued")
+			}
+			if errors.Is(err, data.ErrProgressIncomplete) {
+				v.AddError("progress_status_id", "must be Completed before a certificate can be reissued")
+			}
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateValue):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, id, data.AuditActionReissueCertificate, nil, map[string]any{"certificate_number": certificateNumber, "reason": input.Reason})
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "certificate reissued successfully", "certificate_number": certificateNumber}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listCertificateRevocationsHandler returns the revocation history of an enrollment's certificates
+//
+//	@Summary		List certificate revocations
+//	@Description	Retrieve every revocation recorded against an enrollment's certificates, with the revoked number, reason, who revoked it and when
+//	@Tags			certificates
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Training enrollment ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/training/enrollments/{id}/certificate/revocations [get]
+func (app *appDependencies) listCertificateRevocationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Confirm the enrollment exists so an unknown ID is not reported as an empty history
+	_, err = app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	revocations, err := app.models.Certificate.GetRevocations(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"revocations": revocations}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/certificate"
+)
+
+func TestShowCertificatePDFHandler(t *testing.T) {
+	t.Log("=== Testing Show Certificate PDF Handler ===")
+
+	issued := createCompletedTestEnrollment(t)
+	defer testApp.models.TrainingEnrollment.Delete(issued.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	number, err := testApp.models.TrainingEnrollment.IssueCertificate(issued.ID, adminUser.ID, time.Now(), nil)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	t.Logf("Step: Issued certificate %s for enrollment ID %d", number, issued.ID)
+
+	tests := []struct {
+		name           string
+		enrollmentID   string
+		expectedStatus int
+	}{
+		{
+			name:           "Issued certificate renders as PDF",
+			enrollmentID:   strconv.FormatInt(issued.ID, 10),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Non-existent enrollment",
+			enrollmentID:   "999999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid enrollment ID",
+			enrollmentID:   "invalid",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			path := fmt.Sprintf("/v1/training/enrollments/%s/certificate.pdf", tt.enrollmentID)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req = setURLParam(req, "id", tt.enrollmentID)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.showCertificatePDFHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode == http.StatusOK {
+				if ct := res.Header.Get("Content-Type"); ct != "application/pdf" {
+					t.Errorf("Expected application/pdf content type; got %q", ct)
+				}
+
+				body, _ := io.ReadAll(res.Body)
+				t.Logf("Step: Received %d byte document", len(body))
+				if !bytes.HasPrefix(body, []byte("%PDF-")) {
+					t.Error("Expected response body to be a PDF document")
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestVerifyCertificateHandler(t *testing.T) {
+	t.Log("=== Testing Verify Certificate Handler ===")
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate signing key: %v", err)
+	}
+
+	previous := testApp.signer
+	testApp.signer = certificate.NewSigner(privateKey)
+	defer func() { testApp.signer = previous }()
+
+	enrollment := createCompletedTestEnrollment(t)
+	defer testApp.models.TrainingEnrollment.Delete(enrollment.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	signed, err := testApp.models.TrainingEnrollment.IssueCertificate(enrollment.ID, adminUser.ID, time.Now(), testApp.signer.Sign)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	t.Logf("Step: Issued signed certificate %s", signed)
+
+	tests := []struct {
+		name           string
+		number         string
+		expectedStatus int
+	}{
+		{
+			name:           "Genuine signed certificate",
+			number:         signed,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Tampered certificate number",
+			number:         "X" + signed,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unknown unsigned certificate number",
+			number:         fmt.Sprintf("CERT-UNKNOWN-%d", time.Now().UnixNano()),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/certificates/verify/"+tt.number, nil)
+			req = setURLParam(req, "number", tt.number)
+
+			rec := httptest.NewRecorder()
+			testApp.verifyCertificateHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode == http.StatusOK {
+				var response map[string]any
+				_ = json.NewDecoder(res.Body).Decode(&response)
+
+				if response["signature_verified"] != true {
+					t.Error("Expected signature to be verified")
+				}
+
+				details, _ := response["certificate"].(map[string]any)
+				if _, ok := details["regulation_number"]; ok {
+					t.Error("Expected regulation number to be withheld from public verification")
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestRevokeAndReissueCertificateHandlers(t *testing.T) {
+	t.Log("=== Testing Revoke and Reissue Certificate Handlers ===")
+
+	enrollment := createCompletedTestEnrollment(t)
+	defer testApp.models.TrainingEnrollment.Delete(enrollment.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	enrollmentID := strconv.FormatInt(enrollment.ID, 10)
+
+	original, err := testApp.models.TrainingEnrollment.IssueCertificate(enrollment.ID, adminUser.ID, time.Now(), nil)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	t.Logf("Step: Issued certificate %s", original)
+
+	post := func(handler http.HandlerFunc, action, id string, input map[string]any) *http.Response {
+		body, _ := json.Marshal(input)
+		path := fmt.Sprintf("/v1/training/enrollments/%s/certificate/%s", id, action)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = setURLParam(req, "id", id)
+		req = setUserContext(req, adminUser)
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Result()
+	}
+
+	verify := func(number string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/certificates/verify/"+number, nil)
+		req = setURLParam(req, "number", number)
+
+		rec := httptest.NewRecorder()
+		testApp.verifyCertificateHandler(rec, req)
+		return rec.Result().StatusCode
+	}
+
+	// 1. A reason is mandatory
+	res := post(testApp.revokeCertificateHandler, "revoke", enrollmentID, map[string]any{"reason": ""})
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for missing reason; got %d", http.StatusUnprocessableEntity, res.StatusCode)
+	}
+
+	// 2. Revoking withdraws the certificate and fails verification of its number
+	res = post(testApp.revokeCertificateHandler, "revoke", enrollmentID, map[string]any{"reason": "Attendance record corrected"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d for revocation; got %d", http.StatusOK, res.StatusCode)
+	}
+	t.Log("Step: Revoked certificate")
+
+	if status := verify(original); status != http.StatusGone {
+		t.Errorf("Expected revoked number to return status %d; got %d", http.StatusGone, status)
+	}
+
+	revoked, err := testApp.models.TrainingEnrollment.Get(enrollment.ID)
+	if err != nil {
+		t.Fatalf("Failed to fetch enrollment: %v", err)
+	}
+	if revoked.CertificateIssued || revoked.CertificateNumber != nil || !revoked.CertificateRevoked {
+		t.Error("Expected enrollment to be marked revoked with no certificate number")
+	}
+
+	// 3. A revoked certificate cannot be revoked again or issued afresh
+	res = post(testApp.revokeCertificateHandler, "revoke", enrollmentID, map[string]any{"reason": "Duplicate request"})
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("Expected status %d for repeated revocation; got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	if _, err := testApp.models.TrainingEnrollment.IssueCertificate(enrollment.ID, adminUser.ID, time.Now(), nil); err == nil {
+		t.Error("Expected issuing a revoked certificate to fail")
+	}
+
+	// 4. Reissuing produces a new, verifiable number
+	res = post(testApp.reissueCertificateHandler, "reissue", enrollmentID, map[string]any{"reason": "Attendance record corrected"})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d for reissue; got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var response map[string]any
+	_ = json.NewDecoder(res.Body).Decode(&response)
+	reissued, _ := response["certificate_number"].(string)
+	if reissued == "" || reissued == original {
+		t.Errorf("Expected a new certificate number; got %q", reissued)
+	}
+	t.Logf("Step: Reissued certificate %s", reissued)
+
+	if status := verify(reissued); status != http.StatusOK {
+		t.Errorf("Expected reissued number to verify with status %d; got %d", http.StatusOK, status)
+	}
+
+	// 5. The history links the revoked number to its replacement
+	revocations, err := testApp.models.Certificate.GetRevocations(enrollment.ID)
+	if err != nil {
+		t.Fatalf("Failed to fetch revocations: %v", err)
+	}
+	if len(revocations) != 1 || revocations[0].CertificateNumber != original || revocations[0].ReissuedNumber == nil || *revocations[0].ReissuedNumber != reissued {
+		t.Errorf("Expected one revocation of %s reissued as %s", original, reissued)
+	}
+
+	// 6. Unknown enrollments are not found
+	res = post(testApp.revokeCertificateHandler, "revoke", "999999", map[string]any{"reason": "Not found"})
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d for missing enrollment; got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
@@ -0,0 +1,61 @@
+// Filename: cmd/api/compliance.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// showOfficerComplianceHandler reports an officer's training hours against their rank requirement
+//
+//	@Summary		Get officer training compliance
+//	@Description	Compare credit hours from completed enrollments in a year against the officer's rank requirement
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int	true	"Officer ID"
+//	@Param			year	query		int	false	"Compliance year (defaults to the current year)"
+//	@Success		200		{object}	envelope
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/officers/{id}/compliance [get]
+func (app *appDependencies) showOfficerComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	v := validator.New()
+	year := app.getSingleIntQueryParameter(r.URL.Query(), "year", time.Now().Year(), v)
+
+	data.ValidateComplianceYear(v, year)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var compliance *data.OfficerCompliance
+	_, err = app.models.Officer.GetInScope(id, app.contextGetScope(r))
+	if err == nil {
+		compliance, err = app.models.Compliance.GetForOfficer(id, year)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"compliance": compliance}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+)
+
+func TestShowOfficerComplianceHandler(t *testing.T) {
+	t.Log("=== Testing Show Officer Compliance Handler ===")
+
+	seededOfficer, _ := getSeededOfficer(t)
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	adminToken := createTokenForSeededUser(t, adminUser.ID)
+
+	validStatuses := map[string]bool{
+		"compliant":     true,
+		"on_track":      true,
+		"at_risk":       true,
+		"non_compliant": true,
+	}
+
+	tests := []struct {
+		name           string
+		officerID      string
+		query          string
+		expectedStatus int
+		checkResponse  func(*testing.T, *http.Response)
+	}{
+		{
+			name:           "Current year for seeded officer",
+			officerID:      strconv.FormatInt(seededOfficer.ID, 10),
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating compliance response")
+				var response map[string]any
+				if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+
+				compliance, ok := response["compliance"].(map[string]any)
+				if !ok {
+					t.Fatal("Expected compliance object in response")
+				}
+
+				if int(compliance["year"].(float64)) != time.Now().Year() {
+					t.Errorf("Expected year %d; got %v", time.Now().Year(), compliance["year"])
+				}
+
+				earned := compliance["hours_earned"].(float64)
+				required := compliance["hours_required"].(float64)
+				remaining := compliance["hours_remaining"].(float64)
+				t.Logf("Step: Officer earned %.0f of %.0f hours (%.0f remaining)", earned, required, remaining)
+
+				if remaining != max(required-earned, 0) {
+					t.Errorf("Expected remaining hours %.0f; got %.0f", max(required-earned, 0), remaining)
+				}
+
+				if !validStatuses[compliance["status"].(string)] {
+					t.Errorf("Unexpected compliance status %v", compliance["status"])
+				}
+			},
+		},
+		{
+			name:           "Closed year is never on track",
+			officerID:      strconv.FormatInt(seededOfficer.ID, 10),
+			query:          "?year=2020",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating closed year status")
+				var response map[string]any
+				_ = json.NewDecoder(res.Body).Decode(&response)
+
+				compliance := response["compliance"].(map[string]any)
+				status := compliance["status"].(string)
+				if status != "compliant" && status != "non_compliant" {
+					t.Errorf("Expected compliant or non_compliant for a closed year; got %s", status)
+				}
+			},
+		},
+		{
+			name:           "Invalid year",
+			officerID:      strconv.FormatInt(seededOfficer.ID, 10),
+			query:          "?year=1999",
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating invalid year response")
+			},
+		},
+		{
+			name:           "Non-existent officer ID",
+			officerID:      "999999",
+			expectedStatus: http.StatusNotFound,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating non-existent officer response")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s with officer ID %s", tt.name, tt.officerID)
+
+			path := fmt.Sprintf("/v1/officers/%s/compliance%s", tt.officerID, tt.query)
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+
+			req = setURLParam(req, "id", tt.officerID)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.showOfficerComplianceHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			tt.checkResponse(t, res)
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestComplianceReportHandler(t *testing.T) {
+	t.Log("=== Testing Compliance Report Handler ===")
+
+	seededOfficer, _ := getSeededOfficer(t)
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	adminToken := createTokenForSeededUser(t, adminUser.ID)
+
+	region, err := testApp.models.Region.Get(seededOfficer.RegionID)
+	if err != nil {
+		t.Fatalf("Failed to get the seeded officer's region: %v", err)
+	}
+	formation, err := testApp.models.Formation.Get(seededOfficer.FormationID)
+	if err != nil {
+		t.Fatalf("Failed to get the seeded officer's formation: %v", err)
+	}
+
+	type report struct {
+		GroupBy string                  `json:"group_by"`
+		Year    int                     `json:"year"`
+		Units   []data.ComplianceRollup `json:"units"`
+		Error   map[string]any          `json:"error"`
+	}
+
+	// officersIn totals the officers counted across every unit of a report
+	officersIn := func(t *testing.T, r report) int {
+		t.Helper()
+		total := 0
+		for _, unit := range r.Units {
+			if unit.Officers != unit.Compliant+unit.OnTrack+unit.AtRisk+unit.NonCompliant {
+				t.Errorf("Expected %s's statuses to add up to its %d officers; got %+v", unit.Unit, unit.Officers, unit)
+			}
+			total += unit.Officers
+		}
+		return total
+	}
+
+	// unitFor finds the row for a unit, or fails the test
+	unitFor := func(t *testing.T, r report, id int64) data.ComplianceRollup {
+		t.Helper()
+		for _, unit := range r.Units {
+			if unit.UnitID == id {
+				return unit
+			}
+		}
+		t.Fatalf("Expected unit %d in the report; got %+v", id, r.Units)
+		return data.ComplianceRollup{}
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		checkResponse  func(*testing.T, report)
+	}{
+		{
+			name:           "Grouped by region by default",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if r.GroupBy != data.ComplianceGroupRegion || r.Year != time.Now().Year() {
+					t.Errorf("Expected the current year by region; got %d by %s", r.Year, r.GroupBy)
+				}
+				unit := unitFor(t, r, region.ID)
+				if unit.Unit != region.Region || unit.Officers < 1 {
+					t.Errorf("Expected %s to count the seeded officer; got %+v", region.Region, unit)
+				}
+				t.Logf("Step: %d officers across %d regions", officersIn(t, r), len(r.Units))
+			},
+		},
+		{
+			name:           "Grouped by formation",
+			query:          "&group_by=formation",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if r.GroupBy != data.ComplianceGroupFormation {
+					t.Errorf("Expected the report grouped by formation; got %s", r.GroupBy)
+				}
+				unit := unitFor(t, r, formation.ID)
+				if unit.Unit != formation.Formation || unit.Officers < 1 {
+					t.Errorf("Expected %s to count the seeded officer; got %+v", formation.Formation, unit)
+				}
+				t.Logf("Step: %d officers across %d formations", officersIn(t, r), len(r.Units))
+			},
+		},
+		{
+			name:           "Filtered by rank",
+			query:          fmt.Sprintf("&rank_id=%d", seededOfficer.RankID),
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if unitFor(t, r, region.ID).Officers < 1 {
+					t.Error("Expected the seeded officer's rank to be counted in their region")
+				}
+			},
+		},
+		{
+			name:           "Filtered by posting",
+			query:          fmt.Sprintf("&group_by=formation&posting_id=%d", seededOfficer.PostingID),
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if unitFor(t, r, formation.ID).Officers < 1 {
+					t.Error("Expected the seeded officer's posting to be counted in their formation")
+				}
+			},
+		},
+		{
+			name:           "Rank nobody holds",
+			query:          "&rank_id=999999",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if total := officersIn(t, r); total != 0 {
+					t.Errorf("Expected no officers counted; got %d", total)
+				}
+			},
+		},
+		{
+			name:           "Posting nobody holds",
+			query:          "&group_by=formation&posting_id=999999",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, r report) {
+				if total := officersIn(t, r); total != 0 {
+					t.Errorf("Expected no officers counted; got %d", total)
+				}
+			},
+		},
+		{
+			name:           "Invalid group_by",
+			query:          "&group_by=rank",
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, r report) {
+				if r.Error["group_by"] == nil {
+					t.Errorf("Expected a group_by error; got %v", r.Error)
+				}
+			},
+		},
+		{
+			name:           "Invalid rank_id",
+			query:          "&rank_id=abc",
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, r report) {
+				if r.Error["rank_id"] == nil {
+					t.Errorf("Expected a rank_id error; got %v", r.Error)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/reports/compliance?page_size=100"+tt.query, nil)
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.complianceReportHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			var response report
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			tt.checkResponse(t, response)
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
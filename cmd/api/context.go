@@ -10,7 +10,12 @@ import (
 
 type contextKey string // Define a custom type for context keys to avoid collisions
 
-const contextKeyUser = contextKey("user") // Key for storing/retrieving user information in/from context
+const (
+	contextKeyUser      = contextKey("user")       // Key for storing/retrieving user information in/from context
+	contextKeyRequestID = contextKey("request_id") // Key for storing/retrieving the request ID in/from context
+	contextKeyScope     = contextKey("scope")      // Key for storing/retrieving the permission scope in/from context
+	contextKeyToken     = contextKey("token")      // Key for storing/retrieving the authentication token in/from context
+)
 
 // contextSetUser adds the user information to the request context.
 func (app *appDependencies) contextSetUser(r *http.Request, user *data.User) *http.Request {
@@ -26,3 +31,42 @@ func (app *appDependencies) contextGetUser(r *http.Request) *data.User {
 	}
 	return user // Return the retrieved user
 }
+
+// contextSetRequestID adds the request ID to the request context.
+func (app *appDependencies) contextSetRequestID(r *http.Request, id string) *http.Request {
+	ctx := context.WithValue(r.Context(), contextKeyRequestID, id)
+	return r.WithContext(ctx)
+}
+
+// contextGetRequestID retrieves the request ID from the request context, or an empty string when the
+// request did not pass through the requestID middleware.
+func (app *appDependencies) contextGetRequestID(r *http.Request) string {
+	id, _ := r.Context().Value(contextKeyRequestID).(string)
+	return id
+}
+
+// contextSetScope adds the scope of the permission checked by requirePermissions to the request context.
+func (app *appDependencies) contextSetScope(r *http.Request, scope *data.Scope) *http.Request {
+	ctx := context.WithValue(r.Context(), contextKeyScope, scope)
+	return r.WithContext(ctx)
+}
+
+// contextGetScope retrieves the permission scope from the request context. It is nil, which places no
+// limit, when the request did not pass through requirePermissions.
+func (app *appDependencies) contextGetScope(r *http.Request) *data.Scope {
+	scope, _ := r.Context().Value(contextKeyScope).(*data.Scope)
+	return scope
+}
+
+// contextSetToken adds the plaintext authentication token the request was made with to the request context.
+func (app *appDependencies) contextSetToken(r *http.Request, token string) *http.Request {
+	ctx := context.WithValue(r.Context(), contextKeyToken, token)
+	return r.WithContext(ctx)
+}
+
+// contextGetToken retrieves the authentication token from the request context, or an empty string when the
+// request was not authenticated with a bearer token.
+func (app *appDependencies) contextGetToken(r *http.Request) string {
+	token, _ := r.Context().Value(contextKeyToken).(string)
+	return token
+}
@@ -0,0 +1,152 @@
+// Filename: cmd/api/csv.go
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wantsCSV reports whether the client asked for a CSV export, either with ?format=csv or an
+// Accept: text/csv header. List handlers return every matching row, ignoring the page, when it does.
+func (app *appDependencies) wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// writeCSV streams count rows as a CSV attachment, preceded by the header row
+func (app *appDependencies) writeCSV(w http.ResponseWriter, r *http.Request, filename string, header []string, count int, row func(i int) []string) {
+	app.streamCSV(w, r, filename, header, func(write func([]string) error) error {
+		for i := range count {
+			if err := write(row(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// streamCSV writes a CSV attachment, preceded by the header row, whose rows each passes to write as it
+// produces them, so an export never has to be held in memory. An error before any of the file reaches
+// the client is sent as an error response; once it has started the status can no longer change, so
+// later errors are only logged.
+func (app *appDependencies) streamCSV(w http.ResponseWriter, r *http.Request, filename string, header []string, each func(write func([]string) error) error) {
+	out := &csvResponse{w: w, filename: filename}
+	cw := csv.NewWriter(out)
+
+	err := cw.Write(header)
+	if err == nil {
+		err = each(func(record []string) error {
+			for j, field := range record {
+				record[j] = csvSafe(field)
+			}
+			return cw.Write(record)
+		})
+	}
+	if err == nil {
+		cw.Flush()
+		err = cw.Error()
+	}
+
+	if err != nil {
+		if !out.started {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		app.logError(r, err)
+	}
+}
+
+// csvResponse sends the CSV attachment headers with the first bytes written, leaving the response
+// untouched until then
+type csvResponse struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (c *csvResponse) Write(p []byte) (int, error) {
+	if !c.started {
+		c.started = true
+		c.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		c.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", c.filename+"-"+time.Now().Format("20060102")+".csv"))
+		c.w.WriteHeader(http.StatusOK)
+	}
+	return c.w.Write(p)
+}
+
+// csvSafe stops spreadsheet applications from treating a field as a formula
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+// csvName returns the display name for id, falling back to the id itself
+func csvName(names map[int64]string, id int64) string {
+	if name, ok := names[id]; ok {
+		return name
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+// csvOptionalName returns the display name for an optional id
+func csvOptionalName(names map[int64]string, id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return csvName(names, *id)
+}
+
+// csvString returns an optional string, or an empty field
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// csvInt returns an optional integer, or an empty field
+func csvInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+// csvBool writes booleans as yes or no
+func csvBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// csvDate writes a calendar date
+func csvDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// csvOptionalDate writes an optional calendar date
+func csvOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return csvDate(*t)
+}
+
+// csvClock writes a time of day
+func csvClock(t time.Time) string {
+	return t.Format("15:04")
+}
+
+// csvTimestamp writes a point in time
+func csvTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,168 @@
+// Filename: cmd/api/csv_test.go
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWantsCSV(t *testing.T) {
+	t.Log("=== Testing CSV Export Negotiation ===")
+
+	tests := []struct {
+		name     string
+		query    string
+		accept   string
+		expected bool
+	}{
+		{name: "No format or Accept header", expected: false},
+		{name: "format=csv", query: "?format=csv", expected: true},
+		{name: "format is not case sensitive", query: "?format=CSV", expected: true},
+		{name: "Accept text/csv", accept: "text/csv", expected: true},
+		{name: "Accept list including text/csv", accept: "application/json, text/csv;q=0.9", expected: true},
+		{name: "format=json overrides Accept", query: "?format=json", accept: "text/csv", expected: false},
+		{name: "Unknown format", query: "?format=xml", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/officers"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			if got := testApp.wantsCSV(req); got != tt.expected {
+				t.Errorf("Expected %v; got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	t.Log("=== Testing Streamed CSV Exports ===")
+
+	errExport := errors.New("export failed")
+	header := []string{"ID", "Name"}
+
+	// Enough rows to fill the CSV writer's buffer so part of the file reaches the client
+	manyRows := func(write func([]string) error) error {
+		for range 1000 {
+			if err := write([]string{"1", strings.Repeat("x", 20)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name           string
+		each           func(write func([]string) error) error
+		expectedStatus int
+		checkResponse  func(*testing.T, *http.Response)
+	}{
+		{
+			name: "Rows follow the header",
+			each: func(write func([]string) error) error {
+				if err := write([]string{"1", "Belize City"}); err != nil {
+					return err
+				}
+				return write([]string{"2", "=HYPERLINK(\"http://example.com\")"})
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected text/csv content type, got %q", ct)
+				}
+				if cd := res.Header.Get("Content-Disposition"); !strings.Contains(cd, "attachment; filename=\"regions-") {
+					t.Errorf("Expected an attachment named after the export, got %q", cd)
+				}
+
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+				if len(records) != 3 {
+					t.Fatalf("Expected a header and 2 rows, got %v", records)
+				}
+				if records[2][1] != "'=HYPERLINK(\"http://example.com\")" {
+					t.Errorf("Expected the formula to be neutralised, got %q", records[2][1])
+				}
+			},
+		},
+		{
+			name:           "No rows",
+			each:           func(write func([]string) error) error { return nil },
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+				if len(records) != 1 || records[0][0] != "ID" {
+					t.Errorf("Expected only the header row, got %v", records)
+				}
+			},
+		},
+		{
+			name: "Error before anything is sent",
+			each: func(write func([]string) error) error {
+				if err := write([]string{"1", "Belize City"}); err != nil {
+					return err
+				}
+				return errExport
+			},
+			expectedStatus: http.StatusInternalServerError,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/csv") {
+					t.Errorf("Expected a JSON error rather than a CSV file, got %q", ct)
+				}
+				if res.Header.Get("Content-Disposition") != "" {
+					t.Error("Expected no attachment header on an error response")
+				}
+			},
+		},
+		{
+			name: "Error after the file has started",
+			each: func(write func([]string) error) error {
+				if err := manyRows(write); err != nil {
+					return err
+				}
+				return errExport
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected the CSV already sent to be kept, got %q", ct)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/regions?format=csv", nil)
+			rec := httptest.NewRecorder()
+			testApp.streamCSV(rec, req, "regions", header, tt.each)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if tt.checkResponse != nil {
+				tt.checkResponse(t, res)
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
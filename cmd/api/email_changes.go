@@ -0,0 +1,315 @@
+// FileName: cmd/api/email_changes.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// emailChangeTTL is how long a new email address has to be confirmed before the change is dropped
+const emailChangeTTL = 24 * time.Hour
+
+// validateNewEmail checks an address a user's email is being changed to, including that no other user has it
+func (app *appDependencies) validateNewEmail(v *validator.Validator, user *data.User, email string) error {
+	data.ValidateEmail(v, email)
+	v.Check(email != user.Email, "email", "must be different from the current email address")
+	if !v.IsEmpty() {
+		return nil
+	}
+
+	_, err := app.models.User.GetByEmail(email)
+	switch {
+	case err == nil:
+		v.AddError("email", "a user with this email address already exists")
+	case !errors.Is(err, data.ErrRecordNotFound):
+		return err
+	}
+
+	return nil
+}
+
+// requestEmailChange starts changing a user's email address. The new address is sent a token to confirm
+// it and the old one a notice with a token to cancel it; the address only changes once confirmed.
+func (app *appDependencies) requestEmailChange(r *http.Request, user *data.User, email string) (*data.EmailChange, error) {
+	requestedBy := app.contextGetUser(r).ID
+	change := &data.EmailChange{UserID: user.ID, NewEmail: email, RequestedBy: &requestedBy}
+
+	confirmToken, cancelToken, err := app.models.EmailChange.Request(change, emailChangeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionRequestEmailChange, nil, change)
+
+	recipient := *user
+	app.background(func() {
+		app.sendEmailChangeEmails(&recipient, change, confirmToken, cancelToken)
+	})
+
+	return change, nil
+}
+
+// requestCurrentUserEmailChangeHandler lets users change their own email address after re-entering
+// their password
+//
+//	@Summary		Change your email address
+//	@Description	Start changing your email address. The new address is emailed a token to confirm it, valid for 24 hours, and your current address is told about the change with a token to cancel it. Your email only changes once the new address is confirmed.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			input	body		ChangeEmailRequest_T	true	"New email address and current password"
+//	@Success		202		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/me/email [put]
+func (app *appDependencies) requestCurrentUserEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+	v.Check(input.Password != "", "password", "must be provided")
+	if err := app.validateNewEmail(v, user, input.Email); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// A stolen session alone must not be enough to move the account to another address
+	match, err := user.Password.Matches(input.Password)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !match {
+		v.AddError("password", "is incorrect")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	change, err := app.requestEmailChange(r, user, input.Email)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	payload := envelope{
+		"email_change": change,
+		"message":      "a confirmation email has been sent to the new address",
+	}
+	if err := app.writeJSON(w, http.StatusAccepted, payload, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// cancelCurrentUserEmailChangeHandler lets users drop their own pending email change
+//
+//	@Summary		Cancel your email change
+//	@Description	Cancel your pending email address change. The confirmation token sent to the new address stops working.
+//	@Tags			users
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/email [delete]
+func (app *appDependencies) cancelCurrentUserEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	change, err := app.models.EmailChange.Cancel(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionCancelEmailChange, change, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "email change successfully cancelled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// confirmEmailChangeHandler changes a user's email address with the token sent to the new address
+//
+//	@Summary		Confirm an email change
+//	@Description	Confirm a new email address with the token emailed to it. The user's email is changed and the tokens sent for the change stop working.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		EmailChangeTokenRequest_T	true	"Token from the confirmation email"
+//	@Success		200		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/users/email/confirm [put]
+func (app *appDependencies) confirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.readEmailChangeToken(w, r, data.ScopeEmailChange)
+	if !ok {
+		return
+	}
+
+	before := *user
+
+	if err := app.models.EmailChange.Confirm(user.ID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v := validator.New()
+			v.AddError("token", "invalid or expired email change token")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v := validator.New()
+			v.AddError("email", "a user with this email address already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// Reset links already sent went to the old address
+	if err := app.models.Token.DeleteAllForUser(data.ScopePasswordReset, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	user, err := app.models.User.Get(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionConfirmEmailChange, &before, user)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// cancelEmailChangeHandler cancels an email change with the token sent to the old address
+//
+//	@Summary		Cancel an email change
+//	@Description	Cancel a pending email address change with the token emailed to the current address. The confirmation token sent to the new address stops working.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		EmailChangeTokenRequest_T	true	"Token from the email change notice"
+//	@Success		200		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/users/email/cancel [put]
+func (app *appDependencies) cancelEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.readEmailChangeToken(w, r, data.ScopeEmailChangeCancel)
+	if !ok {
+		return
+	}
+
+	change, err := app.models.EmailChange.Cancel(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v := validator.New()
+			v.AddError("token", "invalid or expired email change token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionCancelEmailChange, change, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "email change successfully cancelled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readEmailChangeToken reads the token from an email change request body and returns the user it
+// belongs to. It writes the error response itself and reports false when the token is not usable.
+func (app *appDependencies) readEmailChangeToken(w http.ResponseWriter, r *http.Request, scope string) (*data.User, bool) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, input.Token); !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+
+	user, err := app.models.User.GetForToken(scope, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired email change token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return user, true
+}
+
+// sendEmailChangeEmails sends the confirmation to the new address and the notice to the old one, with
+// links when -email-change-confirm-url and -email-change-cancel-url are set. It is called from
+// background goroutines, so failures are only logged.
+func (app *appDependencies) sendEmailChangeEmails(user *data.User, change *data.EmailChange, confirmToken, cancelToken *data.Token) {
+	if app.mailer == nil {
+		return
+	}
+
+	expiry := change.Expiry.UTC().Format("2 January 2006 15:04 MST")
+
+	confirmPayload := map[string]any{
+		"firstName":    user.FirstName,
+		"newEmail":     change.NewEmail,
+		"confirmToken": confirmToken.Plaintext,
+		"confirmURL":   tokenLink(app.config.emailChange.confirmURL, confirmToken.Plaintext),
+		"expiry":       expiry,
+	}
+	if err := app.mailer.Send(change.NewEmail, "email_change_confirm.tmpl", confirmPayload); err != nil {
+		app.logger.Error("failed to send email change confirmation", "error", err, "user_id", user.ID)
+	}
+
+	noticePayload := map[string]any{
+		"firstName":   user.FirstName,
+		"newEmail":    change.NewEmail,
+		"cancelToken": cancelToken.Plaintext,
+		"cancelURL":   tokenLink(app.config.emailChange.cancelURL, cancelToken.Plaintext),
+		"expiry":      expiry,
+	}
+	if err := app.mailer.Send(user.Email, "email_change_notice.tmpl", noticePayload); err != nil {
+		app.logger.Error("failed to send email change notice", "error", err, "user_id", user.ID)
+	}
+}
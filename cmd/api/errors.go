@@ -4,7 +4,10 @@ package main
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 /************************************************************************************************************/
@@ -13,9 +16,10 @@ import (
 
 // logs the error message along with the request method and URL
 func (app *appDependencies) logError(r *http.Request, err error) {
-	method := r.Method                                          // get the HTTP method
-	uri := r.URL.RequestURI()                                   // get the request URI
-	app.logger.Error(err.Error(), "method", method, "uri", uri) // log the error with method and URI
+	method := r.Method                                                                   // get the HTTP method
+	uri := r.URL.RequestURI()                                                            // get the request URI
+	requestID := app.contextGetRequestID(r)                                              // get the request ID
+	app.logger.Error(err.Error(), "method", method, "uri", uri, "request_id", requestID) // log the error with method, URI and request ID
 }
 
 // Sends an error response in JSON format
@@ -103,8 +107,82 @@ func (a *appDependencies) notPermittedResponse(w http.ResponseWriter, r *http.Re
 	a.errorResponseJSON(w, r, http.StatusForbidden, message)
 }
 
+// Return a 403 status code when the two-factor policy applies to a user who has not enrolled
+func (a *appDependencies) twoFactorRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your role requires two-factor authentication, enable it at /v1/me/2fa to access this resource"
+	a.errorResponseJSON(w, r, http.StatusForbidden, message)
+}
+
+// Return a 403 status code when registration is only by invitation
+func (a *appDependencies) registrationClosedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "registration is by invitation only, ask an administrator to invite you"
+	a.errorResponseJSON(w, r, http.StatusForbidden, message)
+}
+
 // Return a 409 status code
 func (a *appDependencies) conflictResponse(w http.ResponseWriter, r *http.Request) {
 	message := "the request could not be completed due to a conflict with the current state of the resource"
 	a.errorResponseJSON(w, r, http.StatusConflict, message)
 }
+
+// Return a 409 status code when a training session has no seats left
+func (a *appDependencies) sessionFullResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the training session has reached its maximum capacity"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when an enrollment has no certificate to produce
+func (a *appDependencies) certificateNotIssuedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "no certificate has been issued for this enrollment"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when an enrollment already has a certificate
+func (a *appDependencies) certificateAlreadyIssuedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "a certificate has already been issued for this enrollment"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when an enrollment's certificate was revoked and must be reissued instead
+func (a *appDependencies) certificateRevokedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the certificate for this enrollment has been revoked, use the reissue endpoint"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when a change would rename, delete or remove permissions from the Admin role
+func (a *appDependencies) adminRoleResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the Admin role cannot be renamed, deleted or have permissions removed"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when a change would leave no active user with the Admin role held nationally
+func (a *appDependencies) lastAdminResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this user is the last active admin, assign the Admin role to another user first"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 409 status code when two-factor authentication is already enabled
+func (a *appDependencies) twoFactorEnabledResponse(w http.ResponseWriter, r *http.Request) {
+	message := "two-factor authentication is already enabled, disable it before setting it up again"
+	a.errorResponseJSON(w, r, http.StatusConflict, message)
+}
+
+// Return a 410 status code when a certificate number has been revoked
+func (a *appDependencies) revokedCertificateNumberResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this certificate has been revoked and is no longer valid"
+	a.errorResponseJSON(w, r, http.StatusGone, message)
+}
+
+// Return a 423 status code when an account is locked after too many failed logins, saying when to retry
+func (a *appDependencies) accountLockedResponse(w http.ResponseWriter, r *http.Request, lockedUntil time.Time) {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	message := fmt.Sprintf("this account is locked after too many failed login attempts, try again after %s", lockedUntil.UTC().Format(time.RFC3339))
+	a.errorResponseJSON(w, r, http.StatusLocked, message)
+}
+
+// Return a 404 status code when a user asks for their officer records but no officer is linked to their account
+func (a *appDependencies) notOfficerResponse(w http.ResponseWriter, r *http.Request) {
+	message := "no officer record is linked to your account"
+	a.errorResponseJSON(w, r, http.StatusNotFound, message)
+}
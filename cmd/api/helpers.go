@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
@@ -186,6 +187,31 @@ func (app *appDependencies) getOptionalInt64QueryParameter(params url.Values, ke
 	return &i
 }
 
+// getOptionalDateQueryParameter retrieves a YYYY-MM-DD query parameter returning a pointer if present.
+func (app *appDependencies) getOptionalDateQueryParameter(params url.Values, key string, v *validator.Validator) *time.Time {
+	value := params.Get(key)
+	if value == "" {
+		return nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		v.AddError(key, "invalid date format, use YYYY-MM-DD")
+		return nil
+	}
+
+	return &t
+}
+
+// joinIDs formats a list of record ids for use in a client message, e.g. "3, 7, 12".
+func joinIDs(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(parts, ", ")
+}
+
 // readFilters constructs a Filters struct using standard query parameters and validates it.
 func (app *appDependencies) readFilters(query url.Values, defaultSort string, defaultPageSize int, safelist []string, v *validator.Validator) data.Filters {
 	filters := data.Filters{
@@ -199,6 +225,20 @@ func (app *appDependencies) readFilters(query url.Values, defaultSort string, de
 	return filters
 }
 
+// tokenLink adds a token to the address of a frontend page as a query parameter, for emails that link to
+// the page instead of describing the request to make. It returns "" when no page is configured.
+func tokenLink(page, token string) string {
+	if page == "" {
+		return ""
+	}
+
+	separator := "?"
+	if strings.Contains(page, "?") {
+		separator = "&"
+	}
+	return page + separator + "token=" + url.QueryEscape(token)
+}
+
 /************************************************************************************************************/
 // Go routine helper functions
 /************************************************************************************************************/
@@ -219,3 +259,9 @@ func (app *appDependencies) background(fn func()) {
 		fn() // execute the provided function
 	}()
 }
+
+// checkInScope records an error when a record in the given region and formation would fall outside the
+// regions and formations the user's roles are held in
+func checkInScope(v *validator.Validator, scope *data.Scope, regionID, formationID int64) {
+	v.Check(scope.Includes(regionID, formationID), "formation_id", "must be in a region or formation you are assigned to")
+}
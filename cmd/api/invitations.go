@@ -0,0 +1,447 @@
+// FileName: cmd/api/invitations.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// invitationTTL is how long an invited user has to accept before the invitation must be resent
+const invitationTTL = 7 * 24 * time.Hour
+
+// createInvitationHandler invites someone to join with roles chosen for them, creating their account
+// inactive and emailing them a single-use link to choose a password
+//
+//	@Summary		Invite a user
+//	@Description	Create an inactive account for an email with the given roles, optionally held in one region or formation, and an optional officer record, then email the invitee a link to accept. The roles cannot carry permissions the inviter lacks, nor reach beyond the regions and formations the inviter's own are held in. The link expires after 7 days.
+//	@Tags			invitations
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			invitation	body		CreateInvitationRequest_T	true	"Invitation details"
+//	@Success		201			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/invitations [post]
+func (app *appDependencies) createInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		FirstName     string   `json:"first_name"`
+		LastName      string   `json:"last_name"`
+		Email         string   `json:"email"`
+		Gender        string   `json:"gender"`
+		IsFacilitator bool     `json:"is_facilitator"`
+		Roles         []string `json:"roles"`
+		RegionID      *int64   `json:"region_id"`
+		FormationID   *int64   `json:"formation_id"`
+		Officer       *struct {
+			RegulationNumber string `json:"regulation_number"`
+			RankID           int64  `json:"rank_id"`
+			PostingID        int64  `json:"posting_id"`
+			FormationID      int64  `json:"formation_id"`
+			RegionID         int64  `json:"region_id"`
+		} `json:"officer"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	inviter := app.contextGetUser(r)
+
+	invitation := &data.Invitation{
+		InvitedBy: &inviter.ID,
+		User: &data.User{
+			FirstName:     input.FirstName,
+			LastName:      input.LastName,
+			Email:         input.Email,
+			Gender:        input.Gender,
+			IsFacilitator: input.IsFacilitator,
+		},
+		Roles:       input.Roles,
+		RegionID:    input.RegionID,
+		FormationID: input.FormationID,
+	}
+
+	// The invitee chooses their password on accepting, so until then no password opens the account
+	if err := invitation.User.Password.SetUnusable(app.passwordParams()); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateUser(v, invitation.User)
+	data.ValidateRoleScope(v, input.RegionID, input.FormationID)
+	if err := app.validateRoleNames(v, input.Roles); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if err := app.checkGrantWithin(v, inviter.ID, app.contextGetScope(r), input.Roles, input.RegionID, input.FormationID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if input.Officer != nil {
+		invitation.Officer = &data.Officer{
+			RegulationNumber: input.Officer.RegulationNumber,
+			RankID:           input.Officer.RankID,
+			PostingID:        input.Officer.PostingID,
+			FormationID:      input.Officer.FormationID,
+			RegionID:         input.Officer.RegionID,
+		}
+
+		// The user does not exist yet, so the officer is validated without one
+		ov := validator.New()
+		data.ValidateOfficer(ov, invitation.Officer)
+		checkInScope(ov, app.contextGetScope(r), invitation.Officer.RegionID, invitation.Officer.FormationID)
+		for key, message := range ov.Errors {
+			if key != "user_id" {
+				v.AddError("officer."+key, message)
+			}
+		}
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	token, err := app.models.Invitation.Insert(invitation, invitationTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v.AddError("email", "a user with this email already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateValue):
+			v.AddError("officer.regulation_number", "an officer with this regulation number already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrForeignKeyViolation):
+			v.AddError("references", "one or more referenced records do not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, invitation.UserID, data.AuditActionCreate, nil, invitation.User)
+	if invitation.Officer != nil {
+		app.recordAudit(r, data.AuditEntityOfficer, invitation.Officer.ID, data.AuditActionCreate, nil, invitation.Officer)
+	}
+	app.recordAudit(r, data.AuditEntityInvitation, invitation.ID, data.AuditActionCreate, nil, invitation)
+
+	app.background(func() {
+		app.sendInvitationEmail(invitation.User, token)
+	})
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/invitations/%d", invitation.ID))
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"invitation": invitation}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listInvitationsHandler lists the invitations that have not been accepted yet
+//
+//	@Summary		List pending invitations
+//	@Description	Retrieve the invitations that have not been accepted, newest first, including expired ones
+//	@Tags			invitations
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/invitations [get]
+func (app *appDependencies) listInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	invitations, err := app.models.Invitation.GetAllPending()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"invitations": invitations}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// resendInvitationHandler emails a pending invitation again with a new link, replacing the old one
+//
+//	@Summary		Resend an invitation
+//	@Description	Email a pending invitation again with a new link valid for 7 days. Links sent earlier stop working.
+//	@Tags			invitations
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Invitation ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/invitations/{id}/resend [post]
+func (app *appDependencies) resendInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	invitation, err := app.models.Invitation.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	inviter := app.contextGetUser(r)
+
+	resent, token, err := app.models.Invitation.Invite(invitation.UserID, &inviter.ID, invitationTTL)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	before := *invitation
+	invitation.InvitedBy = resent.InvitedBy
+	invitation.Expiry = resent.Expiry
+	invitation.CreatedAt = resent.CreatedAt
+	app.recordAudit(r, data.AuditEntityInvitation, invitation.ID, data.AuditActionUpdate, &before, invitation)
+
+	app.background(func() {
+		app.sendInvitationEmail(invitation.User, token)
+	})
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"invitation": invitation}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// revokeInvitationHandler withdraws a pending invitation, deleting the inactive account created for it
+//
+//	@Summary		Revoke an invitation
+//	@Description	Withdraw an invitation that has not been accepted, deleting the inactive account, roles and officer record created for it
+//	@Tags			invitations
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Invitation ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/invitations/{id} [delete]
+func (app *appDependencies) revokeInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	invitation, err := app.models.Invitation.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.models.Invitation.Revoke(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrForeignKeyViolation):
+			app.conflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityInvitation, id, data.AuditActionDelete, invitation, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "invitation successfully revoked"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// acceptInvitationHandler lets an invited user choose their password with the token they were sent,
+// activating their account
+//
+//	@Summary		Accept an invitation
+//	@Description	Choose a password with the token from an invitation email. The account is activated and the token stops working.
+//	@Tags			invitations
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		AcceptInvitationRequest_T	true	"Invitation token and new password"
+//	@Success		200		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		409		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/invitations/accept [put]
+func (app *appDependencies) acceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateTokenPlaintext(v, input.Token)
+	data.ValidatePasswordPlaintext(v, input.Password)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.User.GetForToken(data.ScopeInvitation, input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("token", "invalid or expired invitation token")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.validateNewPassword(v, user.ID, input.Password); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	before := *user
+	if err := user.Password.Set(input.Password, app.passwordParams()); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	user.IsActivated = true
+
+	if err := app.models.Invitation.Accept(user); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionAcceptInvitation, &before, user)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// checkGrantWithin records an error when roles would give someone more than the user granting them
+// holds: a permission the grantor lacks, or a region, formation or national reach outside their scope
+func (app *appDependencies) checkGrantWithin(v *validator.Validator, grantorID int64, scope *data.Scope, roles []string, regionID, formationID *int64) error {
+	held, err := app.models.Role.GetAllPermissionsForUser(grantorID)
+	if err != nil {
+		return err
+	}
+
+	granted, err := app.models.Role.GetAllPermissionsForRoles(roles...)
+	if err != nil {
+		return err
+	}
+
+	for _, code := range granted {
+		v.Check(held.Includes(code), "roles", fmt.Sprintf("must not grant the %q permission, which you do not hold", code))
+	}
+
+	if !scope.Limited() {
+		return nil
+	}
+
+	switch {
+	case formationID != nil:
+		formation, err := app.models.Formation.Get(*formationID)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				return nil // reported as an unknown reference when the invitation is saved
+			}
+			return err
+		}
+		checkInScope(v, scope, formation.RegionID, formation.ID)
+	case regionID != nil:
+		v.Check(slices.Contains(scope.RegionIDs, *regionID), "region_id", "must be a region you are assigned to")
+	default:
+		v.AddError("region_id", "must be provided, as your roles are limited to regions or formations")
+	}
+
+	return nil
+}
+
+// sendInvitationEmail emails an invited user the token, or link when -invitation-url is set, that accepts
+// their invitation. It is called from background goroutines, so failures are only logged.
+func (app *appDependencies) sendInvitationEmail(user *data.User, token *data.Token) {
+	if app.mailer == nil {
+		return
+	}
+
+	payload := map[string]any{
+		"firstName":       user.FirstName,
+		"lastName":        user.LastName,
+		"email":           user.Email,
+		"invitationToken": token.Plaintext,
+		"invitationURL":   tokenLink(app.config.invitations.url, token.Plaintext),
+		"expiry":          token.Expiry.UTC().Format("2 January 2006 15:04 MST"),
+	}
+
+	if err := app.mailer.Send(user.Email, "user_invitation.tmpl", payload); err != nil {
+		app.logger.Error("failed to send invitation email", "error", err, "user_id", user.ID)
+	}
+}
+
+// registrationAllowed reports whether an email address is at one of the domains open registration is
+// restricted to
+func (app *appDependencies) registrationAllowed(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range app.config.registration.domains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -13,7 +13,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+	_ "time/tzdata" // embed the time zone database for hosts without one
 
+	"github.com/Pedro-J-Kukul/police_training/internal/certificate"
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/mailer"
 	_ "github.com/lib/pq"
@@ -45,6 +47,44 @@ type serverConfig struct {
 		password string // SMTP password
 		sender   string // SMTP sender address
 	}
+	certificate struct {
+		signingKey string // path to the Ed25519 key used to sign certificate numbers
+	}
+	calendar struct {
+		timezone string // IANA time zone that session dates and times are recorded in
+	}
+	tokens struct {
+		accessTTL  time.Duration // lifetime of authentication tokens
+		refreshTTL time.Duration // lifetime of refresh tokens
+	}
+	lockout struct {
+		threshold   int           // failed logins in a row that lock an account, 0 to disable
+		duration    time.Duration // length of the first lock
+		maxDuration time.Duration // longest an account stays locked
+	}
+	twoFactor struct {
+		required bool   // whether holders of sensitive permissions must enable two-factor authentication
+		issuer   string // name authenticator apps show beside the account
+	}
+	password struct {
+		memory      int    // Argon2id memory in KiB
+		iterations  int    // Argon2id passes over the memory
+		parallelism int    // Argon2id threads
+		minLength   int    // shortest password accepted
+		history     int    // recent passwords that cannot be reused, 0 to allow reuse
+		breachList  string // path to a list of breached passwords, one per line
+	}
+	registration struct {
+		open    bool     // whether anyone at an allowed domain may register without an invitation
+		domains []string // email domains open registration is restricted to
+	}
+	invitations struct {
+		url string // address of the page that accepts invitations, given the token as a query parameter
+	}
+	emailChange struct {
+		confirmURL string // address of the page that confirms a new email address, given the token as a query parameter
+		cancelURL  string // address of the page that cancels an email change, given the token as a query parameter
+	}
 }
 
 type appDependencies struct {
@@ -53,6 +93,10 @@ type appDependencies struct {
 	wg     sync.WaitGroup // wait group for managing goroutines
 	models data.Models
 	mailer *mailer.Mailer
+	signer *certificate.Signer
+
+	calendarLocation  *time.Location  // time zone used to place sessions in calendar feeds
+	breachedPasswords data.BreachList // passwords refused because they appeared in a breach
 }
 
 func (app *appDependencies) version() string {
@@ -75,7 +119,13 @@ func main() {
 	// For application setup
 	cfg := loadConfig()            // load the application configuration
 	logger := setUpLogger(cfg.env) // set up the logger
-	db, err := openDB(cfg)         // open the database connection
+
+	if err := validatePasswordConfig(cfg); err != nil {
+		logger.Error("invalid password settings", slog.Any("error", err)) // log every out of range password flag
+		os.Exit(1)                                                        // exit rather than panic on the first login
+	}
+
+	db, err := openDB(cfg) // open the database connection
 	if err != nil {
 		logger.Error("unable to connect to database", slog.Any("error", err)) // log any error connecting to the database
 		os.Exit(1)                                                            // exit if there is a database connection error
@@ -106,6 +156,35 @@ func main() {
 		app.mailer = mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender)
 	}
 
+	if cfg.certificate.signingKey != "" {
+		app.signer, err = certificate.LoadSigner(cfg.certificate.signingKey)
+		if err != nil {
+			logger.Error("unable to load certificate signing key", slog.Any("error", err)) // log any error reading the key
+			os.Exit(1)                                                                     // exit rather than issue unsigned certificates
+		}
+		logger.Info("certificate signing key loaded")
+	}
+
+	app.calendarLocation, err = time.LoadLocation(cfg.calendar.timezone)
+	if err != nil {
+		logger.Error("unable to load calendar time zone", slog.Any("error", err)) // log an unknown time zone name
+		os.Exit(1)                                                                // exit rather than publish sessions at the wrong time
+	}
+
+	if cfg.password.breachList != "" {
+		app.breachedPasswords, err = data.LoadBreachList(cfg.password.breachList)
+		if err != nil {
+			logger.Error("unable to load password breach list", slog.Any("error", err)) // log any error reading the list
+			os.Exit(1)                                                                  // exit rather than accept breached passwords
+		}
+		logger.Info("password breach list loaded", slog.Int("entries", len(app.breachedPasswords)))
+	}
+
+	if cfg.registration.open && len(cfg.registration.domains) == 0 {
+		logger.Error("open registration needs -registration-domains") // refuse to open registration to any address
+		os.Exit(1)
+	}
+
 	err = app.serve() // start the HTTP server
 	if err != nil {
 		logger.Error("error starting server", slog.Any("error", err)) // log any error starting the server
@@ -147,6 +226,43 @@ func loadConfig() serverConfig {
 	flag.StringVar(&cfg.smtp.password, "smtp-password", "", "SMTP password")                                 // SMTP password
 	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "Training <noreply@example.com>", "SMTP sender address") // SMTP sender address
 
+	// Certificate settings
+	flag.StringVar(&cfg.certificate.signingKey, "certificate-signing-key", "", "Path to the PEM encoded Ed25519 key for signing certificate numbers") // certificate signing key
+
+	// Calendar settings
+	flag.StringVar(&cfg.calendar.timezone, "calendar-timezone", "America/Belize", "Time zone of session dates and times in calendar feeds") // calendar time zone
+
+	// Token settings
+	flag.DurationVar(&cfg.tokens.accessTTL, "access-token-ttl", 15*time.Minute, "Lifetime of authentication tokens") // authentication token lifetime
+	flag.DurationVar(&cfg.tokens.refreshTTL, "refresh-token-ttl", 30*24*time.Hour, "Lifetime of refresh tokens")     // refresh token lifetime
+
+	// Login lockout settings
+	flag.IntVar(&cfg.lockout.threshold, "lockout-threshold", 5, "Failed logins in a row that lock an account (0 disables lockout)")                // lockout threshold
+	flag.DurationVar(&cfg.lockout.duration, "lockout-duration", time.Minute, "Length of the first account lock, doubled for each further failure") // first lock length
+	flag.DurationVar(&cfg.lockout.maxDuration, "lockout-max-duration", 24*time.Hour, "Longest an account stays locked")                            // longest lock
+
+	// Two-factor settings
+	flag.BoolVar(&cfg.twoFactor.required, "require-2fa", false, "Require two-factor authentication for roles holding users:edit or officers:delete") // two-factor policy
+	flag.StringVar(&cfg.twoFactor.issuer, "two-factor-issuer", "Police Training", "Issuer name shown in authenticator apps")                         // authenticator issuer
+
+	// Password settings
+	flag.IntVar(&cfg.password.memory, "argon2-memory", int(data.DefaultPasswordParams.Memory), "Argon2id memory for password hashes, in KiB")             // hashing memory
+	flag.IntVar(&cfg.password.iterations, "argon2-iterations", int(data.DefaultPasswordParams.Iterations), "Argon2id iterations for password hashes")     // hashing passes
+	flag.IntVar(&cfg.password.parallelism, "argon2-parallelism", int(data.DefaultPasswordParams.Parallelism), "Argon2id parallelism for password hashes") // hashing threads
+	flag.IntVar(&cfg.password.minLength, "password-min-length", 8, "Shortest password accepted (8 to 72)")                                                // minimum length
+	flag.IntVar(&cfg.password.history, "password-history", 5, "Recent passwords, including the current one, that cannot be reused (0 to 25)")             // reuse window
+	flag.StringVar(&cfg.password.breachList, "password-breach-list", "", "Path to a file of breached passwords or SHA-1 hashes, one per line, to refuse") // breach list
+
+	// Registration settings
+	flag.BoolVar(&cfg.registration.open, "open-registration", false, "Allow registration without an invitation for emails at -registration-domains") // open registration
+	flag.Func("registration-domains", "Email domains open registration is restricted to (space separated)", func(s string) error {
+		cfg.registration.domains = strings.Fields(strings.ToLower(s)) // split the input string by spaces and assign to domains
+		return nil
+	})
+	flag.StringVar(&cfg.invitations.url, "invitation-url", "", "Address of the page that accepts invitations; the token is added as ?token=")                           // invitation page
+	flag.StringVar(&cfg.emailChange.confirmURL, "email-change-confirm-url", "", "Address of the page that confirms a new email address; the token is added as ?token=") // email confirmation page
+	flag.StringVar(&cfg.emailChange.cancelURL, "email-change-cancel-url", "", "Address of the page that cancels an email change; the token is added as ?token=")        // email change cancel page
+
 	flag.Parse() // parse the command-line flags
 
 	// Print out all the flag values for debugging
@@ -188,6 +304,16 @@ func loadConfig() serverConfig {
 		}
 	}
 
+	if cfg.certificate.signingKey == "" {
+		cfg.certificate.signingKey = os.Getenv("CERTIFICATE_SIGNING_KEY")
+	}
+
+	if cfg.calendar.timezone == "America/Belize" {
+		if timezone := os.Getenv("CALENDAR_TIMEZONE"); timezone != "" {
+			cfg.calendar.timezone = timezone
+		}
+	}
+
 	return cfg // return the populated configuration
 }
 
@@ -2,10 +2,13 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"expvar"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -34,6 +37,29 @@ func (app *appDependencies) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
+/***********************************************************************************************
+ * Request IDs
+ ************************************************************************************************/
+
+// requestIDRX matches request IDs that are safe to accept from clients and proxies
+var requestIDRX = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID is a middleware that tags every request with an ID, reusing a well-formed X-Request-ID
+// header when one is sent. The ID is echoed in the response and recorded in logs and the audit log.
+func (app *appDependencies) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !requestIDRX.MatchString(id) {
+			b := make([]byte, 16)
+			rand.Read(b) // never returns an error
+			id = hex.EncodeToString(b)
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, app.contextSetRequestID(r, id))
+	})
+}
+
 /***********************************************************************************************
  * rate limiting
  ************************************************************************************************/
@@ -163,13 +189,55 @@ func (app *appDependencies) authenticate(next http.Handler) http.Handler {
 			return // Return to avoid further processing
 		}
 
-		// Set the user in the request context
-		r = app.contextSetUser(r, user) // Set the authenticated user in the context
+		// Record the token as used, so users can see when each of their sessions was last active
+		if err := app.models.Token.Touch(data.ScopeAuthentication, tokenPlaintext); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		// Set the user and their token in the request context
+		r = app.contextSetUser(r, user)            // Set the authenticated user in the context
+		r = app.contextSetToken(r, tokenPlaintext) // Keep the token so the session can be identified
 
 		next.ServeHTTP(w, r) // Call the next handler in the chain
 	})
 }
 
+// authenticateCalendarFeed lets calendar clients, which cannot send an Authorization header, subscribe
+// to a feed with a calendar scoped token in the token query parameter. Requests that already carry
+// a bearer token are left alone.
+func (app *appDependencies) authenticateCalendarFeed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenPlaintext := r.URL.Query().Get("token")
+
+		if tokenPlaintext == "" || !app.contextGetUser(r).IsAnonymous() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		v := validator.New()
+		if data.ValidateTokenPlaintext(v, tokenPlaintext); !v.IsEmpty() {
+			app.invalidAuthenticationTokenResponse(w, r)
+			return
+		}
+
+		user, err := app.models.User.GetForToken(data.ScopeCalendar, tokenPlaintext)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.invalidAuthenticationTokenResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		r = app.contextSetUser(r, user)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // requireAuthenticatedUser is a middleware that ensures the user is authenticated.
 func (app *appDependencies) requireAuthenticatedUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -220,6 +288,29 @@ func (app *appDependencies) requirePermissions(requiredPermissions string) func(
 				return
 			}
 
+			// Under the two-factor policy, sensitive roles only work once the holder has enrolled
+			if app.config.twoFactor.required {
+				needsEnrollment, err := app.models.TwoFactor.NeedsEnrollment(user.ID, twoFactorPermissions)
+				if err != nil {
+					app.serverErrorResponse(w, r, err)
+					return
+				}
+
+				if needsEnrollment {
+					app.twoFactorRequiredResponse(w, r)
+					return
+				}
+			}
+
+			// Roles held in a region or formation only grant the permission there
+			scope, err := app.models.RoleUser.GetScope(user.ID, requiredPermissions)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+
+			r = app.contextSetScope(r, scope)
+
 			next.ServeHTTP(w, r) // Call the next handler in the chain
 		})
 		return app.requireActivatedUser(fn) // Ensure the user is activated before checking permissions
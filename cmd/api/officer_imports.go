@@ -0,0 +1,485 @@
+// Filename: cmd/api/officer_imports.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/spreadsheet"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// maxImportFileSize limits the size of an uploaded officer import file
+const maxImportFileSize = 5 << 20
+
+// officerImportColumns are the header names read from an import file. Rank and posting may be given by
+// name or code, formation and region by name. Region is optional and defaults to the formation's region.
+var officerImportColumns = []string{"first_name", "last_name", "email", "gender", "regulation_number", "rank", "posting", "formation", "region"}
+
+// officerImportRow is one line of an import file along with its outcome
+type officerImportRow struct {
+	Row              int               `json:"row"`
+	Email            string            `json:"email"`
+	RegulationNumber string            `json:"regulation_number"`
+	UserID           int64             `json:"user_id,omitempty"`
+	OfficerID        int64             `json:"officer_id,omitempty"`
+	Errors           map[string]string `json:"errors,omitempty"`
+
+	entry *data.OfficerImport
+}
+
+// importOfficersHandler creates users and officers from an uploaded CSV or XLSX file. With ?dry_run=true
+// the file is only validated and the outcome of every row is reported. Otherwise the officers are
+// created in one transaction, and nothing is created if any row is invalid.
+//
+//	@Summary		Import officers
+//	@Description	Create users and officers from a CSV or XLSX file of up to 500 officers and 5 MB. The first row names the columns first_name, last_name, email, gender, regulation_number, rank, posting, formation and an optional region. With dry_run=true every row is validated and reported without creating anything. Otherwise the whole file is imported in one transaction, nothing is created if any row is invalid, and each imported officer is invited to choose a password.
+//	@Tags			officers
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			file	formData	file	true	"CSV or XLSX file of officers"
+//	@Param			dry_run	query		bool	false	"Validate the file without importing it"
+//	@Success		200		{object}	envelope	"Dry run results"
+//	@Success		201		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/officers/import [post]
+func (app *appDependencies) importOfficersHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	dryRun := app.getOptionalBoolQueryParameter(r.URL.Query(), "dry_run", v)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("body must be a multipart form no larger than %d MB", maxImportFileSize>>20))
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		v.AddError("file", "must be provided")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	records, err := spreadsheet.Read(header.Filename, content)
+	if err != nil {
+		v.AddError("file", "could not be read: "+err.Error())
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rows, err := app.parseOfficerImport(records, app.contextGetScope(r), v)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	invalid := 0
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			invalid++
+		}
+	}
+
+	summary := map[string]int{"total": len(rows), "valid": len(rows) - invalid, "invalid": invalid}
+
+	if dryRun != nil && *dryRun {
+		err = app.writeJSON(w, http.StatusOK, envelope{"dry_run": true, "rows": rows, "summary": summary}, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if invalid > 0 {
+		app.invalidImportResponse(w, r, rows, summary)
+		return
+	}
+
+	entries := make([]*data.OfficerImport, len(rows))
+	for i, row := range rows {
+		entries[i] = row.entry
+	}
+
+	err = app.models.Officer.Import(entries)
+	if err != nil {
+		var importErr *data.OfficerImportError
+		switch {
+		case errors.As(err, &importErr) && errors.Is(err, data.ErrDuplicateEmail):
+			rows[importErr.Index].Errors = map[string]string{"email": "a user with this email already exists"}
+		case errors.As(err, &importErr) && errors.Is(err, data.ErrDuplicateValue):
+			rows[importErr.Index].Errors = map[string]string{"regulation_number": "an officer with this regulation number already exists"}
+		case errors.As(err, &importErr) && errors.Is(err, data.ErrForeignKeyViolation):
+			rows[importErr.Index].Errors = map[string]string{"officer": "references a rank, posting, formation or region that no longer exists"}
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		summary["valid"]--
+		summary["invalid"]++
+		app.invalidImportResponse(w, r, rows, summary)
+		return
+	}
+
+	for _, row := range rows {
+		row.UserID = row.entry.User.ID
+		row.OfficerID = row.entry.Officer.ID
+		app.recordAudit(r, data.AuditEntityUser, row.UserID, data.AuditActionCreate, nil, row.entry.User)
+		app.recordAudit(r, data.AuditEntityOfficer, row.OfficerID, data.AuditActionCreate, nil, row.entry.Officer)
+	}
+
+	app.sendImportInvitations(rows, app.contextGetUser(r).ID)
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"dry_run": false, "rows": rows, "summary": summary}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// invalidImportResponse reports the rows that stopped an import from being committed
+func (app *appDependencies) invalidImportResponse(w http.ResponseWriter, r *http.Request, rows []*officerImportRow, summary map[string]int) {
+	payload := envelope{
+		"error":   "the file contains invalid rows, no officers were imported",
+		"rows":    rows,
+		"summary": summary,
+	}
+	if err := app.writeJSON(w, http.StatusUnprocessableEntity, payload, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// parseOfficerImport turns the records of an import file into validated rows. Problems with the file as
+// a whole are added to v; problems with a single row, including officers outside scope, are recorded
+// on that row.
+func (app *appDependencies) parseOfficerImport(records [][]string, scope *data.Scope, v *validator.Validator) ([]*officerImportRow, error) {
+	// The header is the first non-blank line
+	headerIndex := 0
+	for headerIndex < len(records) && isBlankRecord(records[headerIndex]) {
+		headerIndex++
+	}
+	if headerIndex == len(records) {
+		v.AddError("file", "must contain a header row and at least one officer")
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[headerIndex] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		columns[name] = i
+	}
+
+	missing := []string{}
+	for _, name := range officerImportColumns {
+		if _, ok := columns[name]; !ok && name != "region" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		v.AddError("file", "is missing the columns: "+strings.Join(missing, ", "))
+		return nil, nil
+	}
+
+	resolver := newOfficerImportResolver(app.models)
+	emails := make(map[string]int)
+	regulationNumbers := make(map[string]int)
+	rows := []*officerImportRow{}
+
+	for i := headerIndex + 1; i < len(records); i++ {
+		if isBlankRecord(records[i]) {
+			continue
+		}
+
+		field := func(name string) string {
+			column, ok := columns[name]
+			if !ok || column >= len(records[i]) {
+				return ""
+			}
+			return strings.TrimSpace(records[i][column])
+		}
+
+		user := &data.User{
+			FirstName: field("first_name"),
+			LastName:  field("last_name"),
+			Email:     field("email"),
+			Gender:    importGender(field("gender")),
+			IsOfficer: true,
+		}
+
+		// Users choose a password when they accept the invitation sent once the import is committed,
+		// so until then each account holds its own hash that no password matches
+		if err := user.Password.SetUnusable(app.passwordParams()); err != nil {
+			return nil, err
+		}
+		officer := &data.Officer{
+			RegulationNumber: field("regulation_number"),
+		}
+
+		row := &officerImportRow{
+			Row:              i + 1,
+			Email:            user.Email,
+			RegulationNumber: officer.RegulationNumber,
+			entry:            &data.OfficerImport{User: user, Officer: officer},
+		}
+		rows = append(rows, row)
+
+		if len(rows) > data.MaxOfficerImport {
+			v.AddError("file", fmt.Sprintf("must not contain more than %d officers", data.MaxOfficerImport))
+			return nil, nil
+		}
+
+		rv := validator.New()
+		data.ValidateUser(rv, user)
+
+		if err := resolver.resolve(officer, field("rank"), field("posting"), field("formation"), field("region"), rv); err != nil {
+			return nil, err
+		}
+		if officer.FormationID != 0 {
+			rv.Check(scope.Includes(officer.RegionID, officer.FormationID), "formation", "must be in a region or formation you are assigned to")
+		}
+
+		// The user does not exist yet, so the officer is validated without one
+		ov := validator.New()
+		data.ValidateOfficer(ov, officer)
+		for key, message := range ov.Errors {
+			if key != "user_id" {
+				rv.AddError(strings.TrimSuffix(key, "_id"), message)
+			}
+		}
+
+		email := strings.ToLower(user.Email)
+		if first, ok := emails[email]; ok && email != "" {
+			rv.AddError("email", fmt.Sprintf("duplicates row %d", first))
+		} else {
+			emails[email] = row.Row
+		}
+
+		if first, ok := regulationNumbers[officer.RegulationNumber]; ok && officer.RegulationNumber != "" {
+			rv.AddError("regulation_number", fmt.Sprintf("duplicates row %d", first))
+		} else {
+			regulationNumbers[officer.RegulationNumber] = row.Row
+		}
+
+		if _, ok := rv.Errors["email"]; !ok {
+			_, err := app.models.User.GetByEmail(user.Email)
+			switch {
+			case err == nil:
+				rv.AddError("email", "a user with this email already exists")
+			case !errors.Is(err, data.ErrRecordNotFound):
+				return nil, err
+			}
+		}
+
+		if _, ok := rv.Errors["regulation_number"]; !ok {
+			_, err := app.models.Officer.GetByRegulationNumber(officer.RegulationNumber)
+			switch {
+			case err == nil:
+				rv.AddError("regulation_number", "an officer with this regulation number already exists")
+			case !errors.Is(err, data.ErrRecordNotFound):
+				return nil, err
+			}
+		}
+
+		if !rv.IsEmpty() {
+			row.Errors = rv.Errors
+		}
+	}
+
+	if len(rows) == 0 {
+		v.AddError("file", "must contain a header row and at least one officer")
+	}
+
+	return rows, nil
+}
+
+// isBlankRecord reports whether every field of a record is empty
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importGender accepts the gender codes used by the API as well as the words male and female
+func importGender(value string) string {
+	switch value = strings.ToLower(value); value {
+	case "male":
+		return "m"
+	case "female":
+		return "f"
+	default:
+		return value
+	}
+}
+
+/************************************************************************************************************/
+// Reference data resolution
+/************************************************************************************************************/
+
+// officerImportResolver looks up reference data by name, remembering each answer so a file with many
+// officers in the same formation only queries it once
+type officerImportResolver struct {
+	models     data.Models
+	ranks      map[string]int64
+	postings   map[string]int64
+	formations map[string]*data.Formation
+	regions    map[string]int64
+}
+
+func newOfficerImportResolver(models data.Models) *officerImportResolver {
+	return &officerImportResolver{
+		models:     models,
+		ranks:      make(map[string]int64),
+		postings:   make(map[string]int64),
+		formations: make(map[string]*data.Formation),
+		regions:    make(map[string]int64),
+	}
+}
+
+// resolve fills in the officer's rank, posting, formation and region IDs, recording names that do not
+// match anything in v. An error is only returned when a lookup fails.
+func (res *officerImportResolver) resolve(officer *data.Officer, rank, posting, formation, region string, v *validator.Validator) error {
+	var err error
+
+	if rank != "" {
+		officer.RankID, err = cachedLookup(res.ranks, rank, func(value string) (int64, error) {
+			r, err := res.models.Rank.GetByName(value)
+			if errors.Is(err, data.ErrRecordNotFound) {
+				r, err = res.models.Rank.GetByCode(value)
+			}
+			if err != nil {
+				return 0, err
+			}
+			return r.ID, nil
+		})
+		if err = importLookupError(v, "rank", rank, err); err != nil {
+			return err
+		}
+	}
+
+	if posting != "" {
+		officer.PostingID, err = cachedLookup(res.postings, posting, func(value string) (int64, error) {
+			p, err := res.models.Posting.GetByName(value)
+			if errors.Is(err, data.ErrRecordNotFound) {
+				p, err = res.models.Posting.GetByCode(value)
+			}
+			if err != nil {
+				return 0, err
+			}
+			return p.ID, nil
+		})
+		if err = importLookupError(v, "posting", posting, err); err != nil {
+			return err
+		}
+	}
+
+	var f *data.Formation
+	if formation != "" {
+		f, err = cachedLookup(res.formations, formation, res.models.Formation.GetByName)
+		if err = importLookupError(v, "formation", formation, err); err != nil {
+			return err
+		}
+		if f != nil {
+			officer.FormationID = f.ID
+			officer.RegionID = f.RegionID
+		}
+	}
+
+	if region != "" {
+		regionID, err := cachedLookup(res.regions, region, func(value string) (int64, error) {
+			r, err := res.models.Region.GetByName(value)
+			if err != nil {
+				return 0, err
+			}
+			return r.ID, nil
+		})
+		if err = importLookupError(v, "region", region, err); err != nil {
+			return err
+		}
+		if regionID != 0 {
+			v.Check(f == nil || f.RegionID == regionID, "region", "does not match the formation's region")
+			officer.RegionID = regionID
+		}
+	}
+
+	return nil
+}
+
+// cachedLookup returns the remembered result for value, calling lookup the first time it is seen.
+// Values that were not found are remembered as the zero value along with ErrRecordNotFound.
+func cachedLookup[T comparable](cache map[string]T, value string, lookup func(string) (T, error)) (T, error) {
+	var zero T
+	if result, ok := cache[value]; ok {
+		if result == zero {
+			return zero, data.ErrRecordNotFound
+		}
+		return result, nil
+	}
+
+	result, err := lookup(value)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			cache[value] = zero
+		}
+		return zero, err
+	}
+
+	cache[value] = result
+	return result, nil
+}
+
+// importLookupError records a reference that could not be found, passing any other error back
+func importLookupError(v *validator.Validator, field, value string, err error) error {
+	if errors.Is(err, data.ErrRecordNotFound) {
+		v.AddError(field, fmt.Sprintf("no %s matches %q", field, value))
+		return nil
+	}
+	return err
+}
+
+/************************************************************************************************************/
+// Account setup
+/************************************************************************************************************/
+
+// sendImportInvitations invites each imported officer to choose a password, the same way
+// createInvitationHandler does for a single user
+func (app *appDependencies) sendImportInvitations(rows []*officerImportRow, invitedBy int64) {
+	app.background(func() {
+		for _, row := range rows {
+			user := row.entry.User
+
+			_, token, err := app.models.Invitation.Invite(user.ID, &invitedBy, invitationTTL)
+			if err != nil {
+				app.logger.Error("failed to create invitation", "error", err, "user_id", user.ID)
+				continue
+			}
+
+			app.sendInvitationEmail(user, token)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+// FileName: cmd/api/officer_portal.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// These handlers let officers see their own training under /v1/me without permissions over every
+// officer. The officer is always the one linked to the authenticated user.
+
+// currentOfficer returns the officer linked to the authenticated user. It writes the error response
+// itself and reports false when there is none.
+func (app *appDependencies) currentOfficer(w http.ResponseWriter, r *http.Request) (*data.Officer, bool) {
+	officer, err := app.models.Officer.GetByUserID(app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notOfficerResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return officer, true
+}
+
+// showCurrentOfficerHandler returns the officer record of the authenticated user
+//
+//	@Summary		Get your officer record
+//	@Description	Retrieve your officer record with your rank, posting, formation and region
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/officer [get]
+func (app *appDependencies) showCurrentOfficerHandler(w http.ResponseWriter, r *http.Request) {
+	officer, ok := app.currentOfficer(w, r)
+	if !ok {
+		return
+	}
+
+	officer, err := app.models.Officer.GetWithDetails(officer.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"officer": officer}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listCurrentOfficerEnrollmentsHandler lists the training enrollments of the authenticated user
+//
+//	@Summary		List your training enrollments
+//	@Description	Retrieve your training enrollments with pagination
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			page		query		int		false	"Page number"
+//	@Param			page_size	query		int		false	"Page size"
+//	@Param			sort		query		string	false	"Sort field (created_at, -created_at, completion_date, -completion_date)"
+//	@Success		200			{object}	envelope
+//	@Failure		401			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/me/enrollments [get]
+func (app *appDependencies) listCurrentOfficerEnrollmentsHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	filters := app.readFilters(r.URL.Query(), "created_at", 20, []string{"created_at", "-created_at", "completion_date", "-completion_date"}, v)
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	officer, ok := app.currentOfficer(w, r)
+	if !ok {
+		return
+	}
+
+	enrollments, metadata, err := app.models.TrainingEnrollment.GetByOfficer(officer.ID, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"training_enrollments": enrollments, "metadata": metadata}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listCurrentOfficerUpcomingSessionsHandler lists the sessions from today on that the authenticated
+// user is enrolled or waitlisted in
+//
+//	@Summary		List your upcoming sessions
+//	@Description	Retrieve the training sessions from today on that you hold a seat in or are waitlisted for, in chronological order. Cancelled sessions and enrollments you no longer hold are left out.
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/upcoming-sessions [get]
+func (app *appDependencies) listCurrentOfficerUpcomingSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	officer, ok := app.currentOfficer(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := app.models.TrainingSession.GetCalendarEvents(data.CalendarFilters{
+		OfficerID: &officer.ID,
+		From:      time.Now(),
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	sessions := []*data.CalendarEvent{}
+	for _, event := range events {
+		if !event.Cancelled {
+			sessions = append(sessions, event)
+		}
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"upcoming_sessions": sessions}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// listCurrentOfficerCertificatesHandler lists the certificates issued to the authenticated user
+//
+//	@Summary		List your certificates
+//	@Description	Retrieve the certificates issued to you that have not been revoked, most recently completed first
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/certificates [get]
+func (app *appDependencies) listCurrentOfficerCertificatesHandler(w http.ResponseWriter, r *http.Request) {
+	officer, ok := app.currentOfficer(w, r)
+	if !ok {
+		return
+	}
+
+	certificates, err := app.models.Certificate.GetAllForOfficer(officer.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"certificates": certificates}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showCurrentOfficerComplianceHandler reports the authenticated user's training hours against their
+// rank requirement
+//
+//	@Summary		Get your training compliance
+//	@Description	Compare your credit hours from completed enrollments in a year against your rank requirement
+//	@Tags			officers
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			year	query		int	false	"Compliance year (defaults to the current year)"
+//	@Success		200		{object}	envelope
+//	@Failure		401		{object}	errorResponse
+//	@Failure		404		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/me/compliance [get]
+func (app *appDependencies) showCurrentOfficerComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	year := app.getSingleIntQueryParameter(r.URL.Query(), "year", time.Now().Year(), v)
+
+	data.ValidateComplianceYear(v, year)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	officer, ok := app.currentOfficer(w, r)
+	if !ok {
+		return
+	}
+
+	compliance, err := app.models.Compliance.GetForOfficer(officer.ID, year)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"compliance": compliance}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
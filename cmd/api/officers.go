@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
@@ -49,6 +50,7 @@ func (app *appDependencies) createOfficerHandler(w http.ResponseWriter, r *http.
 
 	v := validator.New()
 	data.ValidateOfficer(v, officer)
+	checkInScope(v, app.contextGetScope(r), officer.RegionID, officer.FormationID)
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -68,6 +70,8 @@ func (app *appDependencies) createOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityOfficer, officer.ID, data.AuditActionCreate, nil, officer)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/officers/%d", officer.ID))
 
@@ -95,7 +99,7 @@ func (app *appDependencies) showOfficerHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	officer, err := app.models.Officer.Get(id)
+	officer, err := app.models.Officer.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -130,7 +134,11 @@ func (app *appDependencies) showOfficerWithDetailsHandler(w http.ResponseWriter,
 		return
 	}
 
-	officer, err := app.models.Officer.GetWithDetails(id)
+	// The detailed query is not limited by scope, so check the officer is in scope first
+	officer, err := app.models.Officer.GetInScope(id, app.contextGetScope(r))
+	if err == nil {
+		officer, err = app.models.Officer.GetWithDetails(id)
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -151,13 +159,14 @@ func (app *appDependencies) showOfficerWithDetailsHandler(w http.ResponseWriter,
 //	@Summary		List officers
 //	@Description	Retrieve a list of officers with optional filtering and pagination
 //	@Tags			officers
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			regulation_number	query		string	false	"Filter by regulation number"
 //	@Param			rank_id				query		int		false	"Filter by rank ID"
 //	@Param			posting_id			query		int		false	"Filter by posting ID"
 //	@Param			formation_id		query		int		false	"Filter by formation ID"
 //	@Param			region_id			query		int		false	"Filter by region ID"
+//	@Param			format				query		string	false	"Set to csv to export every matching row"
 //	@Success		200					{object}	envelope
 //	@Failure		500					{object}	errorResponse
 //	@Router			/v1/officers [get]
@@ -188,6 +197,13 @@ func (app *appDependencies) listOfficersHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	filters.Scope = app.contextGetScope(r)
+
+	if app.wantsCSV(r) {
+		app.writeOfficersCSV(w, r, regulationNumber, rankID, postingID, formationID, regionID, filters)
+		return
+	}
+
 	officers, metadata, err := app.models.Officer.GetAll(regulationNumber, rankID, postingID, formationID, regionID, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -204,6 +220,32 @@ func (app *appDependencies) listOfficersHandler(w http.ResponseWriter, r *http.R
 	}
 }
 
+// writeOfficersCSV streams every officer matching the filters, with their names, rank and assignments
+// spelled out
+func (app *appDependencies) writeOfficersCSV(w http.ResponseWriter, r *http.Request, regulationNumber string, rankID, postingID, formationID, regionID *int64, filters data.Filters) {
+	names, err := app.models.Lookup.Names(data.LookupUsers, data.LookupRanks, data.LookupPostings, data.LookupFormations, data.LookupRegions)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	header := []string{"ID", "Regulation Number", "Name", "Rank", "Posting", "Formation", "Region", "Created At"}
+	app.streamCSV(w, r, "officers", header, func(write func([]string) error) error {
+		return app.models.Officer.Each(regulationNumber, rankID, postingID, formationID, regionID, filters, func(o *data.Officer) error {
+			return write([]string{
+				strconv.FormatInt(o.ID, 10),
+				o.RegulationNumber,
+				csvName(names[data.LookupUsers], o.UserID),
+				csvName(names[data.LookupRanks], o.RankID),
+				csvName(names[data.LookupPostings], o.PostingID),
+				csvName(names[data.LookupFormations], o.FormationID),
+				csvName(names[data.LookupRegions], o.RegionID),
+				csvTimestamp(o.CreatedAt),
+			})
+		})
+	})
+}
+
 // updateOfficerHandler updates an existing officer
 //
 //	@Summary		Update an officer
@@ -227,7 +269,7 @@ func (app *appDependencies) updateOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	officer, err := app.models.Officer.Get(id)
+	officer, err := app.models.Officer.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -238,6 +280,8 @@ func (app *appDependencies) updateOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	before := *officer
+
 	var input struct {
 		RegulationNumber *string `json:"regulation_number"`
 		RankID           *int64  `json:"rank_id"`
@@ -269,6 +313,7 @@ func (app *appDependencies) updateOfficerHandler(w http.ResponseWriter, r *http.
 
 	v := validator.New()
 	data.ValidateOfficer(v, officer)
+	checkInScope(v, app.contextGetScope(r), officer.RegionID, officer.FormationID)
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -290,6 +335,8 @@ func (app *appDependencies) updateOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityOfficer, officer.ID, data.AuditActionUpdate, &before, officer)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"officer": officer}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -314,6 +361,17 @@ func (app *appDependencies) deleteOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	officer, err := app.models.Officer.GetInScope(id, app.contextGetScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.Officer.Delete(id)
 	if err != nil {
 		switch {
@@ -325,6 +383,8 @@ func (app *appDependencies) deleteOfficerHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityOfficer, officer.ID, data.AuditActionDelete, officer, nil)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "officer successfully deleted"}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -350,6 +410,9 @@ func (app *appDependencies) getUserOfficerHandler(w http.ResponseWriter, r *http
 	}
 
 	officer, err := app.models.Officer.GetByUserID(userID)
+	if err == nil {
+		officer, err = app.models.Officer.GetInScope(officer.ID, app.contextGetScope(r))
+	}
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
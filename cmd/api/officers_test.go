@@ -2,11 +2,15 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -507,6 +511,64 @@ func TestListOfficersHandler(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Export officers as CSV",
+			queryParams:    "?format=csv&page_size=1",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating CSV export")
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected text/csv content type, got %q", ct)
+				}
+
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) == 0 || records[0][0] != "ID" || records[0][3] != "Rank" {
+					t.Fatalf("Unexpected CSV header: %v", records)
+				}
+
+				// page_size is ignored for exports, so every seeded officer is included
+				if len(records) < 4 {
+					t.Errorf("Expected at least 3 officer rows, got %d", len(records)-1)
+				}
+
+				for _, record := range records[1:] {
+					if _, err := strconv.Atoi(record[3]); err == nil {
+						t.Errorf("Expected rank name for officer %s, got %q", record[0], record[3])
+					}
+				}
+			},
+		},
+		{
+			name:           "Export with no matching officers",
+			queryParams:    "?format=csv&regulation_number=NOSUCHOFFICER",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating empty CSV export")
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) != 1 || records[0][0] != "ID" {
+					t.Errorf("Expected only the header row, got %v", records)
+				}
+			},
+		},
+		{
+			name:           "Export with an invalid filter",
+			queryParams:    "?format=csv&rank_id=abc",
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating export error response")
+				if ct := res.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/csv") {
+					t.Errorf("Expected a JSON error rather than a CSV file, got %q", ct)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -942,3 +1004,418 @@ func TestOfficerWorkflow(t *testing.T) {
 
 	t.Log("Step: Complete officer workflow test passed successfully!")
 }
+
+func TestImportOfficersHandler(t *testing.T) {
+	t.Log("=== Testing Import Officers Handler ===")
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	formation, err := testApp.models.Formation.Get(1)
+	if err != nil {
+		t.Fatalf("Failed to get formation: %v", err)
+	}
+
+	suffix := time.Now().UnixNano()
+	validRow := fmt.Sprintf("Import,Recruit,import%d@test-police-training.bz,Male,IMP%d,Constable,Relief,%s,", suffix, suffix, formation.Formation)
+
+	uploadAs := func(t *testing.T, query, field, filename, file string) *http.Response {
+		t.Helper()
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile(field, filename)
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		part.Write([]byte(file))
+		form.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/officers/import"+query, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req = setUserContext(req, adminUser)
+
+		rec := httptest.NewRecorder()
+		testApp.importOfficersHandler(rec, req)
+		return rec.Result()
+	}
+
+	upload := func(t *testing.T, query, file string) *http.Response {
+		t.Helper()
+		return uploadAs(t, query, "file", "recruits.csv", file)
+	}
+
+	decode := func(t *testing.T, res *http.Response) map[string]any {
+		t.Helper()
+		var response map[string]any
+		if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	header := "First Name,Last Name,Email,Gender,Regulation Number,Rank,Posting,Formation,Region\n"
+	badRow := fmt.Sprintf("Bad,Row,bad%d@test-police-training.bz,m,BAD%d,No Such Rank,Relief,%s,", suffix, suffix, formation.Formation)
+
+	t.Run("Dry run reports row errors", func(t *testing.T) {
+		res := upload(t, "?dry_run=true", header+validRow+"\n"+badRow+"\n")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status %d; got %d", http.StatusOK, res.StatusCode)
+		}
+
+		response := decode(t, res)
+		rows := response["rows"].([]any)
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+
+		if errs, ok := rows[0].(map[string]any)["errors"]; ok {
+			t.Errorf("Expected the first row to be valid, got %v", errs)
+		}
+
+		errs, ok := rows[1].(map[string]any)["errors"].(map[string]any)
+		if !ok || errs["rank"] == nil {
+			t.Errorf("Expected a rank error on row 3, got %v", rows[1])
+		}
+
+		if _, err := testApp.models.Officer.GetByRegulationNumber(fmt.Sprintf("IMP%d", suffix)); err == nil {
+			t.Error("Expected a dry run not to create officers")
+		}
+	})
+
+	t.Run("Duplicate rows within the file", func(t *testing.T) {
+		res := upload(t, "?dry_run=true", header+validRow+"\n"+validRow+"\n")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status %d; got %d", http.StatusOK, res.StatusCode)
+		}
+
+		rows := decode(t, res)["rows"].([]any)
+		if len(rows) != 2 {
+			t.Fatalf("Expected 2 rows, got %d", len(rows))
+		}
+
+		errs, ok := rows[1].(map[string]any)["errors"].(map[string]any)
+		if !ok || errs["email"] != "duplicates row 2" || errs["regulation_number"] != "duplicates row 2" {
+			t.Errorf("Expected row 3 to be reported as a duplicate of row 2, got %v", rows[1])
+		}
+	})
+
+	fileErrors := []struct {
+		name           string
+		query          string
+		field          string
+		filename       string
+		file           string
+		expectedStatus int
+	}{
+		{
+			name:           "Missing file field",
+			field:          "upload",
+			filename:       "recruits.csv",
+			file:           header + validRow + "\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unsupported file type",
+			field:          "file",
+			filename:       "recruits.txt",
+			file:           header + validRow + "\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Missing columns",
+			field:          "file",
+			filename:       "recruits.csv",
+			file:           "First Name,Last Name,Email\nImport,Recruit,missing@test-police-training.bz\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Header without officers",
+			field:          "file",
+			filename:       "recruits.csv",
+			file:           header,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Blank file",
+			field:          "file",
+			filename:       "recruits.csv",
+			file:           "\n\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Malformed CSV",
+			field:          "file",
+			filename:       "recruits.csv",
+			file:           header + "\"Import,Recruit\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid dry_run value",
+			query:          "?dry_run=maybe",
+			field:          "file",
+			filename:       "recruits.csv",
+			file:           header + validRow + "\n",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range fileErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			res := uploadAs(t, tt.query, tt.field, tt.filename, tt.file)
+			defer res.Body.Close()
+
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("Body that is not a multipart form", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/officers/import", strings.NewReader(header+validRow))
+		req.Header.Set("Content-Type", "text/csv")
+		req = setUserContext(req, adminUser)
+
+		rec := httptest.NewRecorder()
+		testApp.importOfficersHandler(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status %d; got %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	if _, err := testApp.models.Officer.GetByRegulationNumber(fmt.Sprintf("IMP%d", suffix)); err == nil {
+		t.Fatal("Expected rejected uploads not to create officers")
+	}
+
+	t.Run("Invalid rows stop the import", func(t *testing.T) {
+		res := upload(t, "", header+validRow+"\n"+badRow+"\n")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status %d; got %d", http.StatusUnprocessableEntity, res.StatusCode)
+		}
+
+		if _, err := testApp.models.Officer.GetByRegulationNumber(fmt.Sprintf("IMP%d", suffix)); err == nil {
+			t.Error("Expected no officers to be created when a row is invalid")
+		}
+	})
+
+	t.Run("Valid file creates officers", func(t *testing.T) {
+		res := upload(t, "", header+validRow+"\n")
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusCreated {
+			t.Fatalf("Expected status %d; got %d", http.StatusCreated, res.StatusCode)
+		}
+
+		officer, err := testApp.models.Officer.GetByRegulationNumber(fmt.Sprintf("IMP%d", suffix))
+		if err != nil {
+			t.Fatalf("Expected the imported officer to exist: %v", err)
+		}
+		defer testApp.models.User.HardDelete(officer.UserID) // Cleanup
+
+		if officer.RegionID != formation.RegionID {
+			t.Errorf("Expected region to default to the formation's region %d, got %d", formation.RegionID, officer.RegionID)
+		}
+
+		// Until the invitation is accepted no password opens the account
+		user, err := testApp.models.User.Get(officer.UserID)
+		if err != nil {
+			t.Fatalf("Failed to get imported user: %v", err)
+		}
+		matches, err := user.Password.Matches("Password123!")
+		if err != nil {
+			t.Fatalf("Expected the imported user's password hash to be well formed: %v", err)
+		}
+		if matches {
+			t.Error("Expected the imported user to have no usable password")
+		}
+	})
+}
+
+func TestScopedOfficerAccess(t *testing.T) {
+	t.Log("=== Testing Scoped Officer Access ===")
+
+	// Both test officers start in formation 1; move one of them out of it
+	inside, insideUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(insideUser.ID) // Cleanup
+	outside, outsideUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(outsideUser.ID) // Cleanup
+
+	otherFormation, err := testApp.models.Formation.Get(2)
+	if err != nil {
+		t.Fatalf("Failed to get formation 2: %v", err)
+	}
+	outside.FormationID = otherFormation.ID
+	if err := testApp.models.Officer.Update(outside); err != nil {
+		t.Fatalf("Failed to move test officer: %v", err)
+	}
+
+	// A commander whose Content-Contributor role is held in formation 1 only
+	_, commander, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(commander.ID) // Cleanup
+
+	formationID := inside.FormationID
+	if err := testApp.models.RoleUser.Assign(commander.ID, nil, &formationID, "Content-Contributor"); err != nil {
+		t.Fatalf("Failed to assign scoped role: %v", err)
+	}
+
+	scope, err := testApp.models.RoleUser.GetScope(commander.ID, "officers:view")
+	if err != nil {
+		t.Fatalf("Failed to get scope: %v", err)
+	}
+	if scope.National || !slices.Equal(scope.FormationIDs, []int64{formationID}) {
+		t.Fatalf("Expected scope limited to formation %d; got %+v", formationID, scope)
+	}
+
+	newRequest := func(target string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req = setUserContext(req, commander)
+		return testApp.contextSetScope(req, scope)
+	}
+
+	tests := []struct {
+		name           string
+		officerID      int64
+		expectedStatus int
+	}{
+		{name: "Officer inside scope", officerID: inside.ID, expectedStatus: http.StatusOK},
+		{name: "Officer outside scope", officerID: outside.ID, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := strconv.FormatInt(tt.officerID, 10)
+			req := setURLParam(newRequest("/v1/officers/"+id), "id", id)
+
+			rec := httptest.NewRecorder()
+			testApp.showOfficerHandler(rec, req)
+
+			t.Logf("Step: Received status code %d", rec.Code)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+
+	t.Run("List only returns officers inside scope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		testApp.listOfficersHandler(rec, newRequest("/v1/officers?page_size=100"))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+		}
+
+		var response struct {
+			Officers []data.Officer `json:"officers"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&response)
+
+		for _, officer := range response.Officers {
+			if officer.FormationID != formationID {
+				t.Errorf("Expected only officers in formation %d; got officer %d in formation %d", formationID, officer.ID, officer.FormationID)
+			}
+		}
+	})
+
+	t.Run("Update officer into formation outside scope", func(t *testing.T) {
+		id := strconv.FormatInt(inside.ID, 10)
+		body, _ := json.Marshal(map[string]any{"formation_id": otherFormation.ID})
+		req := httptest.NewRequest(http.MethodPatch, "/v1/officers/"+id, bytes.NewReader(body))
+		req = setURLParam(setUserContext(req, commander), "id", id)
+		req = testApp.contextSetScope(req, scope)
+
+		rec := httptest.NewRecorder()
+		testApp.updateOfficerHandler(rec, req)
+
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d; got %d", http.StatusUnprocessableEntity, rec.Code)
+		}
+	})
+}
+
+func TestCurrentOfficerHandlers(t *testing.T) {
+	t.Log("=== Testing Officer Self-Service Handlers ===")
+
+	officer, user := getSeededOfficer(t)
+
+	get := func(handler http.HandlerFunc, path string, user *data.User) *httptest.ResponseRecorder {
+		req := setUserContext(httptest.NewRequest(http.MethodGet, path, nil), user)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	decode := func(rec *httptest.ResponseRecorder, dst any) {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		if err := json.NewDecoder(rec.Body).Decode(dst); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+
+	t.Log("Step: Officer record")
+	var officerResponse struct {
+		Officer data.Officer `json:"officer"`
+	}
+	decode(get(testApp.showCurrentOfficerHandler, "/v1/me/officer", user), &officerResponse)
+	if officerResponse.Officer.ID != officer.ID {
+		t.Errorf("Expected officer ID %d; got %d", officer.ID, officerResponse.Officer.ID)
+	}
+
+	t.Log("Step: Enrollments are limited to the officer")
+	var enrollmentsResponse struct {
+		TrainingEnrollments []data.TrainingEnrollment `json:"training_enrollments"`
+	}
+	decode(get(testApp.listCurrentOfficerEnrollmentsHandler, "/v1/me/enrollments?page_size=100", user), &enrollmentsResponse)
+	for _, enrollment := range enrollmentsResponse.TrainingEnrollments {
+		if enrollment.OfficerID != officer.ID {
+			t.Errorf("Expected only enrollments of officer %d; got one of officer %d", officer.ID, enrollment.OfficerID)
+		}
+	}
+
+	t.Log("Step: Upcoming sessions")
+	var sessionsResponse struct {
+		UpcomingSessions []data.CalendarEvent `json:"upcoming_sessions"`
+	}
+	decode(get(testApp.listCurrentOfficerUpcomingSessionsHandler, "/v1/me/upcoming-sessions", user), &sessionsResponse)
+	today := time.Now().Truncate(24 * time.Hour)
+	for _, session := range sessionsResponse.UpcomingSessions {
+		if session.Cancelled || session.SessionDate.Before(today.AddDate(0, 0, -1)) {
+			t.Errorf("Expected only upcoming sessions; got session %d on %s", session.SessionID, session.SessionDate.Format(time.DateOnly))
+		}
+	}
+
+	t.Log("Step: Certificates are limited to the officer")
+	var certificatesResponse struct {
+		Certificates []data.CertificateDetails `json:"certificates"`
+	}
+	decode(get(testApp.listCurrentOfficerCertificatesHandler, "/v1/me/certificates", user), &certificatesResponse)
+	for _, certificate := range certificatesResponse.Certificates {
+		if certificate.RegulationNumber != officer.RegulationNumber {
+			t.Errorf("Expected only certificates of %s; got one of %s", officer.RegulationNumber, certificate.RegulationNumber)
+		}
+	}
+
+	t.Log("Step: Compliance")
+	var complianceResponse map[string]any
+	decode(get(testApp.showCurrentOfficerComplianceHandler, "/v1/me/compliance", user), &complianceResponse)
+	if complianceResponse["compliance"] == nil {
+		t.Error("Expected compliance in the response")
+	}
+	if rec := get(testApp.showCurrentOfficerComplianceHandler, "/v1/me/compliance?year=1800", user); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for an invalid year; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Users without an officer record get 404")
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	if rec := get(testApp.showCurrentOfficerHandler, "/v1/me/officer", adminUser); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d; got %d", http.StatusNotFound, rec.Code)
+	}
+}
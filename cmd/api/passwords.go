@@ -0,0 +1,96 @@
+// FileName: cmd/api/passwords.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// Limits on the password flags. Argon2id panics with fewer than one pass or thread and stores parallelism
+// in a byte; the history keeps the current password beside data.PasswordHistoryLimit older ones.
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+	maxArgon2Memory   = 4 * 1024 * 1024 // 4 GiB, in KiB
+)
+
+// validatePasswordConfig reports every password flag that is out of range, so the server refuses to start
+// rather than failing on the first login or password change
+func validatePasswordConfig(cfg serverConfig) error {
+	var errs []error
+
+	p := cfg.password
+
+	if p.parallelism < 1 || p.parallelism > math.MaxUint8 {
+		errs = append(errs, fmt.Errorf("-argon2-parallelism must be between 1 and %d, got %d", math.MaxUint8, p.parallelism))
+	}
+	if p.iterations < 1 || int64(p.iterations) > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("-argon2-iterations must be between 1 and %d, got %d", uint32(math.MaxUint32), p.iterations))
+	}
+	if p.memory < 8*max(p.parallelism, 1) || p.memory > maxArgon2Memory {
+		errs = append(errs, fmt.Errorf("-argon2-memory must be between %d (8 KiB per thread) and %d KiB, got %d", 8*max(p.parallelism, 1), maxArgon2Memory, p.memory))
+	}
+	if p.minLength < minPasswordLength || p.minLength > maxPasswordLength {
+		errs = append(errs, fmt.Errorf("-password-min-length must be between %d and %d, got %d", minPasswordLength, maxPasswordLength, p.minLength))
+	}
+	if p.history < 0 || p.history > data.PasswordHistoryLimit+1 {
+		errs = append(errs, fmt.Errorf("-password-history must be between 0 and %d, got %d", data.PasswordHistoryLimit+1, p.history))
+	}
+
+	return errors.Join(errs...)
+}
+
+// passwordParams returns the configured Argon2id parameters for new password hashes
+func (app *appDependencies) passwordParams() data.PasswordParams {
+	return data.PasswordParams{
+		Memory:      uint32(app.config.password.memory),
+		Iterations:  uint32(app.config.password.iterations),
+		Parallelism: uint8(app.config.password.parallelism),
+	}
+}
+
+// passwordPolicy returns the configured rules for new passwords
+func (app *appDependencies) passwordPolicy() data.PasswordPolicy {
+	return data.PasswordPolicy{
+		MinLength:   app.config.password.minLength,
+		HistorySize: app.config.password.history,
+		Breached:    app.breachedPasswords,
+	}
+}
+
+// validateNewPassword checks a password a user is choosing against the password policy, including
+// whether they have used it recently. userID is 0 for users who do not exist yet.
+func (app *appDependencies) validateNewPassword(v *validator.Validator, userID int64, password string) error {
+	policy := app.passwordPolicy()
+	data.ValidatePasswordPolicy(v, password, policy)
+
+	// The history is only worth the slow hash comparisons for an otherwise acceptable password
+	if userID == 0 || !v.IsEmpty() {
+		return nil
+	}
+
+	reused, err := app.models.User.PasswordReused(userID, password, policy.HistorySize)
+	if err != nil {
+		return err
+	}
+	v.Check(!reused, "password", fmt.Sprintf("must not be one of your last %d passwords", policy.HistorySize))
+
+	return nil
+}
+
+// rehashPassword upgrades a user's stored hash to the current parameters once their password has been
+// checked at login. A failure is only logged, as the old hash still works.
+func (app *appDependencies) rehashPassword(user *data.User, password string) {
+	params := app.passwordParams()
+	if !user.Password.NeedsRehash(params) {
+		return
+	}
+
+	if err := app.models.User.RehashPassword(user, password, params); err != nil {
+		app.logger.Error("failed to rehash password", "user_id", user.ID, "error", err)
+	}
+}
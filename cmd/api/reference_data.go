@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
@@ -57,6 +58,8 @@ func (app *appDependencies) createRegionHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityRegion, region.ID, data.AuditActionCreate, nil, region)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/regions/%d", region.ID))
 
@@ -105,12 +108,13 @@ func (app *appDependencies) showRegionHandler(w http.ResponseWriter, r *http.Req
 //	@Summary		List regions
 //	@Description	Retrieve a list of regions with optional filtering
 //	@Tags			regions
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			region		query		string	false	"Filter by region name"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -127,17 +131,31 @@ func (app *appDependencies) listRegionsHandler(w http.ResponseWriter, r *http.Re
 
 	search := likeSearch(app.getSingleQueryParameter(query, "region", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	regions, metadata, err := app.models.Region.GetAll(search, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeRegionsCSV(w, r, regions)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"regions": regions, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeRegionsCSV exports regions
+func (app *appDependencies) writeRegionsCSV(w http.ResponseWriter, r *http.Request, regions []*data.Region) {
+	app.writeCSV(w, r, "regions", []string{"ID", "Region"}, len(regions), func(i int) []string {
+		return []string{strconv.FormatInt(regions[i].ID, 10), regions[i].Region}
+	})
+}
+
 // updateRegionHandler performs a partial update on a region record.
 //
 //	@Summary		Update a region
@@ -172,6 +190,8 @@ func (app *appDependencies) updateRegionHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	before := *region
+
 	if err := app.readJSON(w, r, &UpdateRegionRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -201,6 +221,8 @@ func (app *appDependencies) updateRegionHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityRegion, region.ID, data.AuditActionUpdate, &before, region)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"region": region}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -253,6 +275,8 @@ func (app *appDependencies) createFormationHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityFormation, formation.ID, data.AuditActionCreate, nil, formation)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/formations/%d", formation.ID))
 
@@ -301,13 +325,14 @@ func (app *appDependencies) showFormationHandler(w http.ResponseWriter, r *http.
 //	@Summary		List formations
 //	@Description	Retrieve a list of formations with optional filtering by name and region
 //	@Tags			formations
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			formation	query		string	false	"Filter by formation name"
 //	@Param			region_id	query		int		false	"Filter by region ID"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -327,17 +352,38 @@ func (app *appDependencies) listFormationsHandler(w http.ResponseWriter, r *http
 
 	name := likeSearch(app.getSingleQueryParameter(query, "formation", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	formations, metadata, err := app.models.Formation.GetAll(name, regionID, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeFormationsCSV(w, r, formations)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"formations": formations, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeFormationsCSV exports formations with their region spelled out
+func (app *appDependencies) writeFormationsCSV(w http.ResponseWriter, r *http.Request, formations []*data.Formation) {
+	names, err := app.models.Lookup.Names(data.LookupRegions)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeCSV(w, r, "formations", []string{"ID", "Formation", "Region"}, len(formations), func(i int) []string {
+		f := formations[i]
+		return []string{strconv.FormatInt(f.ID, 10), f.Formation, csvName(names[data.LookupRegions], f.RegionID)}
+	})
+}
+
 // updateFormationHandler performs a partial update on a formation record.
 //
 //	@Summary		Update a formation
@@ -372,6 +418,8 @@ func (app *appDependencies) updateFormationHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	before := *formation
+
 	if err := app.readJSON(w, r, &UpdateFormationRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -407,6 +455,8 @@ func (app *appDependencies) updateFormationHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityFormation, formation.ID, data.AuditActionUpdate, &before, formation)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"formation": formation}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -458,6 +508,8 @@ func (app *appDependencies) createPostingHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityPosting, posting.ID, data.AuditActionCreate, nil, posting)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/postings/%d", posting.ID))
 
@@ -506,13 +558,14 @@ func (app *appDependencies) showPostingHandler(w http.ResponseWriter, r *http.Re
 //	@Summary		List postings
 //	@Description	Retrieve a list of postings with optional filtering by name and code
 //	@Tags			postings
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			posting		query		string	false	"Filter by posting name"
 //	@Param			code		query		string	false	"Filter by posting code"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -530,17 +583,31 @@ func (app *appDependencies) listPostingsHandler(w http.ResponseWriter, r *http.R
 	name := likeSearch(app.getSingleQueryParameter(query, "posting", ""))
 	code := likeSearch(app.getSingleQueryParameter(query, "code", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	postings, metadata, err := app.models.Posting.GetAll(name, code, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writePostingsCSV(w, r, postings)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"postings": postings, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writePostingsCSV exports postings
+func (app *appDependencies) writePostingsCSV(w http.ResponseWriter, r *http.Request, postings []*data.Posting) {
+	app.writeCSV(w, r, "postings", []string{"ID", "Posting", "Code"}, len(postings), func(i int) []string {
+		return []string{strconv.FormatInt(postings[i].ID, 10), postings[i].Posting, postings[i].Code}
+	})
+}
+
 // updatePostingHandler performs a partial update on a posting record.
 //
 //	@Summary		Update a posting
@@ -575,6 +642,8 @@ func (app *appDependencies) updatePostingHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	before := *posting
+
 	if err := app.readJSON(w, r, &UpdatePostingRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -602,6 +671,8 @@ func (app *appDependencies) updatePostingHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityPosting, posting.ID, data.AuditActionUpdate, &before, posting)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"posting": posting}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -654,6 +725,8 @@ func (app *appDependencies) createRankHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityRank, rank.ID, data.AuditActionCreate, nil, rank)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/ranks/%d", rank.ID))
 
@@ -702,13 +775,14 @@ func (app *appDependencies) showRankHandler(w http.ResponseWriter, r *http.Reque
 //	@Summary		List ranks
 //	@Description	Retrieve a list of ranks with optional filtering by rank name and code
 //	@Tags			ranks
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			rank		query		string	false	"Filter by rank name"
 //	@Param			code		query		string	false	"Filter by rank code"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -726,17 +800,31 @@ func (app *appDependencies) listRanksHandler(w http.ResponseWriter, r *http.Requ
 	rankFilter := likeSearch(app.getSingleQueryParameter(query, "rank", ""))
 	codeFilter := likeSearch(app.getSingleQueryParameter(query, "code", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	ranks, metadata, err := app.models.Rank.GetAll(rankFilter, codeFilter, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeRanksCSV(w, r, ranks)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"ranks": ranks, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeRanksCSV exports ranks
+func (app *appDependencies) writeRanksCSV(w http.ResponseWriter, r *http.Request, ranks []*data.Rank) {
+	app.writeCSV(w, r, "ranks", []string{"ID", "Rank", "Code", "Annual Training Hours"}, len(ranks), func(i int) []string {
+		return []string{strconv.FormatInt(ranks[i].ID, 10), ranks[i].Rank, ranks[i].Code, strconv.Itoa(ranks[i].AnnualTrainingHours)}
+	})
+}
+
 // updateRankHandler performs a partial update on a rank record.
 //
 //	@Summary		Update a rank
@@ -771,6 +859,8 @@ func (app *appDependencies) updateRankHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	before := *rank
+
 	if err := app.readJSON(w, r, &UpdateRankRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -806,6 +896,8 @@ func (app *appDependencies) updateRankHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityRank, rank.ID, data.AuditActionUpdate, &before, rank)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"rank": rank}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -854,6 +946,8 @@ func (app *appDependencies) createTrainingTypeHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingType, trainingType.ID, data.AuditActionCreate, nil, trainingType)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/training-types/%d", trainingType.ID))
 
@@ -902,12 +996,13 @@ func (app *appDependencies) showTrainingTypeHandler(w http.ResponseWriter, r *ht
 //	@Summary		List training types
 //	@Description	Retrieve a list of training types with optional filtering by type name
 //	@Tags			training-types
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			type		query		string	false	"Filter by training type name"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -924,17 +1019,31 @@ func (app *appDependencies) listTrainingTypesHandler(w http.ResponseWriter, r *h
 
 	name := likeSearch(app.getSingleQueryParameter(query, "type", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	types, metadata, err := app.models.TrainingType.GetAll(name, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeTrainingTypesCSV(w, r, types)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_types": types, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeTrainingTypesCSV exports training types
+func (app *appDependencies) writeTrainingTypesCSV(w http.ResponseWriter, r *http.Request, types []*data.TrainingType) {
+	app.writeCSV(w, r, "training-types", []string{"ID", "Type", "Active"}, len(types), func(i int) []string {
+		return []string{strconv.FormatInt(types[i].ID, 10), types[i].Type, csvBool(types[i].IsActive)}
+	})
+}
+
 // updateTrainingTypeHandler performs a partial update on a training type record.
 //
 //	@Summary		Update a training type
@@ -969,6 +1078,8 @@ func (app *appDependencies) updateTrainingTypeHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	before := *typeRecord
+
 	if err := app.readJSON(w, r, &UpdateTrainingTypeRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -998,6 +1109,8 @@ func (app *appDependencies) updateTrainingTypeHandler(w http.ResponseWriter, r *
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingType, typeRecord.ID, data.AuditActionUpdate, &before, typeRecord)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_type": typeRecord}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1052,6 +1165,8 @@ func (app *appDependencies) createTrainingCategoryHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingCategory, category.ID, data.AuditActionCreate, nil, category)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/training/categories/%d", category.ID))
 
@@ -1100,13 +1215,14 @@ func (app *appDependencies) showTrainingCategoryHandler(w http.ResponseWriter, r
 //	@Summary		List training categories
 //	@Description	Retrieve a list of training categories with optional filtering by name and active status
 //	@Tags			training-categories
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			name		query		string	false	"Filter by category name"
 //	@Param			is_active	query		bool	false	"Filter by active status"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -1126,17 +1242,31 @@ func (app *appDependencies) listTrainingCategoriesHandler(w http.ResponseWriter,
 
 	name := likeSearch(app.getSingleQueryParameter(query, "name", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	categories, metadata, err := app.models.TrainingCategory.GetAll(name, isActive, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeTrainingCategoriesCSV(w, r, categories)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_categories": categories, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeTrainingCategoriesCSV exports training categories
+func (app *appDependencies) writeTrainingCategoriesCSV(w http.ResponseWriter, r *http.Request, categories []*data.TrainingCategory) {
+	app.writeCSV(w, r, "training-categories", []string{"ID", "Category", "Active"}, len(categories), func(i int) []string {
+		return []string{strconv.FormatInt(categories[i].ID, 10), categories[i].Name, csvBool(categories[i].IsActive)}
+	})
+}
+
 // updateTrainingCategoryHandler performs a partial update on a training category record.
 //
 //	@Summary		Update a training category
@@ -1171,6 +1301,8 @@ func (app *appDependencies) updateTrainingCategoryHandler(w http.ResponseWriter,
 		return
 	}
 
+	before := *category
+
 	if err := app.readJSON(w, r, &UpdateTrainingCategoryRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -1203,6 +1335,8 @@ func (app *appDependencies) updateTrainingCategoryHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingCategory, category.ID, data.AuditActionUpdate, &before, category)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_category": category}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1251,6 +1385,8 @@ func (app *appDependencies) createTrainingStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingStatus, status.ID, data.AuditActionCreate, nil, status)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/training/status/%d", status.ID))
 
@@ -1299,12 +1435,13 @@ func (app *appDependencies) showTrainingStatusHandler(w http.ResponseWriter, r *
 //	@Summary		List training statuses
 //	@Description	Retrieve a list of training statuses with optional filtering by status name
 //	@Tags			training-statuses
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			status		query		string	false	"Filter by status name"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -1321,17 +1458,32 @@ func (app *appDependencies) getTrainingStatusesHandler(w http.ResponseWriter, r
 
 	name := likeSearch(app.getSingleQueryParameter(query, "status", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	statuses, metadata, err := app.models.TrainingStatus.GetAll(name, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeStatusesCSV(w, r, "training-statuses", len(statuses), func(i int) (int64, string) { return statuses[i].ID, statuses[i].Status })
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_statuses": statuses, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeStatusesCSV exports any of the status lookup tables
+func (app *appDependencies) writeStatusesCSV(w http.ResponseWriter, r *http.Request, filename string, count int, status func(i int) (int64, string)) {
+	app.writeCSV(w, r, filename, []string{"ID", "Status"}, count, func(i int) []string {
+		id, name := status(i)
+		return []string{strconv.FormatInt(id, 10), name}
+	})
+}
+
 // updateTrainingStatusHandler performs a partial update on a training status record.
 //
 //	@Summary		Update a training status
@@ -1366,6 +1518,8 @@ func (app *appDependencies) updateTrainingStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	before := *status
+
 	if err := app.readJSON(w, r, &UpdateTrainingStatusRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -1395,6 +1549,8 @@ func (app *appDependencies) updateTrainingStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingStatus, status.ID, data.AuditActionUpdate, &before, status)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"training_status": status}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1443,6 +1599,8 @@ func (app *appDependencies) createEnrollmentStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityEnrollmentStatus, status.ID, data.AuditActionCreate, nil, status)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/enrollment/status/%d", status.ID))
 
@@ -1491,12 +1649,13 @@ func (app *appDependencies) showEnrollmentStatusHandler(w http.ResponseWriter, r
 //	@Summary		List enrollment statuses
 //	@Description	Retrieve a list of enrollment statuses with optional filtering by status name
 //	@Tags			enrollment-statuses
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			status		query		string	false	"Filter by status name"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -1513,12 +1672,19 @@ func (app *appDependencies) listEnrollmentStatusesHandler(w http.ResponseWriter,
 
 	name := likeSearch(app.getSingleQueryParameter(query, "status", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	statuses, metadata, err := app.models.EnrollmentStatus.GetAll(name, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeStatusesCSV(w, r, "enrollment-statuses", len(statuses), func(i int) (int64, string) { return statuses[i].ID, statuses[i].Status })
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"enrollment_statuses": statuses, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1558,6 +1724,8 @@ func (app *appDependencies) updateEnrollmentStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	before := *status
+
 	if err := app.readJSON(w, r, &UpdateEnrollmentStatusRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -1587,6 +1755,8 @@ func (app *appDependencies) updateEnrollmentStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityEnrollmentStatus, status.ID, data.AuditActionUpdate, &before, status)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"enrollment_status": status}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1637,6 +1807,8 @@ func (app *appDependencies) createAttendanceStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityAttendanceStatus, status.ID, data.AuditActionCreate, nil, status)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/attendance/status/%d", status.ID))
 
@@ -1685,13 +1857,14 @@ func (app *appDependencies) showAttendanceStatusHandler(w http.ResponseWriter, r
 //	@Summary		List attendance statuses
 //	@Description	Retrieve a list of attendance statuses with optional filtering
 //	@Tags			attendance-statuses
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			status				query		string	false	"Filter by status name"
 //	@Param			counts_as_present	query		bool	false	"Filter by counts as present flag"
 //	@Param			page				query		int		false	"Page number for pagination"
 //	@Param			page_size			query		int		false	"Number of items per page"
 //	@Param			sort				query		string	false	"Sort order"
+//	@Param			format				query		string	false	"Set to csv to export every matching row"
 //	@Success		200					{object}	envelope
 //	@Failure		422					{object}	errorResponse
 //	@Failure		500					{object}	errorResponse
@@ -1711,17 +1884,31 @@ func (app *appDependencies) listAttendanceStatusesHandler(w http.ResponseWriter,
 
 	name := likeSearch(app.getSingleQueryParameter(query, "status", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	statuses, metadata, err := app.models.AttendanceStatus.GetAll(name, countsAsPresent, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeAttendanceStatusesCSV(w, r, statuses)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"attendance_statuses": statuses, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// writeAttendanceStatusesCSV exports attendance statuses
+func (app *appDependencies) writeAttendanceStatusesCSV(w http.ResponseWriter, r *http.Request, statuses []*data.AttendanceStatus) {
+	app.writeCSV(w, r, "attendance-statuses", []string{"ID", "Status", "Counts As Present"}, len(statuses), func(i int) []string {
+		return []string{strconv.FormatInt(statuses[i].ID, 10), statuses[i].Status, csvBool(statuses[i].CountsAsPresent)}
+	})
+}
+
 // updateAttendanceStatusHandler performs a partial update on an attendance status record.
 //
 //	@Summary		Update an attendance status
@@ -1756,6 +1943,8 @@ func (app *appDependencies) updateAttendanceStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	before := *status
+
 	if err := app.readJSON(w, r, &UpdateAttendanceStatusRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -1788,6 +1977,8 @@ func (app *appDependencies) updateAttendanceStatusHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityAttendanceStatus, status.ID, data.AuditActionUpdate, &before, status)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"attendance_status": status}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1835,6 +2026,8 @@ func (app *appDependencies) createProgressStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityProgressStatus, status.ID, data.AuditActionCreate, nil, status)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/progress/status/%d", status.ID))
 
@@ -1883,12 +2076,13 @@ func (app *appDependencies) showProgressStatusHandler(w http.ResponseWriter, r *
 //	@Summary		List progress statuses
 //	@Description	Retrieve a list of progress statuses with optional filtering
 //	@Tags			progress-statuses
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
 //	@Param			status		query		string	false	"Filter by status name"
 //	@Param			page		query		int		false	"Page number for pagination"
 //	@Param			page_size	query		int		false	"Number of items per page"
 //	@Param			sort		query		string	false	"Sort order"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
 //	@Success		200			{object}	envelope
 //	@Failure		422			{object}	errorResponse
 //	@Failure		500			{object}	errorResponse
@@ -1906,12 +2100,19 @@ func (app *appDependencies) listProgressStatusesHandler(w http.ResponseWriter, r
 
 	name := likeSearch(app.getSingleQueryParameter(query, "status", ""))
 
+	filters.Unpaged = app.wantsCSV(r)
+
 	statuses, metadata, err := app.models.ProgressStatus.GetAll(name, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	if filters.Unpaged {
+		app.writeStatusesCSV(w, r, "progress-statuses", len(statuses), func(i int) (int64, string) { return statuses[i].ID, statuses[i].Status })
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"progress_statuses": statuses, "metadata": metadata}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1951,6 +2152,8 @@ func (app *appDependencies) updateProgressStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	before := *status
+
 	if err := app.readJSON(w, r, &UpdateProgressStatusRequest); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -1980,6 +2183,8 @@ func (app *appDependencies) updateProgressStatusHandler(w http.ResponseWriter, r
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityProgressStatus, status.ID, data.AuditActionUpdate, &before, status)
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"progress_status": status}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
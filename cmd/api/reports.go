@@ -0,0 +1,70 @@
+// Filename: cmd/api/reports.go
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// complianceReportHandler aggregates officer compliance by region or formation
+//
+//	@Summary		Compliance rollup report
+//	@Description	Count compliant, on-track, at-risk and non-compliant officers and average credit hours per region or formation
+//	@Tags			reports
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			group_by	query		string	false	"Group by region or formation (default region)"
+//	@Param			year		query		int		false	"Compliance year (defaults to the current year)"
+//	@Param			rank_id		query		int		false	"Only include officers holding this rank"
+//	@Param			posting_id	query		int		false	"Only include officers in this posting"
+//	@Param			page		query		int		false	"Page number"
+//	@Param			page_size	query		int		false	"Page size"
+//	@Param			sort		query		string	false	"Sort field"
+//	@Success		200			{object}	envelope
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/reports/compliance [get]
+func (app *appDependencies) complianceReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	v := validator.New()
+
+	filters := app.readFilters(query, "unit", 20, []string{
+		"unit", "officers", "compliant", "on_track", "at_risk", "non_compliant", "average_hours",
+		"-unit", "-officers", "-compliant", "-on_track", "-at_risk", "-non_compliant", "-average_hours",
+	}, v)
+
+	groupBy := app.getSingleQueryParameter(query, "group_by", data.ComplianceGroupRegion)
+	year := app.getSingleIntQueryParameter(query, "year", time.Now().Year(), v)
+	rankID := app.getOptionalInt64QueryParameter(query, "rank_id", v)
+	postingID := app.getOptionalInt64QueryParameter(query, "posting_id", v)
+
+	v.Check(v.Permitted(groupBy, data.ComplianceGroups...), "group_by", "must be region or formation")
+	data.ValidateComplianceYear(v, year)
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	filters.Scope = app.contextGetScope(r)
+
+	rollups, metadata, err := app.models.Compliance.GetRollup(groupBy, rankID, postingID, year, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	payload := envelope{
+		"group_by": groupBy,
+		"year":     year,
+		"units":    rollups,
+		"metadata": metadata,
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, payload, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
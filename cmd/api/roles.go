@@ -0,0 +1,578 @@
+// Filename: cmd/api/roles.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+/************************************************************************************************************/
+// Roles
+/************************************************************************************************************/
+
+// listRolesHandler returns every role along with its permissions
+//
+//	@Summary		List roles
+//	@Description	Retrieve every role along with its permission codes
+//	@Tags			roles
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles [get]
+func (app *appDependencies) listRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.models.Role.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"roles": roles}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createRoleHandler creates a role, optionally with an initial set of permissions
+//
+//	@Summary		Create a role
+//	@Description	Create a role with an optional list of permission codes
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	envelope
+//	@Failure		400	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles [post]
+func (app *appDependencies) createRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Role        string   `json:"role"`
+		Permissions []string `json:"permissions"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := &data.Role{Role: input.Role}
+
+	v := validator.New()
+	data.ValidateRole(v, role)
+	if err := app.checkPermissionCodes(v, input.Permissions, false); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Role.Insert(role); err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateValue):
+			v.AddError("role", "a role with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if len(input.Permissions) > 0 {
+		if err := app.models.Permission.AssignToRole(role.ID, input.Permissions...); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		var err error
+		if role, err = app.models.Role.Get(role.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.recordAudit(r, data.AuditEntityRole, role.ID, data.AuditActionCreate, nil, role)
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/roles/%d", role.ID))
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"role": role}, headers); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// showRoleHandler returns a role along with its permissions
+//
+//	@Summary		Get a role
+//	@Description	Retrieve a role and its permission codes by ID
+//	@Tags			roles
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles/{id} [get]
+func (app *appDependencies) showRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role, ok := app.readRole(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"role": role}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateRoleHandler renames a role. The Admin role cannot be renamed.
+//
+//	@Summary		Rename a role
+//	@Description	Change the name of a role. The Admin role cannot be renamed.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles/{id} [patch]
+func (app *appDependencies) updateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role, ok := app.readRole(w, r)
+	if !ok {
+		return
+	}
+
+	before := *role
+
+	var input struct {
+		Role *string `json:"role"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Role != nil {
+		role.Role = *input.Role
+	}
+
+	v := validator.New()
+	data.ValidateRole(v, role)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Role.Update(role); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrAdminRole):
+			app.adminRoleResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateValue):
+			v.AddError("role", "a role with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityRole, role.ID, data.AuditActionUpdate, &before, role)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"role": role}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteRoleHandler deletes a role, taking it away from every user that holds it. The Admin role
+// cannot be deleted.
+//
+//	@Summary		Delete a role
+//	@Description	Delete a role and take it away from every user that holds it. The Admin role cannot be deleted.
+//	@Tags			roles
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles/{id} [delete]
+func (app *appDependencies) deleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role, ok := app.readRole(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.models.Role.Delete(role.ID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrAdminRole):
+			app.adminRoleResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityRole, role.ID, data.AuditActionDelete, role, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "role successfully deleted"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readRole loads the role named by the id parameter, sending the error response itself when it cannot
+func (app *appDependencies) readRole(w http.ResponseWriter, r *http.Request) (*data.Role, bool) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	role, err := app.models.Role.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return role, true
+}
+
+/************************************************************************************************************/
+// Role permissions
+/************************************************************************************************************/
+
+// listPermissionsHandler returns every permission code that can be given to a role
+//
+//	@Summary		List permissions
+//	@Description	Retrieve every permission code that can be given to a role
+//	@Tags			roles
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/permissions [get]
+func (app *appDependencies) listPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	permissions, err := app.models.Permission.GetAll()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"permissions": permissions}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// addRolePermissionsHandler gives a role more permissions. Codes the role already has are ignored.
+//
+//	@Summary		Add permissions to a role
+//	@Description	Give a role a list of permission codes. Codes the role already has are ignored.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles/{id}/permissions [post]
+func (app *appDependencies) addRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeRolePermissions(w, r, app.models.Permission.AssignToRole)
+}
+
+// removeRolePermissionsHandler takes permissions away from a role. The Admin role keeps every permission.
+//
+//	@Summary		Remove permissions from a role
+//	@Description	Take a list of permission codes away from a role. The Admin role keeps every permission.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Role ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/roles/{id}/permissions [delete]
+func (app *appDependencies) removeRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeRolePermissions(w, r, app.models.Permission.RemoveFromRole)
+}
+
+// changeRolePermissions reads a list of permission codes and applies change to the role, responding
+// with the role as it is afterwards
+func (app *appDependencies) changeRolePermissions(w http.ResponseWriter, r *http.Request, change func(roleID int64, codes ...string) error) {
+	role, ok := app.readRole(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Permissions []string `json:"permissions"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if err := app.checkPermissionCodes(v, input.Permissions, true); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := change(role.ID, input.Permissions...); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrAdminRole):
+			app.adminRoleResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	updated, err := app.models.Role.Get(role.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityRole, role.ID, data.AuditActionUpdate, role, updated)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"role": updated}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// checkPermissionCodes records in v any code that is not a known permission
+func (app *appDependencies) checkPermissionCodes(v *validator.Validator, codes []string, required bool) error {
+	if required {
+		v.Check(len(codes) > 0, "permissions", "must contain at least one permission code")
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	permissions, err := app.models.Permission.GetAll()
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		known[permission.Code] = true
+	}
+
+	for _, code := range codes {
+		v.Check(known[code], "permissions", fmt.Sprintf("%q is not a known permission", code))
+	}
+
+	return nil
+}
+
+/************************************************************************************************************/
+// User roles
+/************************************************************************************************************/
+
+// listUserRolesHandler returns the roles held by a user and the region or formation each is held in
+//
+//	@Summary		List a user's roles
+//	@Description	Retrieve the names of the roles held by a user, and under assignments the region or formation each role is limited to
+//	@Tags			roles
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/users/{id}/roles [get]
+func (app *appDependencies) listUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	user, assignments, ok := app.readUserRoles(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"user_id": user.ID, "roles": roleNames(assignments), "assignments": assignments}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// addUserRolesHandler gives a user more roles, nationally or limited to one region or formation. Roles
+// the user already holds take on the new scope.
+//
+//	@Summary		Assign roles to a user
+//	@Description	Give a user a list of roles by name. Set region_id or formation_id to limit the roles to that region or formation; roles the user already holds take on the new scope.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/users/{id}/roles [post]
+func (app *appDependencies) addUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeUserRoles(w, r, app.models.RoleUser.Assign)
+}
+
+// removeUserRolesHandler takes roles away from a user. The Admin role cannot be taken from the last
+// active user that holds it.
+//
+//	@Summary		Unassign roles from a user
+//	@Description	Take a list of roles away from a user. The Admin role cannot be taken from the last active admin.
+//	@Tags			roles
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	envelope
+//	@Failure		404	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/users/{id}/roles [delete]
+func (app *appDependencies) removeUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	app.changeUserRoles(w, r, func(userID int64, _, _ *int64, roles ...string) error {
+		return app.models.Role.RemoveFromUser(userID, roles...)
+	})
+}
+
+// changeUserRoles reads a list of role names, along with an optional region or formation, and applies
+// change to the user, responding with the roles the user holds afterwards
+func (app *appDependencies) changeUserRoles(w http.ResponseWriter, r *http.Request, change func(userID int64, regionID, formationID *int64, roles ...string) error) {
+	user, before, ok := app.readUserRoles(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Roles       []string `json:"roles"`
+		RegionID    *int64   `json:"region_id"`
+		FormationID *int64   `json:"formation_id"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateRoleScope(v, input.RegionID, input.FormationID)
+	if err := app.validateRoleNames(v, input.Roles); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := change(user.ID, input.RegionID, input.FormationID, input.Roles...); err != nil {
+		switch {
+		case errors.Is(err, data.ErrLastAdmin):
+			app.lastAdminResponse(w, r)
+		case errors.Is(err, data.ErrForeignKeyViolation):
+			v.AddError("region_id", "must be an existing region or formation")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	after, err := app.models.RoleUser.GetAllForUser(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionUpdate, envelope{"roles": before}, envelope{"roles": after})
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"user_id": user.ID, "roles": roleNames(after), "assignments": after}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// validateRoleNames checks that at least one role is named and that every role named exists
+func (app *appDependencies) validateRoleNames(v *validator.Validator, names []string) error {
+	v.Check(len(names) > 0, "roles", "must contain at least one role")
+
+	roles, err := app.models.Role.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		known := slices.ContainsFunc(roles, func(role *data.Role) bool { return role.Role == name })
+		v.Check(known, "roles", fmt.Sprintf("%q is not a known role", name))
+	}
+
+	return nil
+}
+
+// readUserRoles loads the user named by the id parameter and the roles they hold, sending the error
+// response itself when it cannot
+func (app *appDependencies) readUserRoles(w http.ResponseWriter, r *http.Request) (*data.User, []*data.RoleUser, bool) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, nil, false
+	}
+
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, nil, false
+	}
+
+	assignments, err := app.models.RoleUser.GetAllForUser(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, nil, false
+	}
+
+	return user, assignments, true
+}
+
+// roleNames lists the names of the roles in a user's assignments, which come sorted by name
+func roleNames(assignments []*data.RoleUser) data.Roles {
+	roles := data.Roles{}
+	for _, assignment := range assignments {
+		roles = append(roles, assignment.Role)
+	}
+	return roles
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+)
+
+func TestRoleAdministrationHandlers(t *testing.T) {
+	t.Log("=== Testing Role Administration Handlers ===")
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	roles, err := testApp.models.Role.GetAll()
+	if err != nil {
+		t.Fatalf("Failed to get roles: %v", err)
+	}
+	idx := slices.IndexFunc(roles, func(role *data.Role) bool { return role.Role == data.AdminRole })
+	if idx < 0 {
+		t.Fatal("Admin role not found")
+	}
+	adminRoleID := strconv.FormatInt(roles[idx].ID, 10)
+
+	// Create a role to work with
+	roleName := fmt.Sprintf("Test Role %d", time.Now().UnixNano())
+	body, _ := json.Marshal(map[string]any{"role": roleName, "permissions": []string{"workshops:view"}})
+	req := httptest.NewRequest(http.MethodPost, "/v1/roles", bytes.NewReader(body))
+	req = setUserContext(req, adminUser)
+	rec := httptest.NewRecorder()
+	testApp.createRoleHandler(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Failed to create test role: status %d", rec.Code)
+	}
+
+	var created struct {
+		Role data.Role `json:"role"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&created)
+	defer testApp.models.Role.Delete(created.Role.ID) // Cleanup
+
+	if !created.Role.Permissions.Includes("workshops:view") {
+		t.Errorf("Expected new role to have workshops:view; got %v", created.Role.Permissions)
+	}
+
+	roleID := strconv.FormatInt(created.Role.ID, 10)
+
+	tests := []struct {
+		name           string
+		method         string
+		id             string
+		input          map[string]any
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{
+			name:           "Create role with unknown permission",
+			method:         http.MethodPost,
+			input:          map[string]any{"role": roleName + " B", "permissions": []string{"no:such:permission"}},
+			handler:        testApp.createRoleHandler,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Create role with duplicate name",
+			method:         http.MethodPost,
+			input:          map[string]any{"role": roleName},
+			handler:        testApp.createRoleHandler,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Add permissions to role",
+			method:         http.MethodPost,
+			id:             roleID,
+			input:          map[string]any{"permissions": []string{"workshops:edit", "officers:view"}},
+			handler:        testApp.addRolePermissionsHandler,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Remove permissions from role",
+			method:         http.MethodDelete,
+			id:             roleID,
+			input:          map[string]any{"permissions": []string{"workshops:edit"}},
+			handler:        testApp.removeRolePermissionsHandler,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Rename role",
+			method:         http.MethodPatch,
+			id:             roleID,
+			input:          map[string]any{"role": roleName + " Renamed"},
+			handler:        testApp.updateRoleHandler,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Rename Admin role",
+			method:         http.MethodPatch,
+			id:             adminRoleID,
+			input:          map[string]any{"role": "Administrators"},
+			handler:        testApp.updateRoleHandler,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Remove permissions from Admin role",
+			method:         http.MethodDelete,
+			id:             adminRoleID,
+			input:          map[string]any{"permissions": []string{"roles:edit"}},
+			handler:        testApp.removeRolePermissionsHandler,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Delete Admin role",
+			method:         http.MethodDelete,
+			id:             adminRoleID,
+			handler:        testApp.deleteRoleHandler,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Non-existent role",
+			method:         http.MethodGet,
+			id:             "999999",
+			handler:        testApp.showRoleHandler,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			var body []byte
+			if tt.input != nil {
+				body, _ = json.Marshal(tt.input)
+			}
+
+			req := httptest.NewRequest(tt.method, "/v1/roles/"+tt.id, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.id != "" {
+				req = setURLParam(req, "id", tt.id)
+			}
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+
+	role, err := testApp.models.Role.Get(created.Role.ID)
+	if err != nil {
+		t.Fatalf("Failed to get test role: %v", err)
+	}
+	if !slices.Equal(role.Permissions, data.Permissions{"officers:view", "workshops:view"}) {
+		t.Errorf("Expected permissions [officers:view workshops:view]; got %v", role.Permissions)
+	}
+}
+
+func TestUserRolesHandlers(t *testing.T) {
+	t.Log("=== Testing User Roles Handlers ===")
+
+	_, user, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(user.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	userID := strconv.FormatInt(user.ID, 10)
+
+	tests := []struct {
+		name           string
+		method         string
+		roles          []string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedRoles  data.Roles
+	}{
+		{
+			name:           "Assign role",
+			method:         http.MethodPost,
+			roles:          []string{"Content-Contributor"},
+			handler:        testApp.addUserRolesHandler,
+			expectedStatus: http.StatusOK,
+			expectedRoles:  data.Roles{"Content-Contributor"},
+		},
+		{
+			name:           "Assign role already held",
+			method:         http.MethodPost,
+			roles:          []string{"Content-Contributor"},
+			handler:        testApp.addUserRolesHandler,
+			expectedStatus: http.StatusOK,
+			expectedRoles:  data.Roles{"Content-Contributor"},
+		},
+		{
+			name:           "Assign unknown role",
+			method:         http.MethodPost,
+			roles:          []string{"No Such Role"},
+			handler:        testApp.addUserRolesHandler,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Unassign role",
+			method:         http.MethodDelete,
+			roles:          []string{"Content-Contributor"},
+			handler:        testApp.removeUserRolesHandler,
+			expectedStatus: http.StatusOK,
+			expectedRoles:  data.Roles{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			body, _ := json.Marshal(map[string]any{"roles": tt.roles})
+			req := httptest.NewRequest(tt.method, "/v1/users/"+userID+"/roles", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setURLParam(req, "id", userID)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if tt.expectedRoles == nil {
+				return
+			}
+
+			var response struct {
+				Roles data.Roles `json:"roles"`
+			}
+			_ = json.NewDecoder(res.Body).Decode(&response)
+
+			// Registration gives every user the Officer role, which is left alone here
+			roles := slices.DeleteFunc(response.Roles, func(role string) bool { return role == "Officer" })
+			if !slices.Equal(roles, tt.expectedRoles) {
+				t.Errorf("Expected roles %v; got %v", tt.expectedRoles, roles)
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
@@ -29,17 +29,51 @@ func (app *appDependencies) routes() http.Handler {
 
 	// Authentication and user lifecycle (no permissions required)
 	router.HandlerFunc(http.MethodPost, "/v1/users", app.registerUserHandler)
+	router.HandlerFunc(http.MethodPut, "/v1/invitations/accept", app.acceptInvitationHandler)
 	router.HandlerFunc(http.MethodPut, "/v1/users/activate", app.activateUserHandler)
 	router.HandlerFunc(http.MethodPost, "/v1/tokens/authentication", app.createAuthenticationTokenHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/refresh", app.refreshAuthenticationTokenHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/tokens/two-factor", app.createTwoFactorAuthenticationTokenHandler)
+	router.Handler(http.MethodDelete, "/v1/tokens/authentication", app.requireAuthenticatedUser(http.HandlerFunc(app.deleteAuthenticationTokenHandler)))
 	router.HandlerFunc(http.MethodPost, "/v1/tokens/password-reset", app.createPasswordResetTokenHandler)
 	router.HandlerFunc(http.MethodPut, "/v1/users/password-reset", app.resetPasswordHandler)
+	router.HandlerFunc(http.MethodPut, "/v1/users/email/confirm", app.confirmEmailChangeHandler)
+	router.HandlerFunc(http.MethodPut, "/v1/users/email/cancel", app.cancelEmailChangeHandler)
+	router.Handler(http.MethodPost, "/v1/tokens/calendar", app.requireActivatedUser(http.HandlerFunc(app.createCalendarTokenHandler)))
+	router.Handler(http.MethodDelete, "/v1/tokens/calendar", app.requireActivatedUser(http.HandlerFunc(app.deleteCalendarTokenHandler)))
+
+	// Public certificate verification (no authentication required)
+	router.HandlerFunc(http.MethodGet, "/v1/certificates/verify/:number", app.verifyCertificateHandler)
+	router.HandlerFunc(http.MethodGet, "/v1/certificates/public-key", app.showCertificatePublicKeyHandler)
 
 	// Authenticated user endpoints
 	router.Handler(http.MethodGet, "/v1/me", app.requireActivatedUser(http.HandlerFunc(app.showCurrentUserHandler)))
+	router.Handler(http.MethodPut, "/v1/me/email", app.requireActivatedUser(http.HandlerFunc(app.requestCurrentUserEmailChangeHandler)))
+	router.Handler(http.MethodDelete, "/v1/me/email", app.requireActivatedUser(http.HandlerFunc(app.cancelCurrentUserEmailChangeHandler)))
+	router.Handler(http.MethodGet, "/v1/me/officer", app.requireActivatedUser(http.HandlerFunc(app.showCurrentOfficerHandler)))
+	router.Handler(http.MethodGet, "/v1/me/enrollments", app.requireActivatedUser(http.HandlerFunc(app.listCurrentOfficerEnrollmentsHandler)))
+	router.Handler(http.MethodGet, "/v1/me/upcoming-sessions", app.requireActivatedUser(http.HandlerFunc(app.listCurrentOfficerUpcomingSessionsHandler)))
+	router.Handler(http.MethodGet, "/v1/me/certificates", app.requireActivatedUser(http.HandlerFunc(app.listCurrentOfficerCertificatesHandler)))
+	router.Handler(http.MethodGet, "/v1/me/compliance", app.requireActivatedUser(http.HandlerFunc(app.showCurrentOfficerComplianceHandler)))
+	router.Handler(http.MethodGet, "/v1/me/sessions", app.requireActivatedUser(http.HandlerFunc(app.listCurrentUserSessionsHandler)))
+	router.Handler(http.MethodDelete, "/v1/me/sessions/:id", app.requireActivatedUser(http.HandlerFunc(app.deleteCurrentUserSessionHandler)))
+	router.Handler(http.MethodGet, "/v1/me/2fa", app.requireActivatedUser(http.HandlerFunc(app.showTwoFactorHandler)))
+	router.Handler(http.MethodPost, "/v1/me/2fa", app.requireActivatedUser(http.HandlerFunc(app.setupTwoFactorHandler)))
+	router.Handler(http.MethodDelete, "/v1/me/2fa", app.requireActivatedUser(http.HandlerFunc(app.disableTwoFactorHandler)))
+	router.Handler(http.MethodPost, "/v1/me/2fa/confirm", app.requireActivatedUser(http.HandlerFunc(app.confirmTwoFactorHandler)))
+	router.Handler(http.MethodPost, "/v1/me/2fa/recovery-codes", app.requireActivatedUser(http.HandlerFunc(app.regenerateRecoveryCodesHandler)))
 	router.Handler(http.MethodGet, "/v1/users", app.requirePermissions("users:view")(http.HandlerFunc(app.listUsersHandler)))
 	router.Handler(http.MethodGet, "/v1/users/:id", app.requirePermissions("users:view")(http.HandlerFunc(app.showUserHandler)))
 	router.Handler(http.MethodPatch, "/v1/users/:id", app.requirePermissions("users:edit")(http.HandlerFunc(app.updateUserHandler)))
 	router.Handler(http.MethodDelete, "/v1/users/:id", app.requirePermissions("users:delete")(http.HandlerFunc(app.deleteUserHandler)))
+	router.Handler(http.MethodDelete, "/v1/users/:id/sessions", app.requirePermissions("sessions:revoke")(http.HandlerFunc(app.deleteUserSessionsHandler)))
+	router.Handler(http.MethodPost, "/v1/users/:id/unlock", app.requirePermissions("users:unlock")(http.HandlerFunc(app.unlockUserHandler)))
+
+	// Invitations
+	router.Handler(http.MethodGet, "/v1/invitations", app.requirePermissions("users:invite")(http.HandlerFunc(app.listInvitationsHandler)))
+	router.Handler(http.MethodPost, "/v1/invitations", app.requirePermissions("users:invite")(http.HandlerFunc(app.createInvitationHandler)))
+	router.Handler(http.MethodPost, "/v1/invitations/:id/resend", app.requirePermissions("users:invite")(http.HandlerFunc(app.resendInvitationHandler)))
+	router.Handler(http.MethodDelete, "/v1/invitations/:id", app.requirePermissions("users:invite")(http.HandlerFunc(app.revokeInvitationHandler)))
 
 	// ------------------ Domain-specific routes (standardized) ----------------------
 
@@ -111,21 +145,51 @@ func (app *appDependencies) routes() http.Handler {
 
 	// Officer routes
 	router.Handler(http.MethodPost, "/v1/officers", app.requirePermissions("officers:create")(http.HandlerFunc(app.createOfficerHandler)))
+	router.Handler(http.MethodPost, "/v1/officers/import", app.requirePermissions("officers:create")(http.HandlerFunc(app.importOfficersHandler)))
 	router.Handler(http.MethodGet, "/v1/officers", app.requirePermissions("officers:view")(http.HandlerFunc(app.listOfficersHandler)))
 	router.Handler(http.MethodGet, "/v1/officers/:id", app.requirePermissions("officers:view")(http.HandlerFunc(app.showOfficerHandler)))
 	router.Handler(http.MethodGet, "/v1/officers/:id/details", app.requirePermissions("officers:view")(http.HandlerFunc(app.showOfficerWithDetailsHandler)))
+	router.Handler(http.MethodGet, "/v1/officers/:id/compliance", app.requirePermissions("officers:view")(http.HandlerFunc(app.showOfficerComplianceHandler)))
+	router.Handler(http.MethodGet, "/v1/officers/:id/enrollments", app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.getOfficerEnrollmentsHandler)))
+	router.Handler(http.MethodGet, "/v1/officers/:id/schedule.ics", app.authenticateCalendarFeed(app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.showOfficerCalendarHandler))))
 	router.Handler(http.MethodPatch, "/v1/officers/:id", app.requirePermissions("officers:edit")(http.HandlerFunc(app.updateOfficerHandler)))
 	router.Handler(http.MethodDelete, "/v1/officers/:id", app.requirePermissions("officers:delete")(http.HandlerFunc(app.deleteOfficerHandler)))
 
 	// User-Officer relationship routes
 	router.Handler(http.MethodGet, "/v1/users/:id/officer", app.requirePermissions("officers:view")(http.HandlerFunc(app.getUserOfficerHandler)))
+	router.Handler(http.MethodGet, "/v1/users/:id/facilitation.ics", app.authenticateCalendarFeed(app.requirePermissions("training:sessions:view")(http.HandlerFunc(app.showFacilitatorCalendarHandler))))
+
+	// Role and permission administration routes
+	router.Handler(http.MethodGet, "/v1/roles", app.requirePermissions("roles:view")(http.HandlerFunc(app.listRolesHandler)))
+	router.Handler(http.MethodPost, "/v1/roles", app.requirePermissions("roles:edit")(http.HandlerFunc(app.createRoleHandler)))
+	router.Handler(http.MethodGet, "/v1/roles/:id", app.requirePermissions("roles:view")(http.HandlerFunc(app.showRoleHandler)))
+	router.Handler(http.MethodPatch, "/v1/roles/:id", app.requirePermissions("roles:edit")(http.HandlerFunc(app.updateRoleHandler)))
+	router.Handler(http.MethodDelete, "/v1/roles/:id", app.requirePermissions("roles:edit")(http.HandlerFunc(app.deleteRoleHandler)))
+	router.Handler(http.MethodPost, "/v1/roles/:id/permissions", app.requirePermissions("roles:edit")(http.HandlerFunc(app.addRolePermissionsHandler)))
+	router.Handler(http.MethodDelete, "/v1/roles/:id/permissions", app.requirePermissions("roles:edit")(http.HandlerFunc(app.removeRolePermissionsHandler)))
+	router.Handler(http.MethodGet, "/v1/permissions", app.requirePermissions("roles:view")(http.HandlerFunc(app.listPermissionsHandler)))
+	router.Handler(http.MethodGet, "/v1/users/:id/roles", app.requirePermissions("roles:view")(http.HandlerFunc(app.listUserRolesHandler)))
+	router.Handler(http.MethodPost, "/v1/users/:id/roles", app.requirePermissions("roles:edit")(http.HandlerFunc(app.addUserRolesHandler)))
+	router.Handler(http.MethodDelete, "/v1/users/:id/roles", app.requirePermissions("roles:edit")(http.HandlerFunc(app.removeUserRolesHandler)))
+
+	// Audit routes
+	router.Handler(http.MethodGet, "/v1/audit", app.requirePermissions("audit:view")(http.HandlerFunc(app.listAuditEventsHandler)))
+
+	// Reports routes
+	router.Handler(http.MethodGet, "/v1/reports/compliance", app.requirePermissions("reports:view")(http.HandlerFunc(app.complianceReportHandler)))
 
 	// Training sessions routes
 	router.Handler(http.MethodPost, "/v1/training/sessions", app.requirePermissions("training:sessions:create")(http.HandlerFunc(app.createTrainingSessionHandler)))
 	router.Handler(http.MethodGet, "/v1/training/sessions", app.requirePermissions("training:sessions:view")(http.HandlerFunc(app.listTrainingSessionsHandler)))
+	router.Handler(http.MethodGet, "/v1/training/sessions.ics", app.authenticateCalendarFeed(app.requirePermissions("training:sessions:view")(http.HandlerFunc(app.listTrainingSessionsCalendarHandler))))
+	router.Handler(http.MethodGet, "/v1/training/session-conflicts", app.requirePermissions("training:sessions:edit")(http.HandlerFunc(app.listSessionConflictsHandler)))
 	router.Handler(http.MethodGet, "/v1/training/sessions/:id", app.requirePermissions("training:sessions:view")(http.HandlerFunc(app.showTrainingSessionHandler)))
 	router.Handler(http.MethodPatch, "/v1/training/sessions/:id", app.requirePermissions("training:sessions:edit")(http.HandlerFunc(app.updateTrainingSessionHandler)))
 	router.Handler(http.MethodDelete, "/v1/training/sessions/:id", app.requirePermissions("training:sessions:delete")(http.HandlerFunc(app.deleteTrainingSessionHandler)))
+	router.Handler(http.MethodGet, "/v1/training/sessions/:id/enrollments", app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.getSessionEnrollmentsHandler)))
+	router.Handler(http.MethodGet, "/v1/training/sessions/:id/waitlist", app.requirePermissions("training:sessions:view")(http.HandlerFunc(app.showSessionWaitlistHandler)))
+	router.Handler(http.MethodPut, "/v1/training/sessions/:id/waitlist", app.requirePermissions("training:enrollments:edit")(http.HandlerFunc(app.reorderSessionWaitlistHandler)))
+	router.Handler(http.MethodPost, "/v1/training/sessions/:id/enrollments/bulk", app.requirePermissions("training:enrollments:create")(http.HandlerFunc(app.bulkEnrollSessionHandler)))
 
 	// Training enrollments routes
 	router.Handler(http.MethodPost, "/v1/training/enrollments", app.requirePermissions("training:enrollments:create")(http.HandlerFunc(app.createTrainingEnrollmentHandler)))
@@ -133,6 +197,11 @@ func (app *appDependencies) routes() http.Handler {
 	router.Handler(http.MethodGet, "/v1/training/enrollments/:id", app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.showTrainingEnrollmentHandler)))
 	router.Handler(http.MethodPatch, "/v1/training/enrollments/:id", app.requirePermissions("training:enrollments:edit")(http.HandlerFunc(app.updateTrainingEnrollmentHandler)))
 	router.Handler(http.MethodDelete, "/v1/training/enrollments/:id", app.requirePermissions("training:enrollments:delete")(http.HandlerFunc(app.deleteTrainingEnrollmentHandler)))
+	router.Handler(http.MethodPost, "/v1/training/enrollments/:id/certificate", app.requirePermissions("certificates:issue")(http.HandlerFunc(app.issueCertificateHandler)))
+	router.Handler(http.MethodGet, "/v1/training/enrollments/:id/certificate.pdf", app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.showCertificatePDFHandler)))
+	router.Handler(http.MethodPost, "/v1/training/enrollments/:id/certificate/revoke", app.requirePermissions("certificates:revoke")(http.HandlerFunc(app.revokeCertificateHandler)))
+	router.Handler(http.MethodPost, "/v1/training/enrollments/:id/certificate/reissue", app.requirePermissions("certificates:revoke")(http.HandlerFunc(app.reissueCertificateHandler)))
+	router.Handler(http.MethodGet, "/v1/training/enrollments/:id/certificate/revocations", app.requirePermissions("training:enrollments:view")(http.HandlerFunc(app.listCertificateRevocationsHandler)))
 
-	return app.recoverPanic(app.enableCORS(app.metrics(app.rateLimit(app.authenticate(router)))))
+	return app.recoverPanic(app.requestID(app.enableCORS(app.metrics(app.rateLimit(app.authenticate(router))))))
 }
@@ -0,0 +1,136 @@
+// FileName: cmd/api/sessions.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+)
+
+// maxUserAgentLength bounds the user agent stored with each session
+const maxUserAgentLength = 256
+
+// userAgent returns the request's user agent, cut down to a length worth storing
+func userAgent(r *http.Request) string {
+	agent := r.UserAgent()
+	if len(agent) > maxUserAgentLength {
+		agent = agent[:maxUserAgentLength]
+	}
+	return strings.ToValidUTF8(agent, "")
+}
+
+// listCurrentUserSessionsHandler lists the devices the current user is logged in on
+//
+//	@Summary		List my sessions
+//	@Description	Retrieve the current user's active logins with when and where each was created and last used. The session making the request is marked as current.
+//	@Tags			sessions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/sessions [get]
+func (app *appDependencies) listCurrentUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	sessions, err := app.models.Token.GetAllSessions(user.ID, app.contextGetToken(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"sessions": sessions}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteCurrentUserSessionHandler logs the current user out of one of their sessions
+//
+//	@Summary		Revoke one of my sessions
+//	@Description	Revoke the authentication and refresh tokens of one of the current user's logins, logging that device out
+//	@Tags			sessions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Session ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/sessions/{id} [delete]
+func (app *appDependencies) deleteCurrentUserSessionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	err = app.models.Token.DeleteSession(id, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntitySession, id, data.AuditActionDelete, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "session revoked"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteUserSessionsHandler forces a user to log out everywhere by revoking all of their authentication
+// and refresh tokens. Calendar feed tokens are left alone.
+//
+//	@Summary		Force logout a user
+//	@Description	Revoke every authentication and refresh token held by a user, logging them out on all devices
+//	@Tags			sessions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/users/{id}/sessions [delete]
+func (app *appDependencies) deleteUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	for _, scope := range []string{data.ScopeAuthentication, data.ScopeRefresh} {
+		if err := app.models.Token.DeleteAllForUser(scope, user.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionRevokeSessions, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "user logged out of all sessions"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
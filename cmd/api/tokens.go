@@ -13,12 +13,13 @@ import (
 // createAuthenticationTokenHandler handles the creation of authentication tokens.
 //
 //	@Summary		Create Authentication Token
-//	@Description	Generates an authentication token for a user based on provided email and password.
+//	@Description	Generates an authentication token for a user based on provided email and password. Users with two-factor authentication enabled get a 202 with a two_factor_token to complete at POST /v1/tokens/two-factor.
 //	@Tags			Tokens
 //	@Accept			json
 //	@Produce		json
 //	@Param			input	body		CreateAuthenticationTokenRequest_T	true	"User credentials"
 //	@Success		201		{object}	envelope
+//	@Success		202		{object}	envelope
 //	@Failure		400		{object}	errorResponse
 //	@Failure		422		{object}	errorResponse
 //	@Failure		500		{object}	errorResponse
@@ -60,6 +61,17 @@ func (app *appDependencies) createAuthenticationTokenHandler(w http.ResponseWrit
 		return
 	}
 
+	// Refuse locked accounts before the password is checked, so guesses made during a lock are never tested
+	lockedUntil, err := app.models.User.GetLockedUntil(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if lockedUntil != nil {
+		app.accountLockedResponse(w, r, *lockedUntil)
+		return
+	}
+
 	// Check if the user is activated and isn't trying to bypass the flow
 	if !user.IsActivated {
 		v.AddError("email", "account must be activated to login")
@@ -74,23 +86,185 @@ func (app *appDependencies) createAuthenticationTokenHandler(w http.ResponseWrit
 		return
 	}
 
-	// If the passwords don't match, send an invalid credentials response
+	// If the passwords don't match, count the failure and send an invalid credentials response, or a
+	// locked response if this failure locked the account
 	if !match {
+		lockedUntil, err := app.models.User.RecordFailedLogin(user.ID, app.lockoutPolicy())
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if lockedUntil != nil {
+			app.sendAccountLockedEmail(user, *lockedUntil)
+			app.accountLockedResponse(w, r, *lockedUntil)
+			return
+		}
+
 		app.invalidCredentialsResponse(w, r)
 		return
 	}
 
-	// Generate and return the authentication token
-	token, err := app.models.Token.New(user.ID, 24*time.Hour, data.ScopeAuthentication)
+	// Hashes made with bcrypt or older parameters are upgraded now the plaintext is known
+	app.rehashPassword(user, incomingData.Password)
+
+	// Users with two-factor authentication get a short-lived token to exchange, along with a code, at
+	// POST /v1/tokens/two-factor. Failures are only cleared once the second step succeeds.
+	twoFactorEnabled, err := app.models.TwoFactor.IsEnabled(user.ID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	data := envelope{"authentication_token": token}       // wrap the token in an envelope
-	err = app.writeJSON(w, http.StatusCreated, data, nil) // send a 201 Created response
+	if twoFactorEnabled {
+		_ = app.models.Token.DeleteAllForUser(data.ScopeTwoFactor, user.ID)
+		token, err := app.models.Token.New(user.ID, twoFactorTokenTTL, data.ScopeTwoFactor)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = app.writeJSON(w, http.StatusAccepted, envelope{"two_factor_required": true, "two_factor_token": token}, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.completeLogin(w, r, user)
+}
+
+// completeLogin clears a user's failed logins and issues their authentication and refresh tokens once
+// every factor has been checked
+func (app *appDependencies) completeLogin(w http.ResponseWriter, r *http.Request, user *data.User) {
+	// A successful login starts the count of failures again
+	if err := app.models.User.ClearFailedLogins(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Generate and return the authentication and refresh tokens
+	token, refreshToken, err := app.models.Token.NewAuthentication(user.ID, app.config.tokens.accessTTL, app.config.tokens.refreshTTL, clientIP(r), userAgent(r))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	payload := envelope{"authentication_token": token, "refresh_token": refreshToken}
+
+	// Tell users the policy applies to that they must enroll before their permissions will work
+	if app.config.twoFactor.required {
+		needsEnrollment, err := app.models.TwoFactor.NeedsEnrollment(user.ID, twoFactorPermissions)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if needsEnrollment {
+			payload["two_factor_setup_required"] = true
+		}
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, payload, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// lockoutPolicy returns the configured policy for locking accounts after failed logins
+func (app *appDependencies) lockoutPolicy() data.LockoutPolicy {
+	return data.LockoutPolicy{
+		Threshold:    app.config.lockout.threshold,
+		BaseDuration: app.config.lockout.duration,
+		MaxDuration:  app.config.lockout.maxDuration,
+	}
+}
+
+// sendAccountLockedEmail tells a user their account was locked, in case someone else is guessing their password
+func (app *appDependencies) sendAccountLockedEmail(user *data.User, lockedUntil time.Time) {
+	if app.mailer == nil {
+		return
+	}
+
+	app.background(func() {
+		payload := map[string]any{
+			"firstName":   user.FirstName,
+			"lockedUntil": lockedUntil.UTC().Format("2 January 2006 at 15:04 MST"),
+		}
+		if err := app.mailer.Send(user.Email, "account_locked.tmpl", payload); err != nil {
+			app.logger.Error("failed to send account locked email", "error", err)
+		}
+	})
+}
+
+// refreshAuthenticationTokenHandler exchanges a refresh token for a new authentication and refresh token.
+//
+//	@Summary		Refresh Authentication Token
+//	@Description	Exchanges a refresh token for a new authentication token and a new refresh token. Each refresh token can be used once; replaying one that was already used revokes every token from the same login.
+//	@Tags			Tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		RefreshAuthenticationTokenRequest_T	true	"Refresh token"
+//	@Success		201		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/tokens/refresh [post]
+func (app *appDependencies) refreshAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateTokenPlaintext(v, input.RefreshToken)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	token, refreshToken, err := app.models.Token.Refresh(input.RefreshToken, app.config.tokens.accessTTL, app.config.tokens.refreshTTL, clientIP(r), userAgent(r))
 	if err != nil {
-		app.serverErrorResponse(w, r, err) // handle any errors
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.invalidAuthenticationTokenResponse(w, r)
+		case errors.Is(err, data.ErrTokenReused):
+			app.logger.Warn("refresh token reused, revoking its token family", "ip", clientIP(r))
+			app.invalidAuthenticationTokenResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token, "refresh_token": refreshToken}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteAuthenticationTokenHandler logs the current user out by revoking the token the request was made with.
+//
+//	@Summary		Delete Authentication Token
+//	@Description	Revokes the authentication token used for this request, and the refresh tokens issued with it, logging out this device only.
+//	@Tags			Tokens
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/tokens/authentication [delete]
+func (app *appDependencies) deleteAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.models.Token.DeleteForToken(data.ScopeAuthentication, app.contextGetToken(r)); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "logged out"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
 	}
 }
 
@@ -213,7 +387,16 @@ func (app *appDependencies) resetPasswordHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if err := user.Password.Set(input.Password); err != nil {
+	if err := app.validateNewPassword(v, user.ID, input.Password); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := user.Password.Set(input.Password, app.passwordParams()); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -228,6 +411,14 @@ func (app *appDependencies) resetPasswordHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionPasswordChange, nil, nil)
+
+	// Resetting the password proves the user owns the account, so any lock is lifted
+	if err := app.models.User.ClearFailedLogins(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	if err := app.models.Token.DeleteAllForUser(data.ScopePasswordReset, user.ID); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -238,7 +429,79 @@ func (app *appDependencies) resetPasswordHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if err := app.models.Token.DeleteAllForUser(data.ScopeRefresh, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Token.DeleteAllForUser(data.ScopeCalendar, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "password updated"}, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// createCalendarTokenHandler issues a calendar feed token for the current user.
+//
+//	@Summary		Create Calendar Feed Token
+//	@Description	Generates a long-lived token for subscribing to .ics calendar feeds with ?token=. Any previous calendar token is revoked.
+//	@Tags			Tokens
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/tokens/calendar [post]
+func (app *appDependencies) createCalendarTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	// Only one feed token is kept, so a leaked subscription URL can be replaced
+	if err := app.models.Token.DeleteAllForUser(data.ScopeCalendar, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	token, err := app.models.Token.New(user.ID, 365*24*time.Hour, data.ScopeCalendar)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Calendar tokens are identified by their owner, and the token itself is never recorded
+	app.recordAudit(r, data.AuditEntityCalendarToken, user.ID, data.AuditActionCreate, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusCreated, envelope{"calendar_token": token}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteCalendarTokenHandler revokes the current user's calendar feed token.
+//
+//	@Summary		Revoke Calendar Feed Token
+//	@Description	Revokes the current user's calendar feed token so existing subscriptions stop working.
+//	@Tags			Tokens
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/tokens/calendar [delete]
+func (app *appDependencies) deleteCalendarTokenHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	if err := app.models.Token.DeleteAllForUser(data.ScopeCalendar, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityCalendarToken, user.ID, data.AuditActionDelete, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "calendar token revoked"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
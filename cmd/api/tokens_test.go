@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/totp"
 )
 
 func TestCreateAuthenticationTokenHandler(t *testing.T) {
@@ -437,3 +440,404 @@ func TestTokenWorkflow(t *testing.T) {
 
 	t.Log("Step: Token workflow completed successfully with correct user data")
 }
+
+func TestSessionHandlers(t *testing.T) {
+	t.Log("=== Testing Session Handlers ===")
+
+	_, user, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(user.ID) // Cleanup
+
+	current, _, err := testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.1", "Current Browser")
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+	other, _, err := testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.2", "Other Browser")
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	// Requests go through authenticate so the session making them is known
+	send := func(method, target, token string, handler http.HandlerFunc, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if id != "" {
+			req = setURLParam(req, "id", id)
+		}
+		rec := httptest.NewRecorder()
+		testApp.authenticate(handler).ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Step: Listing sessions")
+	rec := send(http.MethodGet, "/v1/me/sessions", current.Plaintext, testApp.listCurrentUserSessionsHandler, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	var response struct {
+		Sessions []data.Session `json:"sessions"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&response)
+
+	var otherID int64
+	for _, session := range response.Sessions {
+		switch session.UserAgent {
+		case "Current Browser":
+			if !session.Current || session.IPAddress != "192.0.2.1" || session.LastUsedAt == nil {
+				t.Errorf("Expected the current session to be marked current and used; got %+v", session)
+			}
+		case "Other Browser":
+			if session.Current {
+				t.Errorf("Expected the other session not to be marked current")
+			}
+			otherID = session.ID
+		}
+	}
+	if otherID == 0 {
+		t.Fatalf("Expected the other session in %+v", response.Sessions)
+	}
+
+	t.Log("Step: Revoking the other session")
+	id := fmt.Sprint(otherID)
+	rec = send(http.MethodDelete, "/v1/me/sessions/"+id, current.Plaintext, testApp.deleteCurrentUserSessionHandler, id)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+	rec = send(http.MethodGet, "/v1/me/sessions", other.Plaintext, testApp.listCurrentUserSessionsHandler, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked token to be rejected with %d; got %d", http.StatusUnauthorized, rec.Code)
+	}
+	rec = send(http.MethodDelete, "/v1/me/sessions/"+id, current.Plaintext, testApp.deleteCurrentUserSessionHandler, id)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d revoking twice; got %d", http.StatusNotFound, rec.Code)
+	}
+
+	t.Log("Step: Logging out")
+	rec = send(http.MethodDelete, "/v1/tokens/authentication", current.Plaintext, testApp.deleteAuthenticationTokenHandler, "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+	rec = send(http.MethodGet, "/v1/me/sessions", current.Plaintext, testApp.listCurrentUserSessionsHandler, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected logged out token to be rejected with %d; got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	t.Log("Step: Forcing the user to log out")
+	remaining, _, err := testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.3", "Remaining Browser")
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	userID := fmt.Sprint(user.ID)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/users/"+userID+"/sessions", nil)
+	req = setUserContext(setURLParam(req, "id", userID), adminUser)
+	rec = httptest.NewRecorder()
+	testApp.deleteUserSessionsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	if _, err := testApp.models.User.GetForToken(data.ScopeAuthentication, remaining.Plaintext); err == nil {
+		t.Error("Expected every session to be revoked by the force logout")
+	}
+}
+
+func TestRefreshAuthenticationTokenHandler(t *testing.T) {
+	t.Log("=== Testing Refresh Authentication Token Handler ===")
+
+	_, user, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(user.ID) // Cleanup
+
+	access, refresh, err := testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.1", "Test Browser")
+	if err != nil {
+		t.Fatalf("Failed to create tokens: %v", err)
+	}
+
+	// refreshWith posts a refresh token and returns the status and the new refresh token, if any
+	refreshWith := func(refreshToken string) (int, string, string) {
+		body, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+		req := httptest.NewRequest(http.MethodPost, "/v1/tokens/refresh", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		testApp.refreshAuthenticationTokenHandler(rec, req)
+
+		var response struct {
+			AuthenticationToken struct {
+				Token string `json:"token"`
+			} `json:"authentication_token"`
+			RefreshToken struct {
+				Token string `json:"token"`
+			} `json:"refresh_token"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&response)
+		return rec.Code, response.AuthenticationToken.Token, response.RefreshToken.Token
+	}
+
+	isValid := func(token string) bool {
+		_, err := testApp.models.User.GetForToken(data.ScopeAuthentication, token)
+		return err == nil
+	}
+
+	t.Log("Step: Refreshing")
+	status, secondAccess, secondRefresh := refreshWith(refresh.Plaintext)
+	if status != http.StatusCreated || secondAccess == "" || secondRefresh == "" {
+		t.Fatalf("Expected status %d with new tokens; got %d", http.StatusCreated, status)
+	}
+	if isValid(access.Plaintext) {
+		t.Error("Expected the earlier authentication token to be revoked by the refresh")
+	}
+	if !isValid(secondAccess) {
+		t.Error("Expected the new authentication token to be valid")
+	}
+
+	t.Log("Step: Refreshing with the rotated refresh token")
+	status, thirdAccess, _ := refreshWith(secondRefresh)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected status %d; got %d", http.StatusCreated, status)
+	}
+
+	t.Log("Step: Replaying the first refresh token")
+	if status, _, _ := refreshWith(refresh.Plaintext); status != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a reused refresh token; got %d", http.StatusUnauthorized, status)
+	}
+	if isValid(thirdAccess) {
+		t.Error("Expected reuse to revoke every token in the family")
+	}
+
+	t.Log("Step: Refreshing with an unknown token")
+	if status, _, _ := refreshWith("abcdefghijklmnopqrstuv"); status != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for an unknown refresh token; got %d", http.StatusUnauthorized, status)
+	}
+
+	t.Log("Step: Refreshing after the user is deleted")
+	access, refresh, err = testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.1", "Test Browser")
+	if err != nil {
+		t.Fatalf("Failed to create tokens: %v", err)
+	}
+	if err := testApp.models.User.SoftDelete(user.ID); err != nil {
+		t.Fatalf("Failed to delete test user: %v", err)
+	}
+	if isValid(access.Plaintext) {
+		t.Error("Expected deleting the user to revoke their authentication tokens")
+	}
+	if status, _, _ := refreshWith(refresh.Plaintext); status != http.StatusUnauthorized {
+		t.Errorf("Expected status %d for a deleted user's refresh token; got %d", http.StatusUnauthorized, status)
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	t.Log("=== Testing Account Lockout ===")
+
+	_, testUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(testUser.ID) // Cleanup
+
+	// Login needs an activated account; GetByEmail loads the password hash so Update keeps it
+	user, err := testApp.models.User.GetByEmail(testUser.Email)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	user.IsActivated = true
+	if err := testApp.models.User.Update(user); err != nil {
+		t.Fatalf("Failed to activate test user: %v", err)
+	}
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": user.Email, "password": password})
+		req := httptest.NewRequest(http.MethodPost, "/v1/tokens/authentication", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		testApp.createAuthenticationTokenHandler(rec, req)
+		return rec
+	}
+
+	_, refresh, err := testApp.models.Token.NewAuthentication(user.ID, time.Hour, 24*time.Hour, "192.0.2.1", "Test Browser")
+	if err != nil {
+		t.Fatalf("Failed to create tokens: %v", err)
+	}
+
+	threshold := testApp.config.lockout.threshold
+
+	t.Logf("Step: Failing %d logins in a row", threshold)
+	for i := 1; i < threshold; i++ {
+		if rec := login("WrongPassword123!"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status %d on failure %d; got %d", http.StatusUnauthorized, i, rec.Code)
+		}
+	}
+
+	rec := login("WrongPassword123!")
+	if rec.Code != http.StatusLocked {
+		t.Fatalf("Expected status %d once the threshold is reached; got %d", http.StatusLocked, rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header on the locked response")
+	}
+	if _, err := testApp.models.User.GetForToken(data.ScopeRefresh, refresh.Plaintext); err == nil {
+		t.Error("Expected locking the account to revoke its refresh tokens")
+	}
+
+	t.Log("Step: The right password is refused while locked")
+	if rec := login("TestOfficerPass123!"); rec.Code != http.StatusLocked {
+		t.Errorf("Expected status %d while locked; got %d", http.StatusLocked, rec.Code)
+	}
+
+	t.Log("Step: Unlocking as an admin")
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	userID := fmt.Sprint(user.ID)
+	req := httptest.NewRequest(http.MethodPost, "/v1/users/"+userID+"/unlock", nil)
+	req = setUserContext(setURLParam(req, "id", userID), adminUser)
+	rec = httptest.NewRecorder()
+	testApp.unlockUserHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	if rec := login("TestOfficerPass123!"); rec.Code != http.StatusCreated {
+		t.Errorf("Expected status %d after unlocking; got %d", http.StatusCreated, rec.Code)
+	}
+}
+
+func TestTwoFactorAuthentication(t *testing.T) {
+	t.Log("=== Testing Two-Factor Authentication ===")
+
+	_, testUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(testUser.ID) // Cleanup
+
+	// Login needs an activated account; GetByEmail loads the password hash so Update keeps it
+	user, err := testApp.models.User.GetByEmail(testUser.Email)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	user.IsActivated = true
+	if err := testApp.models.User.Update(user); err != nil {
+		t.Fatalf("Failed to activate test user: %v", err)
+	}
+
+	send := func(handler http.HandlerFunc, method, path string, input any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(input)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = setUserContext(req, user)
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	codeAt := func(secret string, step int64) string {
+		code, err := totp.Code(secret, step)
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		return code
+	}
+
+	t.Log("Step: Starting setup")
+	rec := send(testApp.setupTwoFactorHandler, http.MethodPost, "/v1/me/2fa", nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d; got %d", http.StatusCreated, rec.Code)
+	}
+
+	var setup struct {
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioning_uri"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&setup); err != nil {
+		t.Fatalf("Failed to decode setup response: %v", err)
+	}
+	if !strings.HasPrefix(setup.ProvisioningURI, "otpauth://totp/") {
+		t.Errorf("Expected an otpauth provisioning URI; got %q", setup.ProvisioningURI)
+	}
+
+	step := totp.Step(time.Now())
+
+	t.Log("Step: Confirming with a wrong code is refused")
+	wrongCode := fmt.Sprintf("%06d", (mustAtoi(t, codeAt(setup.Secret, step))+1)%1000000)
+	if rec := send(testApp.confirmTwoFactorHandler, http.MethodPost, "/v1/me/2fa/confirm", map[string]string{"code": wrongCode}); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Confirming with the current code")
+	rec = send(testApp.confirmTwoFactorHandler, http.MethodPost, "/v1/me/2fa/confirm", map[string]string{"code": codeAt(setup.Secret, step)})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	var confirm struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&confirm); err != nil {
+		t.Fatalf("Failed to decode confirm response: %v", err)
+	}
+	if len(confirm.RecoveryCodes) == 0 {
+		t.Fatal("Expected recovery codes after confirming")
+	}
+
+	if rec := send(testApp.setupTwoFactorHandler, http.MethodPost, "/v1/me/2fa", nil); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status %d setting up again; got %d", http.StatusConflict, rec.Code)
+	}
+
+	login := func() string {
+		rec := send(testApp.createAuthenticationTokenHandler, http.MethodPost, "/v1/tokens/authentication",
+			map[string]string{"email": user.Email, "password": "TestOfficerPass123!"})
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Expected status %d from the password step; got %d", http.StatusAccepted, rec.Code)
+		}
+
+		var response struct {
+			TwoFactorToken data.Token `json:"two_factor_token"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode login response: %v", err)
+		}
+		return response.TwoFactorToken.Plaintext
+	}
+
+	secondStep := func(token, code string) *httptest.ResponseRecorder {
+		return send(testApp.createTwoFactorAuthenticationTokenHandler, http.MethodPost, "/v1/tokens/two-factor",
+			map[string]string{"two_factor_token": token, "code": code})
+	}
+
+	t.Log("Step: Logging in with the next code")
+	nextCode := codeAt(setup.Secret, step+1)
+	if rec := secondStep(login(), nextCode); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d; got %d", http.StatusCreated, rec.Code)
+	}
+
+	t.Log("Step: Replaying a used code is refused")
+	if rec := secondStep(login(), nextCode); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Logging in with a recovery code, which only works once")
+	token := login()
+	if rec := secondStep(token, confirm.RecoveryCodes[0]); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d; got %d", http.StatusCreated, rec.Code)
+	}
+	if rec := secondStep(token, confirm.RecoveryCodes[0]); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d reusing a spent two-factor token; got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	t.Log("Step: Disabling with a recovery code")
+	if rec := send(testApp.disableTwoFactorHandler, http.MethodDelete, "/v1/me/2fa", map[string]string{"code": confirm.RecoveryCodes[1]}); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	rec = send(testApp.createAuthenticationTokenHandler, http.MethodPost, "/v1/tokens/authentication",
+		map[string]string{"email": user.Email, "password": "TestOfficerPass123!"})
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status %d once disabled; got %d", http.StatusCreated, rec.Code)
+	}
+}
+
+// mustAtoi converts a numeric code for arithmetic in tests
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", s, err)
+	}
+	return n
+}
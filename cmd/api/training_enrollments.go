@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
@@ -12,14 +13,13 @@ import (
 
 func (app *appDependencies) createTrainingEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		OfficerID          int64   `json:"officer_id"`
-		SessionID          int64   `json:"session_id"`
-		EnrollmentStatusID int64   `json:"enrollment_status_id"`
-		AttendanceStatusID *int64  `json:"attendance_status_id"`
-		ProgressStatusID   int64   `json:"progress_status_id"`
-		CompletionDate     *string `json:"completion_date"` // "2025-01-15"
-		CertificateIssued  *bool   `json:"certificate_issued"`
-		CertificateNumber  *string `json:"certificate_number"`
+		OfficerID             int64   `json:"officer_id"`
+		SessionID             int64   `json:"session_id"`
+		EnrollmentStatusID    int64   `json:"enrollment_status_id"`
+		AttendanceStatusID    *int64  `json:"attendance_status_id"`
+		ProgressStatusID      int64   `json:"progress_status_id"`
+		CompletionDate        *string `json:"completion_date"` // "2025-01-15"
+		OverrideScheduleClash bool    `json:"override_schedule_clash"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -34,12 +34,6 @@ func (app *appDependencies) createTrainingEnrollmentHandler(w http.ResponseWrite
 		EnrollmentStatusID: input.EnrollmentStatusID,
 		AttendanceStatusID: input.AttendanceStatusID,
 		ProgressStatusID:   input.ProgressStatusID,
-		CertificateIssued:  false, // default
-		CertificateNumber:  input.CertificateNumber,
-	}
-
-	if input.CertificateIssued != nil {
-		enrollment.CertificateIssued = *input.CertificateIssued
 	}
 
 	// Parse completion date if provided
@@ -54,15 +48,28 @@ func (app *appDependencies) createTrainingEnrollmentHandler(w http.ResponseWrite
 
 	v := validator.New()
 	data.ValidateTrainingEnrollment(v, enrollment)
+	if err := app.checkEnrollmentInScope(v, app.contextGetScope(r), enrollment); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	err = app.models.TrainingEnrollment.Insert(enrollment)
+	if input.OverrideScheduleClash && !app.canOverrideScheduleClash(w, r) {
+		return
+	}
+
+	err = app.models.TrainingEnrollment.Insert(enrollment, input.OverrideScheduleClash)
 	if err != nil {
+		var clash *data.ScheduleClashError
 		switch {
+		case errors.As(err, &clash):
+			app.scheduleClashResponse(w, r, clash)
+		case errors.Is(err, data.ErrSessionFull):
+			app.sessionFullResponse(w, r)
 		case errors.Is(err, data.ErrDuplicateValue):
 			app.badRequestResponse(w, r, errors.New("officer is already enrolled in this session"))
 		case errors.Is(err, data.ErrForeignKeyViolation):
@@ -73,15 +80,46 @@ func (app *appDependencies) createTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, enrollment.ID, data.AuditActionCreate, nil, enrollment)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/training-enrollments/%d", enrollment.ID))
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"training_enrollment": enrollment}, headers)
+	payload := envelope{"training_enrollment": enrollment}
+	if enrollment.WaitlistPosition != nil {
+		payload["message"] = fmt.Sprintf("officer placed on the session waitlist at position %d", *enrollment.WaitlistPosition)
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, payload, headers)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
+// checkEnrollmentInScope records an error for an officer or session outside the regions and formations
+// the user's roles are held in. Users whose scope places no limit are not checked.
+func (app *appDependencies) checkEnrollmentInScope(v *validator.Validator, scope *data.Scope, enrollment *data.TrainingEnrollment) error {
+	if !scope.Limited() {
+		return nil
+	}
+
+	if _, err := app.models.Officer.GetInScope(enrollment.OfficerID, scope); err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			return err
+		}
+		v.AddError("officer_id", "must be an officer in a region or formation you are assigned to")
+	}
+
+	if _, err := app.models.TrainingSession.GetInScope(enrollment.SessionID, scope); err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			return err
+		}
+		v.AddError("session_id", "must be a session in a region or formation you are assigned to")
+	}
+
+	return nil
+}
+
 func (app *appDependencies) showTrainingEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -89,7 +127,7 @@ func (app *appDependencies) showTrainingEnrollmentHandler(w http.ResponseWriter,
 		return
 	}
 
-	enrollment, err := app.models.TrainingEnrollment.Get(id)
+	enrollment, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -123,6 +161,13 @@ func (app *appDependencies) listTrainingEnrollmentsHandler(w http.ResponseWriter
 		return
 	}
 
+	filters.Scope = app.contextGetScope(r)
+
+	if app.wantsCSV(r) {
+		app.writeTrainingEnrollmentsCSV(w, r, officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID, filters)
+		return
+	}
+
 	enrollments, metadata, err := app.models.TrainingEnrollment.GetAll(officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -135,6 +180,34 @@ func (app *appDependencies) listTrainingEnrollmentsHandler(w http.ResponseWriter
 	}
 }
 
+// writeTrainingEnrollmentsCSV exports the enrollments matching the criteria of a list, with the officer,
+// session and statuses spelled out, writing each row as it is read
+func (app *appDependencies) writeTrainingEnrollmentsCSV(w http.ResponseWriter, r *http.Request, officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID *int64, filters data.Filters) {
+	names, err := app.models.Lookup.Names(data.LookupOfficers, data.LookupTrainingSessions, data.LookupEnrollmentStatuses, data.LookupAttendanceStatuses, data.LookupProgressStatuses)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	header := []string{"ID", "Officer", "Session", "Enrollment Status", "Attendance Status", "Progress Status", "Completion Date", "Certificate Issued", "Certificate Number", "Waitlist Position"}
+	app.streamCSV(w, r, "training-enrollments", header, func(write func([]string) error) error {
+		return app.models.TrainingEnrollment.Each(officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID, filters, func(e *data.TrainingEnrollment) error {
+			return write([]string{
+				strconv.FormatInt(e.ID, 10),
+				csvName(names[data.LookupOfficers], e.OfficerID),
+				csvName(names[data.LookupTrainingSessions], e.SessionID),
+				csvName(names[data.LookupEnrollmentStatuses], e.EnrollmentStatusID),
+				csvOptionalName(names[data.LookupAttendanceStatuses], e.AttendanceStatusID),
+				csvName(names[data.LookupProgressStatuses], e.ProgressStatusID),
+				csvOptionalDate(e.CompletionDate),
+				csvBool(e.CertificateIssued),
+				csvString(e.CertificateNumber),
+				csvInt(e.WaitlistPosition),
+			})
+		})
+	})
+}
+
 func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -142,7 +215,7 @@ func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
-	enrollment, err := app.models.TrainingEnrollment.Get(id)
+	enrollment, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -153,15 +226,16 @@ func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	before := *enrollment
+
 	var input struct {
-		OfficerID          *int64  `json:"officer_id"`
-		SessionID          *int64  `json:"session_id"`
-		EnrollmentStatusID *int64  `json:"enrollment_status_id"`
-		AttendanceStatusID *int64  `json:"attendance_status_id"`
-		ProgressStatusID   *int64  `json:"progress_status_id"`
-		CompletionDate     *string `json:"completion_date"`
-		CertificateIssued  *bool   `json:"certificate_issued"`
-		CertificateNumber  *string `json:"certificate_number"`
+		OfficerID             *int64  `json:"officer_id"`
+		SessionID             *int64  `json:"session_id"`
+		EnrollmentStatusID    *int64  `json:"enrollment_status_id"`
+		AttendanceStatusID    *int64  `json:"attendance_status_id"`
+		ProgressStatusID      *int64  `json:"progress_status_id"`
+		CompletionDate        *string `json:"completion_date"`
+		OverrideScheduleClash bool    `json:"override_schedule_clash"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -170,6 +244,8 @@ func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	previousSessionID := enrollment.SessionID
+
 	if input.OfficerID != nil {
 		enrollment.OfficerID = *input.OfficerID
 	}
@@ -197,26 +273,33 @@ func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWrite
 			enrollment.CompletionDate = &completionDate
 		}
 	}
-	if input.CertificateIssued != nil {
-		enrollment.CertificateIssued = *input.CertificateIssued
-	}
-	if input.CertificateNumber != nil {
-		enrollment.CertificateNumber = input.CertificateNumber
-	}
 
 	v := validator.New()
 	data.ValidateTrainingEnrollment(v, enrollment)
+	if err := app.checkEnrollmentInScope(v, app.contextGetScope(r), enrollment); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	err = app.models.TrainingEnrollment.Update(enrollment)
+	if input.OverrideScheduleClash && !app.canOverrideScheduleClash(w, r) {
+		return
+	}
+
+	err = app.models.TrainingEnrollment.Update(enrollment, input.OverrideScheduleClash)
 	if err != nil {
+		var clash *data.ScheduleClashError
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.As(err, &clash):
+			app.scheduleClashResponse(w, r, clash)
+		case errors.Is(err, data.ErrSessionFull):
+			app.sessionFullResponse(w, r)
 		case errors.Is(err, data.ErrDuplicateValue):
 			app.badRequestResponse(w, r, errors.New("officer is already enrolled in this session"))
 		case errors.Is(err, data.ErrForeignKeyViolation):
@@ -227,6 +310,11 @@ func (app *appDependencies) updateTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, enrollment.ID, data.AuditActionUpdate, &before, enrollment)
+
+	// Withdrawing, cancelling or moving the enrollment may have freed a seat
+	app.promoteWaitlist(previousSessionID)
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"training_enrollment": enrollment}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -240,6 +328,17 @@ func (app *appDependencies) deleteTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	enrollment, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.TrainingEnrollment.Delete(id)
 	if err != nil {
 		switch {
@@ -251,13 +350,34 @@ func (app *appDependencies) deleteTrainingEnrollmentHandler(w http.ResponseWrite
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, enrollment.ID, data.AuditActionDelete, enrollment, nil)
+
+	app.promoteWaitlist(enrollment.SessionID)
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"message": "training enrollment successfully deleted"}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
 
-// Specialized handlers
+// getOfficerEnrollmentsHandler lists an officer's enrollments, or exports them all as CSV
+//
+//	@Summary		List an officer's enrollments
+//	@Description	Retrieve the enrollments of an officer within the caller's scope, with pagination, or export every one as CSV
+//	@Tags			officers
+//	@Produce		json,text/csv
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int		true	"Officer ID"
+//	@Param			page		query		int		false	"Page number"
+//	@Param			page_size	query		int		false	"Page size"
+//	@Param			sort		query		string	false	"Sort field (created_at or completion_date, prefix with - for descending)"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
+//	@Success		200			{object}	envelope
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/officers/{id}/enrollments [get]
 func (app *appDependencies) getOfficerEnrollmentsHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -274,6 +394,13 @@ func (app *appDependencies) getOfficerEnrollmentsHandler(w http.ResponseWriter,
 		return
 	}
 
+	filters.Scope = app.contextGetScope(r)
+
+	if app.wantsCSV(r) {
+		app.writeTrainingEnrollmentsCSV(w, r, &id, nil, nil, nil, nil, filters)
+		return
+	}
+
 	enrollments, metadata, err := app.models.TrainingEnrollment.GetByOfficer(id, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -286,6 +413,24 @@ func (app *appDependencies) getOfficerEnrollmentsHandler(w http.ResponseWriter,
 	}
 }
 
+// getSessionEnrollmentsHandler lists a session's enrollments, or exports them all as CSV
+//
+//	@Summary		List a session's enrollments
+//	@Description	Retrieve the enrollments of a training session within the caller's scope, with pagination, or export every one as CSV
+//	@Tags			training-sessions
+//	@Produce		json,text/csv
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int		true	"Training session ID"
+//	@Param			page		query		int		false	"Page number"
+//	@Param			page_size	query		int		false	"Page size"
+//	@Param			sort		query		string	false	"Sort field (created_at or completion_date, prefix with - for descending)"
+//	@Param			format		query		string	false	"Set to csv to export every matching row"
+//	@Success		200			{object}	envelope
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/sessions/{id}/enrollments [get]
 func (app *appDependencies) getSessionEnrollmentsHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -302,6 +447,13 @@ func (app *appDependencies) getSessionEnrollmentsHandler(w http.ResponseWriter,
 		return
 	}
 
+	filters.Scope = app.contextGetScope(r)
+
+	if app.wantsCSV(r) {
+		app.writeTrainingEnrollmentsCSV(w, r, nil, &id, nil, nil, nil, filters)
+		return
+	}
+
 	enrollments, metadata, err := app.models.TrainingEnrollment.GetBySession(id, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -314,6 +466,25 @@ func (app *appDependencies) getSessionEnrollmentsHandler(w http.ResponseWriter,
 	}
 }
 
+// issueCertificateHandler issues a numbered certificate for a completed enrollment
+//
+//	@Summary		Issue a certificate
+//	@Description	Issue the next sequential certificate number for an enrollment that counts as present and is completed, signed when certificate signing is configured. completion_date (YYYY-MM-DD) defaults to today.
+//	@Tags			certificates
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int							true	"Training enrollment ID"
+//	@Param			certificate	body		IssueCertificateRequest_T	false	"Completion date"
+//	@Success		200			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		409			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/enrollments/{id}/certificate [post]
 func (app *appDependencies) issueCertificateHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -322,8 +493,7 @@ func (app *appDependencies) issueCertificateHandler(w http.ResponseWriter, r *ht
 	}
 
 	var input struct {
-		CertificateNumber string `json:"certificate_number"`
-		CompletionDate    string `json:"completion_date"` // "2025-01-15"
+		CompletionDate *string `json:"completion_date"` // "2025-01-15", defaults to today
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -334,13 +504,15 @@ func (app *appDependencies) issueCertificateHandler(w http.ResponseWriter, r *ht
 
 	// Validate inputs
 	v := validator.New()
-	v.Check(input.CertificateNumber != "", "certificate_number", "must be provided")
-	v.Check(len(input.CertificateNumber) <= 100, "certificate_number", "must not exceed 100 characters")
-	v.Check(input.CompletionDate != "", "completion_date", "must be provided")
 
-	completionDate, err := time.Parse("2006-01-02", input.CompletionDate)
-	if err != nil {
-		v.AddError("completion_date", "invalid date format, use YYYY-MM-DD")
+	completionDate := time.Now()
+	if input.CompletionDate != nil {
+		completionDate, err = time.Parse("2006-01-02", *input.CompletionDate)
+		if err != nil {
+			v.AddError("completion_date", "invalid date format, use YYYY-MM-DD")
+		} else {
+			v.Check(!completionDate.After(time.Now()), "completion_date", "must not be in the future")
+		}
 	}
 
 	if !v.IsEmpty() {
@@ -348,19 +520,79 @@ func (app *appDependencies) issueCertificateHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	err = app.models.TrainingEnrollment.IssueCertificate(id, input.CertificateNumber, completionDate)
+	// Signed numbers can be checked for tampering by anyone holding the public key
+	var sign func(string) string
+	if app.signer != nil {
+		sign = app.signer.Sign
+	}
+
+	if _, err := app.models.TrainingEnrollment.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	user := app.contextGetUser(r)
+
+	certificateNumber, err := app.models.TrainingEnrollment.IssueCertificate(id, user.ID, completionDate, sign)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrCertificateAlreadyIssued):
+			app.certificateAlreadyIssuedResponse(w, r)
+		case errors.Is(err, data.ErrCertificateRevoked):
+			app.certificateRevokedResponse(w, r)
+		case errors.Is(err, data.ErrAttendanceNotPresent), errors.Is(err, data.ErrProgressIncomplete):
+			if errors.Is(err, data.ErrAttendanceNotPresent) {
+				v.AddError("attendance_status_id", "must count as present before a certificate can be issued")
+			}
+			if errors.Is(err, data.ErrProgressIncomplete) {
+				v.AddError("progress_status_id", "must be Completed before a certificate can be issued")
+			}
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateValue):
+			app.editConflictResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "certificate issued successfully"}, nil)
+	app.recordAudit(r, data.AuditEntityTrainingEnrollment, id, data.AuditActionIssueCertificate, nil, map[string]any{"certificate_number": certificateNumber})
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "certificate issued successfully", "certificate_number": certificateNumber}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// canOverrideScheduleClash reports whether the user may enroll officers into overlapping sessions,
+// sending a 403 response when they may not.
+func (app *appDependencies) canOverrideScheduleClash(w http.ResponseWriter, r *http.Request) bool {
+	user := app.contextGetUser(r)
+
+	permitted, err := app.models.Role.HasPermission(user.ID, "training:enrollments:override")
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
+		return false
 	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
+
+// scheduleClashResponse reports the overlapping sessions an officer already attends as a failed validation.
+func (app *appDependencies) scheduleClashResponse(w http.ResponseWriter, r *http.Request, clash *data.ScheduleClashError) {
+	v := validator.New()
+	v.AddError("session_id", "officer is already enrolled in overlapping session(s) "+joinIDs(clash.SessionIDs))
+	app.failedValidationResponse(w, r, v.Errors)
 }
@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -128,7 +130,7 @@ func createTestEnrollment(t *testing.T) *data.TrainingEnrollment {
 		testApp.models.TrainingEnrollment.Delete(existing.ID)
 	}
 
-	err = testApp.models.TrainingEnrollment.Insert(enrollment)
+	err = testApp.models.TrainingEnrollment.Insert(enrollment, false)
 	if err != nil {
 		t.Fatalf("Failed to create test enrollment: %v", err)
 	}
@@ -137,6 +139,34 @@ func createTestEnrollment(t *testing.T) *data.TrainingEnrollment {
 	return enrollment
 }
 
+// createCompletedTestEnrollment creates a test enrollment that is eligible for a certificate
+func createCompletedTestEnrollment(t *testing.T) *data.TrainingEnrollment {
+	t.Helper()
+
+	enrollment := createTestEnrollment(t)
+
+	presentStatus, err := testApp.models.AttendanceStatus.GetByName("Present")
+	if err != nil {
+		t.Fatal("Present attendance status not found")
+	}
+	completedStatus, err := testApp.models.ProgressStatus.GetByName("Completed")
+	if err != nil {
+		t.Fatal("Completed progress status not found")
+	}
+
+	completionDate := time.Now()
+	enrollment.AttendanceStatusID = &presentStatus.ID
+	enrollment.ProgressStatusID = completedStatus.ID
+	enrollment.CompletionDate = &completionDate
+
+	if err := testApp.models.TrainingEnrollment.Update(enrollment, false); err != nil {
+		t.Fatalf("Failed to complete test enrollment: %v", err)
+	}
+
+	t.Logf("Step: Completed test enrollment ID %d", enrollment.ID)
+	return enrollment
+}
+
 func TestCreateTrainingEnrollmentHandler(t *testing.T) {
 	t.Log("=== Testing Create Training Enrollment Handler ===")
 
@@ -179,7 +209,6 @@ func TestCreateTrainingEnrollmentHandler(t *testing.T) {
 				"enrollment_status_id": enrollmentStatus.ID,
 				"attendance_status_id": attendanceStatus.ID,
 				"progress_status_id":   progressStatus.ID,
-				"certificate_issued":   false,
 			},
 			expectedStatus: http.StatusCreated,
 			checkResponse: func(t *testing.T, res *http.Response) {
@@ -240,6 +269,21 @@ func TestCreateTrainingEnrollmentHandler(t *testing.T) {
 				t.Log("Step: Validating invalid date format handling")
 			},
 		},
+		{
+			name: "Certificate fields cannot be set directly",
+			input: map[string]any{
+				"officer_id":           officerID,
+				"session_id":           sessionID,
+				"enrollment_status_id": enrollmentStatus.ID,
+				"progress_status_id":   progressStatus.ID,
+				"certificate_issued":   true,
+				"certificate_number":   "CERT-TEST-123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating rejected certificate fields")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -566,8 +610,6 @@ func TestUpdateTrainingEnrollmentHandler(t *testing.T) {
 			input: map[string]any{
 				"progress_status_id": completedStatus.ID,
 				"completion_date":    time.Now().Format("2006-01-02"),
-				"certificate_issued": true,
-				"certificate_number": "CERT-TEST-123",
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, res *http.Response) {
@@ -584,18 +626,31 @@ func TestUpdateTrainingEnrollmentHandler(t *testing.T) {
 				}
 
 				enrollment := response["training_enrollment"].(map[string]any)
-				if enrollment["certificate_issued"] != true {
-					t.Errorf("Expected certificate_issued true, got %v", enrollment["certificate_issued"])
+				if int64(enrollment["progress_status_id"].(float64)) != completedStatus.ID {
+					t.Errorf("Expected progress_status_id %d, got %v", completedStatus.ID, enrollment["progress_status_id"])
+				}
+				if enrollment["certificate_issued"] != false {
+					t.Errorf("Expected certificate_issued false, got %v", enrollment["certificate_issued"])
 				}
-
-				t.Logf("Step: Updated enrollment with certificate %v", enrollment["certificate_number"])
+			},
+		},
+		{
+			name:         "Certificate fields cannot be set directly",
+			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
+			input: map[string]any{
+				"certificate_issued": true,
+				"certificate_number": "CERT-TEST-123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating rejected certificate fields")
 			},
 		},
 		{
 			name:         "Non-existent enrollment",
 			enrollmentID: "999999",
 			input: map[string]any{
-				"certificate_issued": true,
+				"progress_status_id": completedStatus.ID,
 			},
 			expectedStatus: http.StatusNotFound,
 			checkResponse: func(t *testing.T, res *http.Response) {
@@ -736,6 +791,7 @@ func TestGetOfficerEnrollmentsHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		officerID      string
+		query          string
 		expectedStatus int
 		checkResponse  func(*testing.T, *http.Response)
 	}{
@@ -773,6 +829,35 @@ func TestGetOfficerEnrollmentsHandler(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Export officer enrollments as CSV",
+			officerID:      strconv.FormatInt(officerID, 10),
+			query:          "?format=csv&page_size=1",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating CSV export")
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected text/csv content type, got %q", ct)
+				}
+
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) == 0 || records[0][0] != "ID" || records[0][1] != "Officer" {
+					t.Fatalf("Unexpected CSV header: %v", records)
+				}
+				t.Logf("Step: Exported %d enrollments", len(records)-1)
+
+				// Every row is for the same officer
+				for _, record := range records[1:] {
+					if record[1] != records[1][1] {
+						t.Errorf("Expected every row for officer %q, got %q", records[1][1], record[1])
+					}
+				}
+			},
+		},
 		{
 			name:           "Non-existent officer",
 			officerID:      "999999",
@@ -788,7 +873,7 @@ func TestGetOfficerEnrollmentsHandler(t *testing.T) {
 			t.Logf("Starting test: %s", tt.name)
 
 			path := fmt.Sprintf("/v1/officers/%s/enrollments", tt.officerID)
-			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req := httptest.NewRequest(http.MethodGet, path+tt.query, nil)
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
 
 			req = setURLParam(req, "id", tt.officerID)
@@ -829,6 +914,7 @@ func TestGetSessionEnrollmentsHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		sessionID      string
+		query          string
 		expectedStatus int
 		checkResponse  func(*testing.T, *http.Response)
 	}{
@@ -866,6 +952,35 @@ func TestGetSessionEnrollmentsHandler(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Export session enrollments as CSV",
+			sessionID:      strconv.FormatInt(sessionID, 10),
+			query:          "?format=csv&page_size=1",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating CSV export")
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected text/csv content type, got %q", ct)
+				}
+
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) == 0 || records[0][0] != "ID" || records[0][2] != "Session" {
+					t.Fatalf("Unexpected CSV header: %v", records)
+				}
+				t.Logf("Step: Exported %d enrollments", len(records)-1)
+
+				// Every row is for the same session
+				for _, record := range records[1:] {
+					if record[2] != records[1][2] {
+						t.Errorf("Expected every row for session %q, got %q", records[1][2], record[2])
+					}
+				}
+			},
+		},
 		{
 			name:           "Non-existent session",
 			sessionID:      "999999",
@@ -881,7 +996,7 @@ func TestGetSessionEnrollmentsHandler(t *testing.T) {
 			t.Logf("Starting test: %s", tt.name)
 
 			path := fmt.Sprintf("/v1/training-sessions/%s/enrollments", tt.sessionID)
-			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req := httptest.NewRequest(http.MethodGet, path+tt.query, nil)
 			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
 
 			req = setURLParam(req, "id", tt.sessionID)
@@ -908,7 +1023,7 @@ func TestIssueCertificateHandler(t *testing.T) {
 	t.Log("=== Testing Issue Certificate Handler ===")
 
 	// Create test enrollment for certificate issuance
-	testEnrollment := createTestEnrollment(t)
+	testEnrollment := createCompletedTestEnrollment(t)
 	defer testApp.models.TrainingEnrollment.Delete(testEnrollment.ID) // Cleanup
 
 	adminUser := getSeededUser(t, "admin1@police-training.bz")
@@ -921,12 +1036,33 @@ func TestIssueCertificateHandler(t *testing.T) {
 		expectedStatus int
 		checkResponse  func(*testing.T, *http.Response)
 	}{
+		{
+			name:         "Invalid completion date format",
+			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
+			input: map[string]string{
+				"completion_date": "invalid-date",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating invalid date format")
+			},
+		},
+		{
+			name:         "Caller cannot choose the certificate number",
+			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
+			input: map[string]string{
+				"certificate_number": "CERT-CHOSEN-123",
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating rejected certificate number")
+			},
+		},
 		{
 			name:         "Valid certificate issuance",
 			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
 			input: map[string]string{
-				"certificate_number": fmt.Sprintf("CERT-API-TEST-%d", time.Now().UnixNano()),
-				"completion_date":    time.Now().Format("2006-01-02"),
+				"completion_date": time.Now().Format("2006-01-02"),
 			},
 			expectedStatus: http.StatusOK,
 			checkResponse: func(t *testing.T, res *http.Response) {
@@ -941,42 +1077,31 @@ func TestIssueCertificateHandler(t *testing.T) {
 					t.Error("Expected message in response")
 				}
 
-				t.Logf("Step: Certificate issued with message: %v", response["message"])
-			},
-		},
-		{
-			name:         "Non-existent enrollment",
-			enrollmentID: "999999",
-			input: map[string]string{
-				"certificate_number": "CERT-NONEXISTENT-123",
-				"completion_date":    time.Now().Format("2006-01-02"),
-			},
-			expectedStatus: http.StatusNotFound,
-			checkResponse: func(t *testing.T, res *http.Response) {
-				t.Log("Step: Validating non-existent enrollment certificate")
+				number, _ := response["certificate_number"].(string)
+				prefix := fmt.Sprintf("%s-%d-", data.CertificateNumberPrefix, time.Now().Year())
+				if !strings.HasPrefix(number, prefix) {
+					t.Errorf("Expected certificate number starting with %s; got %q", prefix, number)
+				}
+
+				t.Logf("Step: Certificate issued with number: %v", number)
 			},
 		},
 		{
-			name:         "Missing certificate number",
-			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
-			input: map[string]string{
-				"completion_date": time.Now().Format("2006-01-02"),
-			},
-			expectedStatus: http.StatusUnprocessableEntity,
+			name:           "Certificate already issued",
+			enrollmentID:   strconv.FormatInt(testEnrollment.ID, 10),
+			input:          map[string]string{},
+			expectedStatus: http.StatusConflict,
 			checkResponse: func(t *testing.T, res *http.Response) {
-				t.Log("Step: Validating missing certificate number")
+				t.Log("Step: Validating repeated certificate issuance")
 			},
 		},
 		{
-			name:         "Invalid completion date format",
-			enrollmentID: strconv.FormatInt(testEnrollment.ID, 10),
-			input: map[string]string{
-				"certificate_number": "CERT-INVALID-DATE-123",
-				"completion_date":    "invalid-date",
-			},
-			expectedStatus: http.StatusUnprocessableEntity,
+			name:           "Non-existent enrollment",
+			enrollmentID:   "999999",
+			input:          map[string]string{},
+			expectedStatus: http.StatusNotFound,
 			checkResponse: func(t *testing.T, res *http.Response) {
-				t.Log("Step: Validating invalid date format")
+				t.Log("Step: Validating non-existent enrollment certificate")
 			},
 		},
 	}
@@ -1011,6 +1136,42 @@ func TestIssueCertificateHandler(t *testing.T) {
 	}
 }
 
+func TestIssueCertificateIneligible(t *testing.T) {
+	t.Log("=== Testing Certificate Issuance Eligibility ===")
+
+	// The test enrollment is absent and still in progress
+	testEnrollment := createTestEnrollment(t)
+	defer testApp.models.TrainingEnrollment.Delete(testEnrollment.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/v1/training/enrollments/%d/certificate", testEnrollment.ID), bytes.NewReader([]byte("{}")))
+	req.Header.Set("Content-Type", "application/json")
+	req = setURLParam(req, "id", strconv.FormatInt(testEnrollment.ID, 10))
+	req = setUserContext(req, adminUser)
+
+	rec := httptest.NewRecorder()
+	testApp.issueCertificateHandler(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+
+	t.Logf("Step: Received status code %d", res.StatusCode)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d; got %d", http.StatusUnprocessableEntity, res.StatusCode)
+	}
+
+	var response map[string]any
+	_ = json.NewDecoder(res.Body).Decode(&response)
+	errs, _ := response["error"].(map[string]any)
+
+	for _, field := range []string{"attendance_status_id", "progress_status_id"} {
+		if errs[field] == nil {
+			t.Errorf("Expected an eligibility error for %s", field)
+		}
+	}
+}
+
 func TestEnrollmentWorkflow(t *testing.T) {
 	t.Log("=== Testing Complete Enrollment Workflow ===")
 
@@ -1053,7 +1214,6 @@ func TestEnrollmentWorkflow(t *testing.T) {
 		"enrollment_status_id": enrollmentStatus.ID,
 		"attendance_status_id": attendanceStatus.ID,
 		"progress_status_id":   progressStatus.ID,
-		"certificate_issued":   false,
 	}
 
 	body, _ := json.Marshal(enrollmentInput)
@@ -1097,7 +1257,6 @@ func TestEnrollmentWorkflow(t *testing.T) {
 	updateInput := map[string]any{
 		"progress_status_id": completedStatus.ID,
 		"completion_date":    time.Now().Format("2006-01-02"),
-		"certificate_issued": true,
 	}
 
 	body, _ = json.Marshal(updateInput)
@@ -1118,8 +1277,7 @@ func TestEnrollmentWorkflow(t *testing.T) {
 
 	// 4. Issue certificate
 	certInput := map[string]string{
-		"certificate_number": fmt.Sprintf("CERT-WORKFLOW-%d", time.Now().UnixNano()),
-		"completion_date":    time.Now().Format("2006-01-02"),
+		"completion_date": time.Now().Format("2006-01-02"),
 	}
 
 	body, _ = json.Marshal(certInput)
@@ -1185,3 +1343,613 @@ func TestEnrollmentWorkflow(t *testing.T) {
 
 	t.Log("Step: Complete enrollment workflow test passed successfully!")
 }
+
+func TestCreateTrainingEnrollmentCapacity(t *testing.T) {
+	t.Log("=== Testing Enrollment Capacity Enforcement ===")
+
+	officers, _, err := testApp.models.Officer.GetAll("", nil, nil, nil, nil, data.Filters{
+		Page: 1, PageSize: 10, Sort: "id", SortSafelist: []string{"id"},
+	})
+	if err != nil || len(officers) < 2 {
+		t.Skip("Need at least 2 officers for capacity testing")
+	}
+
+	session := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(session.ID) // Cleanup
+
+	session.MaxCapacity = intPtr(1)
+	if err := testApp.models.TrainingSession.Update(session); err != nil {
+		t.Fatalf("Failed to limit session capacity: %v", err)
+	}
+	t.Logf("Step: Limited session ID %d to a single seat", session.ID)
+
+	enrollmentStatus, _ := testApp.models.EnrollmentStatus.GetByName("Enrolled")
+	progressStatus, _ := testApp.models.ProgressStatus.GetByName("In Progress")
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	adminToken := createTokenForSeededUser(t, adminUser.ID)
+
+	// Enrollments are only removed once every case has run so the seat stays taken
+	var created []int64
+	defer func() {
+		for _, id := range created {
+			testApp.models.TrainingEnrollment.Delete(id)
+		}
+	}()
+
+	tests := []struct {
+		name             string
+		officerID        int64
+		expectedStatus   int
+		expectedWaitlist bool
+	}{
+		{
+			name:             "First officer takes the last seat",
+			officerID:        officers[0].ID,
+			expectedStatus:   http.StatusCreated,
+			expectedWaitlist: false,
+		},
+		{
+			name:             "Second officer is waitlisted",
+			officerID:        officers[1].ID,
+			expectedStatus:   http.StatusCreated,
+			expectedWaitlist: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			input := map[string]any{
+				"officer_id":           tt.officerID,
+				"session_id":           session.ID,
+				"enrollment_status_id": enrollmentStatus.ID,
+				"progress_status_id":   progressStatus.ID,
+			}
+
+			body, _ := json.Marshal(input)
+			req := httptest.NewRequest(http.MethodPost, "/v1/training/enrollments", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", adminToken))
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.createTrainingEnrollmentHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if res.StatusCode == http.StatusCreated {
+				var response map[string]any
+				_ = json.NewDecoder(res.Body).Decode(&response)
+				enrollment := response["training_enrollment"].(map[string]any)
+				created = append(created, int64(enrollment["id"].(float64)))
+
+				_, waitlisted := enrollment["waitlist_position"]
+				t.Logf("Step: Enrollment waitlisted: %v", waitlisted)
+				if waitlisted != tt.expectedWaitlist {
+					t.Errorf("Expected waitlisted %v; got %v", tt.expectedWaitlist, waitlisted)
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+
+	if len(created) != 2 {
+		return
+	}
+
+	t.Log("Step: Deleting the seated enrollment to free the seat")
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/v1/training/enrollments/%d", created[0]), nil)
+	req = setURLParam(req, "id", fmt.Sprintf("%d", created[0]))
+	req = setUserContext(req, adminUser)
+
+	rec := httptest.NewRecorder()
+	testApp.deleteTrainingEnrollmentHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	promoted, err := testApp.models.TrainingEnrollment.Get(created[1])
+	if err != nil {
+		t.Fatalf("Failed to reload waitlisted enrollment: %v", err)
+	}
+
+	t.Logf("Step: Waitlisted enrollment now has status ID %d", promoted.EnrollmentStatusID)
+	if promoted.EnrollmentStatusID != enrollmentStatus.ID || promoted.WaitlistPosition != nil {
+		t.Errorf("Expected enrollment %d to be promoted off the waitlist", promoted.ID)
+	}
+}
+
+func TestCreateTrainingEnrollmentScheduleClash(t *testing.T) {
+	t.Log("=== Testing Enrollment Schedule Clash Detection ===")
+
+	seededOfficer, _ := getSeededOfficer(t)
+
+	first := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(first.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	// A second session on the same day that overlaps the first, run by another facilitator elsewhere
+	second := *first
+	second.ID = 0
+	second.FacilitatorID = adminUser.ID
+	second.StartTime = time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	second.EndTime = time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)
+	second.Location = stringPtr("Clash Test Room")
+	if err := testApp.models.TrainingSession.Insert(&second); err != nil {
+		t.Fatalf("Failed to create overlapping session: %v", err)
+	}
+	defer testApp.models.TrainingSession.Delete(second.ID) // Cleanup
+	t.Logf("Step: Created overlapping sessions %d and %d", first.ID, second.ID)
+
+	enrollmentStatus, _ := testApp.models.EnrollmentStatus.GetByName("Enrolled")
+	progressStatus, _ := testApp.models.ProgressStatus.GetByName("In Progress")
+
+	existing := &data.TrainingEnrollment{
+		OfficerID:          seededOfficer.ID,
+		SessionID:          first.ID,
+		EnrollmentStatusID: enrollmentStatus.ID,
+		ProgressStatusID:   progressStatus.ID,
+	}
+	if err := testApp.models.TrainingEnrollment.Insert(existing, false); err != nil {
+		t.Fatalf("Failed to enroll officer in first session: %v", err)
+	}
+	t.Logf("Step: Enrolled officer %d in session %d", seededOfficer.ID, first.ID)
+
+	tests := []struct {
+		name           string
+		override       bool
+		expectedStatus int
+	}{
+		{
+			name:           "Overlapping enrollment is refused",
+			override:       false,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Admin overrides the clash",
+			override:       true,
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			input := map[string]any{
+				"officer_id":              seededOfficer.ID,
+				"session_id":              second.ID,
+				"enrollment_status_id":    enrollmentStatus.ID,
+				"progress_status_id":      progressStatus.ID,
+				"override_schedule_clash": tt.override,
+			}
+
+			body, _ := json.Marshal(input)
+			req := httptest.NewRequest(http.MethodPost, "/v1/training/enrollments", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.createTrainingEnrollmentHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestWaitlistPromotionSkipsScheduleClash(t *testing.T) {
+	t.Log("=== Testing Waitlist Promotion With Schedule Clashes ===")
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	// A single-seat session and another that overlaps it
+	full := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(full.ID) // Cleanup
+	full.MaxCapacity = intPtr(1)
+	if err := testApp.models.TrainingSession.Update(full); err != nil {
+		t.Fatalf("Failed to limit session capacity: %v", err)
+	}
+
+	overlapping := *full
+	overlapping.ID = 0
+	overlapping.FacilitatorID = adminUser.ID
+	overlapping.MaxCapacity = nil
+	overlapping.StartTime = time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	overlapping.EndTime = time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)
+	overlapping.Location = stringPtr("Promotion Clash Room")
+	if err := testApp.models.TrainingSession.Insert(&overlapping); err != nil {
+		t.Fatalf("Failed to create overlapping session: %v", err)
+	}
+	defer testApp.models.TrainingSession.Delete(overlapping.ID) // Cleanup
+	t.Logf("Step: Created overlapping sessions %d and %d", full.ID, overlapping.ID)
+
+	enrollmentStatus, _ := testApp.models.EnrollmentStatus.GetByName("Enrolled")
+	progressStatus, _ := testApp.models.ProgressStatus.GetByName("In Progress")
+
+	enroll := func(officerID, sessionID int64) *data.TrainingEnrollment {
+		t.Helper()
+		enrollment := &data.TrainingEnrollment{
+			OfficerID:          officerID,
+			SessionID:          sessionID,
+			EnrollmentStatusID: enrollmentStatus.ID,
+			ProgressStatusID:   progressStatus.ID,
+		}
+		// The clash is allowed here so the busy officer can reach the waitlist at all
+		if err := testApp.models.TrainingEnrollment.Insert(enrollment, true); err != nil {
+			t.Fatalf("Failed to enroll officer %d in session %d: %v", officerID, sessionID, err)
+		}
+		return enrollment
+	}
+
+	seated, seatedUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(seatedUser.ID) // Cleanup
+	busy, busyUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(busyUser.ID) // Cleanup
+	free, freeUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(freeUser.ID) // Cleanup
+
+	seat := enroll(seated.ID, full.ID)
+	defer testApp.models.TrainingEnrollment.Delete(seat.ID) // Cleanup if the delete below fails
+	busySeat := enroll(busy.ID, overlapping.ID)
+	defer testApp.models.TrainingEnrollment.Delete(busySeat.ID) // Cleanup
+	busyWait := enroll(busy.ID, full.ID)
+	defer testApp.models.TrainingEnrollment.Delete(busyWait.ID) // Cleanup
+	freeWait := enroll(free.ID, full.ID)
+	defer testApp.models.TrainingEnrollment.Delete(freeWait.ID) // Cleanup
+
+	if busyWait.WaitlistPosition == nil || freeWait.WaitlistPosition == nil {
+		t.Fatal("Expected both later officers to be waitlisted")
+	}
+	t.Logf("Step: Officer %d is first on the waitlist but seated in session %d", busy.ID, overlapping.ID)
+
+	t.Log("Step: Deleting the seated enrollment to free the seat")
+	id := strconv.FormatInt(seat.ID, 10)
+	req := setURLParam(httptest.NewRequest(http.MethodDelete, "/v1/training/enrollments/"+id, nil), "id", id)
+	req = setUserContext(req, adminUser)
+
+	rec := httptest.NewRecorder()
+	testApp.deleteTrainingEnrollmentHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	tests := []struct {
+		name             string
+		enrollmentID     int64
+		expectedWaitlist bool
+	}{
+		{name: "Officer with an overlapping seat stays waitlisted", enrollmentID: busyWait.ID, expectedWaitlist: true},
+		{name: "Next officer in line is promoted", enrollmentID: freeWait.ID, expectedWaitlist: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enrollment, err := testApp.models.TrainingEnrollment.Get(tt.enrollmentID)
+			if err != nil {
+				t.Fatalf("Failed to reload enrollment: %v", err)
+			}
+
+			waitlisted := enrollment.WaitlistPosition != nil
+			t.Logf("Step: Enrollment %d waitlisted: %v", enrollment.ID, waitlisted)
+			if waitlisted != tt.expectedWaitlist {
+				t.Errorf("Expected waitlisted %v; got %v", tt.expectedWaitlist, waitlisted)
+			}
+			if !waitlisted && enrollment.EnrollmentStatusID != enrollmentStatus.ID {
+				t.Errorf("Expected promoted enrollment to be Enrolled; got status %d", enrollment.EnrollmentStatusID)
+			}
+		})
+	}
+}
+
+func TestBulkEnrollSessionHandler(t *testing.T) {
+	t.Log("=== Testing Bulk Enroll Session Handler ===")
+
+	officers, _, err := testApp.models.Officer.GetAll("", nil, nil, nil, nil, data.Filters{
+		Page: 1, PageSize: 10, Sort: "id", SortSafelist: []string{"id"},
+	})
+	if err != nil || len(officers) < 2 {
+		t.Skip("Need at least 2 officers for bulk enrollment testing")
+	}
+
+	session := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(session.ID) // Cleanup
+
+	session.MaxCapacity = intPtr(1)
+	if err := testApp.models.TrainingSession.Update(session); err != nil {
+		t.Fatalf("Failed to limit session capacity: %v", err)
+	}
+	t.Logf("Step: Limited session ID %d to a single seat", session.ID)
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	sessionID := strconv.FormatInt(session.ID, 10)
+
+	tooMany := make([]int64, data.MaxBulkEnrollment+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+
+	// Enrollments are only removed once every case has run so later cases see them
+	created := map[int64]bool{}
+	defer func() {
+		for id := range created {
+			testApp.models.TrainingEnrollment.Delete(id)
+		}
+	}()
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		input          map[string]any
+		body           string
+		expectedStatus int
+		expected       []string
+	}{
+		{
+			name:      "Officers fill the seat, then the waitlist",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids": []int64{officers[0].ID, officers[1].ID, officers[0].ID, 999999999},
+			},
+			expectedStatus: http.StatusOK,
+			expected:       []string{data.BulkEnrollmentEnrolled, data.BulkEnrollmentWaitlisted, data.BulkEnrollmentRejected},
+		},
+		{
+			name:      "Repeating the request reports existing enrollments",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids": []int64{officers[0].ID, officers[1].ID},
+			},
+			expectedStatus: http.StatusOK,
+			expected:       []string{data.BulkEnrollmentAlreadyEnrolled, data.BulkEnrollmentAlreadyEnrolled},
+		},
+		{
+			name:           "No officers or selector",
+			sessionID:      sessionID,
+			input:          map[string]any{},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Officer IDs combined with a selector",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids":  []int64{officers[0].ID},
+				"formation_id": officers[0].FormationID,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Non-existent session",
+			sessionID: "999999",
+			input: map[string]any{
+				"officer_ids": []int64{officers[0].ID},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:      "Non-existent enrollment and progress statuses",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids":          []int64{officers[0].ID},
+				"enrollment_status_id": 999999,
+				"progress_status_id":   999999,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Non-positive officer ID",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids": []int64{officers[0].ID, 0},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Too many officer IDs",
+			sessionID: sessionID,
+			input: map[string]any{
+				"officer_ids": tooMany,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "Selector matching no officers",
+			sessionID: sessionID,
+			input: map[string]any{
+				"rank_id": 999999,
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Malformed JSON",
+			sessionID:      sessionID,
+			body:           `{"officer_ids": [1,`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Invalid session ID",
+			sessionID: "invalid",
+			input: map[string]any{
+				"officer_ids": []int64{officers[0].ID},
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			body := []byte(tt.body)
+			if tt.body == "" {
+				body, _ = json.Marshal(tt.input)
+			}
+			path := fmt.Sprintf("/v1/training/sessions/%s/enrollments/bulk", tt.sessionID)
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setURLParam(req, "id", tt.sessionID)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.bulkEnrollSessionHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Fatalf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			if tt.expected == nil {
+				return
+			}
+
+			var response struct {
+				Results []data.BulkEnrollmentResult `json:"results"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(response.Results) != len(tt.expected) {
+				t.Fatalf("Expected %d results; got %d", len(tt.expected), len(response.Results))
+			}
+
+			for i, result := range response.Results {
+				if result.EnrollmentID != nil {
+					created[*result.EnrollmentID] = true
+				}
+				if result.Status != tt.expected[i] {
+					t.Errorf("Expected officer %d to be %s; got %s (%s)", result.OfficerID, tt.expected[i], result.Status, result.Reason)
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
+
+func TestBulkEnrollSessionScheduleClash(t *testing.T) {
+	t.Log("=== Testing Bulk Enrollment Schedule Clash Detection ===")
+
+	seededOfficer, _ := getSeededOfficer(t)
+
+	first := createTestSession(t)
+	defer testApp.models.TrainingSession.Delete(first.ID) // Cleanup
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	// A second session on the same day that overlaps the first
+	second := *first
+	second.ID = 0
+	second.FacilitatorID = adminUser.ID
+	second.StartTime = time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)
+	second.EndTime = time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)
+	second.Location = stringPtr("Bulk Clash Test Room")
+	if err := testApp.models.TrainingSession.Insert(&second); err != nil {
+		t.Fatalf("Failed to create overlapping session: %v", err)
+	}
+	defer testApp.models.TrainingSession.Delete(second.ID) // Cleanup
+	t.Logf("Step: Created overlapping sessions %d and %d", first.ID, second.ID)
+
+	enrollmentStatus, _ := testApp.models.EnrollmentStatus.GetByName("Enrolled")
+	progressStatus, _ := testApp.models.ProgressStatus.GetByName("In Progress")
+
+	existing := &data.TrainingEnrollment{
+		OfficerID:          seededOfficer.ID,
+		SessionID:          first.ID,
+		EnrollmentStatusID: enrollmentStatus.ID,
+		ProgressStatusID:   progressStatus.ID,
+	}
+	if err := testApp.models.TrainingEnrollment.Insert(existing, false); err != nil {
+		t.Fatalf("Failed to enroll officer in first session: %v", err)
+	}
+	t.Logf("Step: Enrolled officer %d in session %d", seededOfficer.ID, first.ID)
+
+	sessionID := strconv.FormatInt(second.ID, 10)
+
+	tests := []struct {
+		name     string
+		override bool
+		expected string
+	}{
+		{
+			name:     "Double booked officer is rejected",
+			override: false,
+			expected: data.BulkEnrollmentRejected,
+		},
+		{
+			name:     "Admin overrides the clash",
+			override: true,
+			expected: data.BulkEnrollmentEnrolled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			input := map[string]any{
+				"officer_ids":             []int64{seededOfficer.ID},
+				"override_schedule_clash": tt.override,
+			}
+
+			body, _ := json.Marshal(input)
+			path := fmt.Sprintf("/v1/training/sessions/%s/enrollments/bulk", sessionID)
+			req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setURLParam(req, "id", sessionID)
+			req = setUserContext(req, adminUser)
+
+			rec := httptest.NewRecorder()
+			testApp.bulkEnrollSessionHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status %d; got %d", http.StatusOK, res.StatusCode)
+			}
+
+			var response struct {
+				Results []data.BulkEnrollmentResult `json:"results"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if len(response.Results) != 1 {
+				t.Fatalf("Expected 1 result; got %d", len(response.Results))
+			}
+
+			result := response.Results[0]
+			if result.EnrollmentID != nil {
+				defer testApp.models.TrainingEnrollment.Delete(*result.EnrollmentID) // Cleanup
+			}
+			if result.Status != tt.expected {
+				t.Errorf("Expected officer %d to be %s; got %s (%s)", result.OfficerID, tt.expected, result.Status, result.Reason)
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+}
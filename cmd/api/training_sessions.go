@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
@@ -66,6 +67,7 @@ func (app *appDependencies) createTrainingSessionHandler(w http.ResponseWriter,
 
 	v := validator.New()
 	data.ValidateTrainingSession(v, session)
+	checkInScope(v, app.contextGetScope(r), session.RegionID, session.FormationID)
 
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -74,7 +76,10 @@ func (app *appDependencies) createTrainingSessionHandler(w http.ResponseWriter,
 
 	err = app.models.TrainingSession.Insert(session)
 	if err != nil {
+		var conflict *data.ScheduleConflictError
 		switch {
+		case errors.As(err, &conflict):
+			app.scheduleConflictResponse(w, r, conflict)
 		case errors.Is(err, data.ErrDuplicateValue):
 			app.badRequestResponse(w, r, errors.New("a training session with these details already exists"))
 		case errors.Is(err, data.ErrForeignKeyViolation):
@@ -85,6 +90,8 @@ func (app *appDependencies) createTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingSession, session.ID, data.AuditActionCreate, nil, session)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/training-sessions/%d", session.ID))
 
@@ -101,7 +108,7 @@ func (app *appDependencies) showTrainingSessionHandler(w http.ResponseWriter, r
 		return
 	}
 
-	session, err := app.models.TrainingSession.Get(id)
+	session, err := app.models.TrainingSession.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -146,6 +153,13 @@ func (app *appDependencies) listTrainingSessionsHandler(w http.ResponseWriter, r
 		return
 	}
 
+	filters.Scope = app.contextGetScope(r)
+
+	if app.wantsCSV(r) {
+		app.writeTrainingSessionsCSV(w, r, facilitatorID, workshopID, formationID, regionID, statusID, sessionDate, filters)
+		return
+	}
+
 	sessions, metadata, err := app.models.TrainingSession.GetAll(facilitatorID, workshopID, formationID, regionID, statusID, sessionDate, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -158,6 +172,106 @@ func (app *appDependencies) listTrainingSessionsHandler(w http.ResponseWriter, r
 	}
 }
 
+// writeTrainingSessionsCSV streams every session matching the filters, with the workshop, facilitator,
+// formation, region and status spelled out
+func (app *appDependencies) writeTrainingSessionsCSV(w http.ResponseWriter, r *http.Request, facilitatorID, workshopID, formationID, regionID, statusID *int64, sessionDate *time.Time, filters data.Filters) {
+	names, err := app.models.Lookup.Names(data.LookupWorkshops, data.LookupUsers, data.LookupFormations, data.LookupRegions, data.LookupTrainingStatuses)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	header := []string{"ID", "Workshop", "Facilitator", "Formation", "Region", "Date", "Start Time", "End Time", "Location", "Max Capacity", "Status", "Notes"}
+	app.streamCSV(w, r, "training-sessions", header, func(write func([]string) error) error {
+		return app.models.TrainingSession.Each(facilitatorID, workshopID, formationID, regionID, statusID, sessionDate, filters, func(s *data.TrainingSession) error {
+			return write([]string{
+				strconv.FormatInt(s.ID, 10),
+				csvName(names[data.LookupWorkshops], s.WorkshopID),
+				csvName(names[data.LookupUsers], s.FacilitatorID),
+				csvName(names[data.LookupFormations], s.FormationID),
+				csvName(names[data.LookupRegions], s.RegionID),
+				csvDate(s.SessionDate),
+				csvClock(s.StartTime),
+				csvClock(s.EndTime),
+				csvString(s.Location),
+				csvInt(s.MaxCapacity),
+				csvName(names[data.LookupTrainingStatuses], s.TrainingStatusID),
+				csvString(s.Notes),
+			})
+		})
+	})
+}
+
+// listSessionConflictsHandler audits existing sessions for facilitator double-bookings and venue clashes.
+//
+//	@Summary		Audit session conflicts
+//	@Description	List pairs of overlapping sessions that share a facilitator or a location, limited to the caller's regions and formations
+//	@Tags			training-sessions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			type		query		string	false	"facilitator or location (default both)"
+//	@Param			from		query		string	false	"Earliest session date (YYYY-MM-DD)"
+//	@Param			to			query		string	false	"Latest session date (YYYY-MM-DD)"
+//	@Param			page		query		int		false	"Page number"
+//	@Param			page_size	query		int		false	"Page size"
+//	@Param			sort		query		string	false	"Sort field"
+//	@Success		200			{object}	envelope
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/session-conflicts [get]
+func (app *appDependencies) listSessionConflictsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	v := validator.New()
+
+	filters := app.readFilters(query, "session_date", 20, []string{"session_date", "-session_date", "session_id", "-session_id", "type", "-type"}, v)
+
+	conflictType := app.getSingleQueryParameter(query, "type", "")
+	if conflictType != "" {
+		v.Check(v.Permitted(conflictType, data.SessionConflictFacilitator, data.SessionConflictLocation), "type", "must be facilitator or location")
+	}
+
+	from := app.getOptionalDateQueryParameter(query, "from", v)
+	to := app.getOptionalDateQueryParameter(query, "to", v)
+
+	if from != nil && to != nil {
+		v.Check(!to.Before(*from), "to", "must not be before from")
+	}
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	filters.Scope = app.contextGetScope(r)
+
+	conflicts, metadata, err := app.models.TrainingSession.GetConflicts(conflictType, from, to, filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"conflicts": conflicts, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// scheduleConflictResponse reports the sessions a new or updated session overlaps as a failed validation.
+func (app *appDependencies) scheduleConflictResponse(w http.ResponseWriter, r *http.Request, conflict *data.ScheduleConflictError) {
+	v := validator.New()
+
+	if len(conflict.FacilitatorSessionIDs) > 0 {
+		v.AddError("facilitator_id", "is already scheduled for overlapping session(s) "+joinIDs(conflict.FacilitatorSessionIDs))
+	}
+	if len(conflict.LocationSessionIDs) > 0 {
+		v.AddError("location", "is already booked for overlapping session(s) "+joinIDs(conflict.LocationSessionIDs))
+	}
+
+	app.failedValidationResponse(w, r, v.Errors)
+}
+
 func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -165,7 +279,7 @@ func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
-	session, err := app.models.TrainingSession.Get(id)
+	session, err := app.models.TrainingSession.GetInScope(id, app.contextGetScope(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
@@ -176,6 +290,8 @@ func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
+	before := *session
+
 	var input struct {
 		FacilitatorID    *int64  `json:"facilitator_id"`
 		WorkshopID       *int64  `json:"workshop_id"`
@@ -247,6 +363,7 @@ func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter,
 
 	v := validator.New()
 	data.ValidateTrainingSession(v, session)
+	checkInScope(v, app.contextGetScope(r), session.RegionID, session.FormationID)
 
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -255,9 +372,12 @@ func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter,
 
 	err = app.models.TrainingSession.Update(session)
 	if err != nil {
+		var conflict *data.ScheduleConflictError
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.As(err, &conflict):
+			app.scheduleConflictResponse(w, r, conflict)
 		case errors.Is(err, data.ErrDuplicateValue):
 			app.badRequestResponse(w, r, errors.New("a training session with these details already exists"))
 		case errors.Is(err, data.ErrForeignKeyViolation):
@@ -268,6 +388,13 @@ func (app *appDependencies) updateTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingSession, session.ID, data.AuditActionUpdate, &before, session)
+
+	// Raising max_capacity frees seats for waitlisted officers
+	if input.MaxCapacity != nil {
+		app.promoteWaitlist(session.ID)
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"training_session": session}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -281,6 +408,17 @@ func (app *appDependencies) deleteTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
+	session, err := app.models.TrainingSession.GetInScope(id, app.contextGetScope(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.TrainingSession.Delete(id)
 	if err != nil {
 		switch {
@@ -292,6 +430,8 @@ func (app *appDependencies) deleteTrainingSessionHandler(w http.ResponseWriter,
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityTrainingSession, session.ID, data.AuditActionDelete, session, nil)
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"message": "training session successfully deleted"}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -466,6 +468,61 @@ func TestListTrainingSessionsHandler(t *testing.T) {
 				t.Logf("Step: Retrieved %d sessions for today", len(sessions))
 			},
 		},
+		{
+			name:           "Export sessions as CSV",
+			queryParams:    fmt.Sprintf("?format=csv&page_size=1&facilitator_id=%d", facilitator.ID),
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating CSV export")
+				if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+					t.Fatalf("Expected text/csv content type, got %q", ct)
+				}
+
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) == 0 || records[0][0] != "ID" || records[0][2] != "Facilitator" {
+					t.Fatalf("Unexpected CSV header: %v", records)
+				}
+
+				facilitatorName := facilitator.FirstName + " " + facilitator.LastName
+				for _, record := range records[1:] {
+					if record[2] != facilitatorName {
+						t.Errorf("Expected facilitator %q for session %s, got %q", facilitatorName, record[0], record[2])
+					}
+				}
+				t.Logf("Step: Exported %d sessions for facilitator %d", len(records)-1, facilitator.ID)
+			},
+		},
+		{
+			name:           "Export with no matching sessions",
+			queryParams:    "?format=csv&facilitator_id=999999",
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating empty CSV export")
+				records, err := csv.NewReader(res.Body).ReadAll()
+				if err != nil {
+					t.Fatalf("Failed to parse CSV: %v", err)
+				}
+
+				if len(records) != 1 || records[0][0] != "ID" {
+					t.Errorf("Expected only the header row, got %v", records)
+				}
+			},
+		},
+		{
+			name:           "Export with an invalid filter",
+			queryParams:    "?format=csv&session_date=yesterday",
+			expectedStatus: http.StatusUnprocessableEntity,
+			checkResponse: func(t *testing.T, res *http.Response) {
+				t.Log("Step: Validating export error response")
+				if ct := res.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/csv") {
+					t.Errorf("Expected a JSON error rather than a CSV file, got %q", ct)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -781,3 +838,129 @@ func TestSessionWorkflow(t *testing.T) {
 
 	t.Log("Step: Complete session workflow test passed successfully!")
 }
+
+func TestTrainingSessionScheduleConflicts(t *testing.T) {
+	t.Log("=== Testing Training Session Schedule Conflicts ===")
+
+	facilitatorID, workshopID, formationID, regionID, statusID := getSeededSessionData(t)
+	facilitator := getSeededUser(t, "maria.rodriguez@police-training.bz")
+	otherFacilitator := getSeededUser(t, "admin1@police-training.bz")
+	sessionDate := time.Now().AddDate(0, 0, 75)
+
+	existing := &data.TrainingSession{
+		FacilitatorID:    facilitatorID,
+		WorkshopID:       workshopID,
+		FormationID:      formationID,
+		RegionID:         regionID,
+		TrainingStatusID: statusID,
+		SessionDate:      sessionDate,
+		StartTime:        time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:          time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC),
+		Location:         stringPtr("Conflict Test Room"),
+	}
+	if err := testApp.models.TrainingSession.Insert(existing); err != nil {
+		t.Fatalf("Failed to create existing session: %v", err)
+	}
+	defer testApp.models.TrainingSession.Delete(existing.ID) // Cleanup
+	t.Logf("Step: Created existing session ID %d", existing.ID)
+
+	tests := []struct {
+		name           string
+		facilitatorID  int64
+		startTime      string
+		endTime        string
+		location       string
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:           "Facilitator double-booked",
+			facilitatorID:  facilitatorID,
+			startTime:      "11:00",
+			endTime:        "13:00",
+			location:       "Another Room",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedField:  "facilitator_id",
+		},
+		{
+			name:           "Venue already booked",
+			facilitatorID:  otherFacilitator.ID,
+			startTime:      "11:00",
+			endTime:        "13:00",
+			location:       "  conflict test room ",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedField:  "location",
+		},
+		{
+			name:           "Back-to-back session is allowed",
+			facilitatorID:  facilitatorID,
+			startTime:      "12:00",
+			endTime:        "14:00",
+			location:       "Conflict Test Room",
+			expectedStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Logf("Starting test: %s", tt.name)
+
+			input := map[string]any{
+				"facilitator_id":     tt.facilitatorID,
+				"workshop_id":        workshopID,
+				"formation_id":       formationID,
+				"region_id":          regionID,
+				"training_status_id": statusID,
+				"session_date":       sessionDate.Format("2006-01-02"),
+				"start_time":         tt.startTime,
+				"end_time":           tt.endTime,
+				"location":           tt.location,
+			}
+
+			body, _ := json.Marshal(input)
+			req := httptest.NewRequest(http.MethodPost, "/v1/training/sessions", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req = setUserContext(req, facilitator)
+
+			rec := httptest.NewRecorder()
+			testApp.createTrainingSessionHandler(rec, req)
+
+			res := rec.Result()
+			defer res.Body.Close()
+
+			t.Logf("Step: Received status code %d", res.StatusCode)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d", tt.expectedStatus, res.StatusCode)
+			}
+
+			var response map[string]any
+			_ = json.NewDecoder(res.Body).Decode(&response)
+
+			if res.StatusCode == http.StatusCreated {
+				session := response["training_session"].(map[string]any)
+				testApp.models.TrainingSession.Delete(int64(session["id"].(float64))) // Cleanup
+			}
+
+			if tt.expectedField != "" {
+				errs, _ := response["error"].(map[string]any)
+				t.Logf("Step: Validation errors %v", errs)
+				if errs[tt.expectedField] == nil {
+					t.Errorf("Expected a conflict error for %s", tt.expectedField)
+				}
+			}
+
+			t.Logf("Completed test: %s", tt.name)
+		})
+	}
+
+	t.Log("Step: Auditing existing conflicts")
+	req := httptest.NewRequest(http.MethodGet, "/v1/training/session-conflicts?type=facilitator", nil)
+	req = setUserContext(req, facilitator)
+
+	rec := httptest.NewRecorder()
+	testApp.listSessionConflictsHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+}
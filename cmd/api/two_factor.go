@@ -0,0 +1,384 @@
+// FileName: cmd/api/two_factor.go
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/totp"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// twoFactorPermissions are the permissions whose holders must enable two-factor authentication when the
+// -two-factor-required policy is on
+var twoFactorPermissions = []string{"users:edit", "officers:delete"}
+
+// twoFactorTokenTTL is how long a user has to enter their code after giving their password
+const twoFactorTokenTTL = 5 * time.Minute
+
+// checkTwoFactorCode accepts either a current TOTP code or an unused recovery code for a user
+func (app *appDependencies) checkTwoFactorCode(userID int64, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return app.models.TwoFactor.Verify(userID, code)
+	}
+	return app.models.TwoFactor.UseRecoveryCode(userID, code)
+}
+
+// readTwoFactorCode reads and validates the code in a request body, sending the error response itself
+// when it cannot
+func (app *appDependencies) readTwoFactorCode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var input struct {
+		Code string `json:"code"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return "", false
+	}
+
+	v := validator.New()
+	data.ValidateTwoFactorCode(v, input.Code)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return "", false
+	}
+
+	return input.Code, true
+}
+
+// invalidTwoFactorCodeResponse rejects a TOTP or recovery code that did not match
+func (app *appDependencies) invalidTwoFactorCodeResponse(w http.ResponseWriter, r *http.Request) {
+	app.failedValidationResponse(w, r, map[string]string{"code": "invalid or already used code"})
+}
+
+// showTwoFactorHandler reports whether the current user has two-factor authentication enabled
+//
+//	@Summary		Show my two-factor status
+//	@Description	Report whether two-factor authentication is enabled and how many recovery codes are left
+//	@Tags			two-factor
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/2fa [get]
+func (app *appDependencies) showTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	status, err := app.models.TwoFactor.GetStatus(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"two_factor": status}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// setupTwoFactorHandler starts two-factor enrollment, returning a new secret and the provisioning URI to
+// show as a QR code. Enrollment takes effect once confirmed with a code.
+//
+//	@Summary		Start two-factor setup
+//	@Description	Generate a TOTP secret and otpauth:// provisioning URI for an authenticator app. Replaces any setup that was not confirmed.
+//	@Tags			two-factor
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/2fa [post]
+func (app *appDependencies) setupTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	twoFactor, err := app.models.TwoFactor.Setup(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrTwoFactorEnabled):
+			app.twoFactorEnabledResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionSetupTwoFactor, nil, nil)
+
+	payload := envelope{
+		"secret":           twoFactor.Secret,
+		"provisioning_uri": totp.ProvisioningURI(app.config.twoFactor.issuer, user.Email, twoFactor.Secret),
+	}
+
+	if err := app.writeJSON(w, http.StatusCreated, payload, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// confirmTwoFactorHandler enables two-factor authentication once the user proves their authenticator app
+// produces the right codes, and returns their recovery codes
+//
+//	@Summary		Confirm two-factor setup
+//	@Description	Enable two-factor authentication with a code from the authenticator app. The response holds single-use recovery codes, which are not shown again.
+//	@Tags			two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/2fa/confirm [post]
+func (app *appDependencies) confirmTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	code, ok := app.readTwoFactorCode(w, r)
+	if !ok {
+		return
+	}
+
+	twoFactor, err := app.models.TwoFactor.Get(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.failedValidationResponse(w, r, map[string]string{"code": "start two-factor setup first"})
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if twoFactor.ConfirmedAt != nil {
+		app.twoFactorEnabledResponse(w, r)
+		return
+	}
+
+	match, err := app.models.TwoFactor.Verify(user.ID, code)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !match {
+		app.invalidTwoFactorCodeResponse(w, r)
+		return
+	}
+
+	recoveryCodes, err := app.models.TwoFactor.Confirm(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.twoFactorEnabledResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionEnableTwoFactor, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"recovery_codes": recoveryCodes}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// regenerateRecoveryCodesHandler replaces the current user's recovery codes
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace every recovery code with a new set, given a current code from the authenticator app or an unused recovery code
+//	@Tags			two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/2fa/recovery-codes [post]
+func (app *appDependencies) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	user, code, ok := app.readEnabledTwoFactor(w, r)
+	if !ok {
+		return
+	}
+
+	match, err := app.checkTwoFactorCode(user.ID, code)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !match {
+		app.invalidTwoFactorCodeResponse(w, r)
+		return
+	}
+
+	recoveryCodes, err := app.models.TwoFactor.RegenerateRecoveryCodes(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionRegenerateRecovery, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"recovery_codes": recoveryCodes}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// disableTwoFactorHandler turns two-factor authentication off for the current user
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Turn two-factor authentication off, given a current code from the authenticator app or an unused recovery code
+//	@Tags			two-factor
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		422	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/me/2fa [delete]
+func (app *appDependencies) disableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user, code, ok := app.readEnabledTwoFactor(w, r)
+	if !ok {
+		return
+	}
+
+	match, err := app.checkTwoFactorCode(user.ID, code)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !match {
+		app.invalidTwoFactorCodeResponse(w, r)
+		return
+	}
+
+	if err := app.models.TwoFactor.Disable(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionDisableTwoFactor, nil, nil)
+
+	if err := app.writeJSON(w, http.StatusOK, envelope{"message": "two-factor authentication disabled"}, nil); err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readEnabledTwoFactor reads the code in a request from a user who has two-factor authentication enabled,
+// sending the error response itself when it cannot
+func (app *appDependencies) readEnabledTwoFactor(w http.ResponseWriter, r *http.Request) (*data.User, string, bool) {
+	user := app.contextGetUser(r)
+
+	code, ok := app.readTwoFactorCode(w, r)
+	if !ok {
+		return nil, "", false
+	}
+
+	enabled, err := app.models.TwoFactor.IsEnabled(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, "", false
+	}
+	if !enabled {
+		app.notFoundResponse(w, r)
+		return nil, "", false
+	}
+
+	return user, code, true
+}
+
+// createTwoFactorAuthenticationTokenHandler completes a two-factor login, exchanging the token from the
+// password step and a code for authentication and refresh tokens.
+//
+//	@Summary		Complete Two-Factor Login
+//	@Description	Exchanges the two_factor_token returned by POST /v1/tokens/authentication and a code from the authenticator app, or an unused recovery code, for authentication and refresh tokens. Wrong codes count towards account lockout.
+//	@Tags			Tokens
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body		CreateTwoFactorAuthenticationTokenRequest_T	true	"Two-factor token and code"
+//	@Success		201		{object}	envelope
+//	@Failure		400		{object}	errorResponse
+//	@Failure		401		{object}	errorResponse
+//	@Failure		422		{object}	errorResponse
+//	@Failure		423		{object}	errorResponse
+//	@Failure		500		{object}	errorResponse
+//	@Router			/v1/tokens/two-factor [post]
+func (app *appDependencies) createTwoFactorAuthenticationTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		TwoFactorToken string `json:"two_factor_token"`
+		Code           string `json:"code"`
+	}
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	data.ValidateTokenPlaintext(v, input.TwoFactorToken)
+	data.ValidateTwoFactorCode(v, input.Code)
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	user, err := app.models.User.GetForToken(data.ScopeTwoFactor, input.TwoFactorToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.invalidAuthenticationTokenResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	lockedUntil, err := app.models.User.GetLockedUntil(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if lockedUntil != nil {
+		app.accountLockedResponse(w, r, *lockedUntil)
+		return
+	}
+
+	match, err := app.checkTwoFactorCode(user.ID, input.Code)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Wrong codes count as failed logins, so the password alone is not enough to guess the code
+	if !match {
+		lockedUntil, err := app.models.User.RecordFailedLogin(user.ID, app.lockoutPolicy())
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if lockedUntil != nil {
+			_ = app.models.Token.DeleteAllForUser(data.ScopeTwoFactor, user.ID)
+			app.sendAccountLockedEmail(user, *lockedUntil)
+			app.accountLockedResponse(w, r, *lockedUntil)
+			return
+		}
+
+		app.invalidTwoFactorCodeResponse(w, r)
+		return
+	}
+
+	if err := app.models.Token.DeleteAllForUser(data.ScopeTwoFactor, user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.completeLogin(w, r, user)
+}
@@ -145,6 +145,45 @@ type registerUserRequest struct {
 	Facilitator bool   `json:"facilitator"`
 }
 
+// CreateInvitationRequest_T represents the request payload for inviting a user
+type CreateInvitationRequest_T struct {
+	FirstName     string                      `json:"first_name"`
+	LastName      string                      `json:"last_name"`
+	Email         string                      `json:"email"`
+	Gender        string                      `json:"gender"`
+	IsFacilitator bool                        `json:"is_facilitator"`
+	Roles         []string                    `json:"roles"`
+	RegionID      *int64                      `json:"region_id"`
+	FormationID   *int64                      `json:"formation_id"`
+	Officer       *InvitationOfficerRequest_T `json:"officer"`
+}
+
+// InvitationOfficerRequest_T represents the officer record created for an invited user
+type InvitationOfficerRequest_T struct {
+	RegulationNumber string `json:"regulation_number"`
+	RankID           int64  `json:"rank_id"`
+	PostingID        int64  `json:"posting_id"`
+	FormationID      int64  `json:"formation_id"`
+	RegionID         int64  `json:"region_id"`
+}
+
+// AcceptInvitationRequest_T represents the request payload for accepting an invitation
+type AcceptInvitationRequest_T struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ChangeEmailRequest_T represents the request payload for changing your own email address
+type ChangeEmailRequest_T struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// EmailChangeTokenRequest_T represents the request payload for confirming or cancelling an email change
+type EmailChangeTokenRequest_T struct {
+	Token string `json:"token"`
+}
+
 // activateUserRequest represents the request payload for user activation
 type activateUserRequest struct {
 	Token string `json:"token"`
@@ -166,6 +205,17 @@ type CreateAuthenticationTokenRequest_T struct {
 	Password string `json:"password"`
 }
 
+// RefreshAuthenticationTokenRequest_T represents the request payload for refreshing an authentication token
+type RefreshAuthenticationTokenRequest_T struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CreateTwoFactorAuthenticationTokenRequest_T represents the request payload for completing a two-factor login
+type CreateTwoFactorAuthenticationTokenRequest_T struct {
+	TwoFactorToken string `json:"two_factor_token"`
+	Code           string `json:"code"`
+}
+
 // CreateTrainingSessionRequest_T represents the request payload for creating a training session
 type CreateTrainingSessionRequest_T struct {
 	FormationID      int64     `json:"formation_id"`
@@ -238,3 +288,31 @@ type CreateProgressStatusRequest_T struct {
 type UpdateProgressStatusRequest_T struct {
 	Status *string `json:"status,omitempty"`
 }
+
+// ReorderWaitlistRequest_T represents the request payload for reordering a session waitlist
+type ReorderWaitlistRequest_T struct {
+	EnrollmentIDs []int64 `json:"enrollment_ids"`
+}
+
+// CertificateRevocationRequest_T represents the request payload for revoking or reissuing a certificate
+type CertificateRevocationRequest_T struct {
+	Reason string `json:"reason"`
+}
+
+// BulkEnrollSessionRequest_T represents the request payload for enrolling many officers into a session.
+// Either officer_ids or at least one of the selector fields is given, never both.
+type BulkEnrollSessionRequest_T struct {
+	OfficerIDs            []int64 `json:"officer_ids,omitempty"`
+	FormationID           *int64  `json:"formation_id,omitempty"`
+	PostingID             *int64  `json:"posting_id,omitempty"`
+	RankID                *int64  `json:"rank_id,omitempty"`
+	RegionID              *int64  `json:"region_id,omitempty"`
+	EnrollmentStatusID    int64   `json:"enrollment_status_id,omitempty"`
+	ProgressStatusID      int64   `json:"progress_status_id,omitempty"`
+	OverrideScheduleClash bool    `json:"override_schedule_clash,omitempty"`
+}
+
+// IssueCertificateRequest_T represents the request payload for issuing a certificate
+type IssueCertificateRequest_T struct {
+	CompletionDate *string `json:"completion_date,omitempty"`
+}
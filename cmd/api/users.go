@@ -4,26 +4,35 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
 )
 
-// registerUserHandler creates a new user account and sends an activation email.
+// registerUserHandler creates a new user account and sends an activation email. Users normally join by
+// invitation; this is only open when -open-registration is set, and only for the allowed email domains.
 //
 //	@Summary		Register a new user
-//	@Description	Create a new user account and send an activation email
+//	@Description	Create a new user account and send an activation email. Only available when open registration is enabled, for emails at the allowed domains.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		registerUserRequest	true	"User registration data"
 //	@Success		201		{object}	envelope
 //	@Failure		400		{object}	errorResponse
+//	@Failure		403		{object}	errorResponse
 //	@Failure		422		{object}	errorResponse
 //	@Failure		500		{object}	errorResponse
 //	@Router			/v1/users [post]
 func (app *appDependencies) registerUserHandler(w http.ResponseWriter, r *http.Request) {
+	if !app.config.registration.open {
+		app.registrationClosedResponse(w, r)
+		return
+	}
+
 	var input struct {
 		FirstName     string `json:"first_name"`
 		LastName      string `json:"last_name"`
@@ -49,13 +58,20 @@ func (app *appDependencies) registerUserHandler(w http.ResponseWriter, r *http.R
 		IsOfficer:     input.IsOfficer,
 	}
 
-	if err := user.Password.Set(input.Password); err != nil {
+	if err := user.Password.Set(input.Password, app.passwordParams()); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	v := validator.New()
 	data.ValidateUser(v, user)
+	if _, ok := v.Errors["email"]; !ok {
+		v.Check(app.registrationAllowed(user.Email), "email", "must be an address at "+strings.Join(app.config.registration.domains, ", "))
+	}
+	if err := app.validateNewPassword(v, 0, input.Password); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -80,6 +96,8 @@ func (app *appDependencies) registerUserHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionCreate, nil, user)
+
 	// Always clear existing activation tokens and send a new one.
 	_ = app.models.Token.DeleteAllForUser(data.ScopeActivation, user.ID)
 	activationToken, err := app.models.Token.New(user.ID, 72*time.Hour, data.ScopeActivation)
@@ -95,7 +113,6 @@ func (app *appDependencies) registerUserHandler(w http.ResponseWriter, r *http.R
 				"firstName":       user.FirstName,
 				"lastName":        user.LastName,
 				"email":           user.Email,
-				"password":        input.Password,
 				"activationToken": activationToken.Plaintext,
 			}
 			if err := app.mailer.Send(user.Email, "user_welcome.tmpl", data); err != nil {
@@ -154,6 +171,7 @@ func (app *appDependencies) activateUserHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	before := *user
 	user.IsActivated = true
 
 	if err := app.models.User.Update(user); err != nil {
@@ -166,6 +184,8 @@ func (app *appDependencies) activateUserHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionActivate, &before, user)
+
 	if err := app.models.Token.DeleteAllForUser(data.ScopeActivation, user.ID); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -222,8 +242,9 @@ func (app *appDependencies) showUserHandler(w http.ResponseWriter, r *http.Reque
 //	@Summary		List users
 //	@Description	Retrieve a list of users with optional filters and pagination
 //	@Tags			users
-//	@Produce		json
+//	@Produce		json,text/csv
 //	@Security		ApiKeyAuth
+//	@Param			format	query		string	false	"Set to csv to export every matching row"
 //	@Success		200	{object}	envelope
 //	@Failure		500	{object}	errorResponse
 //	@Router			/v1/users [get]
@@ -253,14 +274,17 @@ func (app *appDependencies) listUsersHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	users, metadata, err := app.models.User.GetAll(
-		app.getSingleQueryParameter(query, "first_name", ""),
-		app.getSingleQueryParameter(query, "last_name", ""),
-		app.getSingleQueryParameter(query, "email", ""),
-		app.getSingleQueryParameter(query, "gender", ""),
-		isActivated, isFacilitator, isOfficer, isDeleted,
-		filters,
-	)
+	firstName := app.getSingleQueryParameter(query, "first_name", "")
+	lastName := app.getSingleQueryParameter(query, "last_name", "")
+	email := app.getSingleQueryParameter(query, "email", "")
+	gender := app.getSingleQueryParameter(query, "gender", "")
+
+	if app.wantsCSV(r) {
+		app.writeUsersCSV(w, r, firstName, lastName, email, gender, isActivated, isFacilitator, isOfficer, isDeleted, filters)
+		return
+	}
+
+	users, metadata, err := app.models.User.GetAll(firstName, lastName, email, gender, isActivated, isFacilitator, isOfficer, isDeleted, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -276,10 +300,31 @@ func (app *appDependencies) listUsersHandler(w http.ResponseWriter, r *http.Requ
 	}
 }
 
+// writeUsersCSV streams every user matching the filters, leaving out credentials
+func (app *appDependencies) writeUsersCSV(w http.ResponseWriter, r *http.Request, firstName, lastName, email, gender string, isActivated, isFacilitator, isOfficer, isDeleted *bool, filters data.Filters) {
+	header := []string{"ID", "First Name", "Last Name", "Email", "Gender", "Activated", "Facilitator", "Officer", "Deleted", "Created At"}
+	app.streamCSV(w, r, "users", header, func(write func([]string) error) error {
+		return app.models.User.Each(firstName, lastName, email, gender, isActivated, isFacilitator, isOfficer, isDeleted, filters, func(u *data.User) error {
+			return write([]string{
+				strconv.FormatInt(u.ID, 10),
+				u.FirstName,
+				u.LastName,
+				u.Email,
+				u.Gender,
+				csvBool(u.IsActivated),
+				csvBool(u.IsFacilitator),
+				csvBool(u.IsOfficer),
+				csvBool(u.IsDeleted),
+				csvTimestamp(u.CreatedAt),
+			})
+		})
+	})
+}
+
 // updateUserHandler performs a partial update on a user record.
 //
 //	@Summary		Update a user
-//	@Description	Perform a partial update on a user record
+//	@Description	Perform a partial update on a user record. A new email is not applied straight away: the new address is sent a token to confirm it and the user keeps their current email until then.
 //	@Tags			users
 //	@Produce		json
 //	@Security		ApiKeyAuth
@@ -329,20 +374,19 @@ func (app *appDependencies) updateUserHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	before := *user
+
 	if input.FirstName != nil {
 		user.FirstName = *input.FirstName
 	}
 	if input.LastName != nil {
 		user.LastName = *input.LastName
 	}
-	if input.Email != nil {
-		user.Email = *input.Email
-	}
 	if input.Gender != nil {
 		user.Gender = *input.Gender
 	}
 	if input.Password != nil {
-		if err := user.Password.Set(*input.Password); err != nil {
+		if err := user.Password.Set(*input.Password, app.passwordParams()); err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
@@ -357,8 +401,23 @@ func (app *appDependencies) updateUserHandler(w http.ResponseWriter, r *http.Req
 		user.IsOfficer = *input.IsOfficer
 	}
 
+	// A new address must be confirmed before it replaces the one password resets are sent to
+	changeEmail := input.Email != nil && *input.Email != user.Email
+
 	v := validator.New()
 	data.ValidateUser(v, user)
+	if changeEmail {
+		if err := app.validateNewEmail(v, user, *input.Email); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	if input.Password != nil {
+		if err := app.validateNewPassword(v, user.ID, *input.Password); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -369,8 +428,23 @@ func (app *appDependencies) updateUserHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	action := data.AuditActionUpdate
+	if input.Password != nil {
+		action = data.AuditActionPasswordChange
+	}
+	app.recordAudit(r, data.AuditEntityUser, user.ID, action, &before, user)
+
 	payload := envelope{"user": user}
 
+	if changeEmail {
+		change, err := app.requestEmailChange(r, user, *input.Email)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		payload["email_change"] = change
+	}
+
 	if err := app.writeJSON(w, http.StatusOK, payload, nil); err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -404,13 +478,17 @@ func (app *appDependencies) updatePasswordHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err := user.Password.Set(input.Password); err != nil {
+	if err := user.Password.Set(input.Password, app.passwordParams()); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	v := validator.New()
 	data.ValidateUser(v, user)
+	if err := app.validateNewPassword(v, user.ID, input.Password); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 	if !v.IsEmpty() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -426,6 +504,8 @@ func (app *appDependencies) updatePasswordHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionPasswordChange, nil, nil)
+
 	payload := envelope{"user": user}
 
 	if err := app.writeJSON(w, http.StatusOK, payload, nil); err != nil {
@@ -452,17 +532,34 @@ func (app *appDependencies) deleteUserHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.User.SoftDelete(id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrLastAdmin):
+			app.lastAdminResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	after := *user
+	after.IsDeleted = true
+	app.recordAudit(r, data.AuditEntityUser, id, data.AuditActionDelete, user, &after)
+
 	app.writeJSON(w, http.StatusOK, envelope{"message": "user successfully deactivated"}, nil)
 }
 
@@ -474,6 +571,17 @@ func (app *appDependencies) restoreUserHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.User.Restore(id)
 	if err != nil {
 		switch {
@@ -485,9 +593,55 @@ func (app *appDependencies) restoreUserHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	after := *user
+	after.IsDeleted = false
+	app.recordAudit(r, data.AuditEntityUser, id, data.AuditActionRestore, user, &after)
+
 	app.writeJSON(w, http.StatusOK, envelope{"message": "user successfully restored"}, nil)
 }
 
+// unlockUserHandler lifts a lock placed on a user's account after failed logins and forgets the failures.
+//
+//	@Summary		Unlock a user
+//	@Description	Lift the lock placed on an account after too many failed logins in a row and reset the failure count, so the user can log in again straight away
+//	@Tags			users
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/users/{id}/unlock [post]
+func (app *appDependencies) unlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.models.User.ClearFailedLogins(user.ID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityUser, user.ID, data.AuditActionUnlock, nil, nil)
+
+	app.writeJSON(w, http.StatusOK, envelope{"message": "user successfully unlocked"}, nil)
+}
+
 // Permanently delete a user record.
 func (app *appDependencies) hardDeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
@@ -496,16 +650,31 @@ func (app *appDependencies) hardDeleteUserHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	user, err := app.models.User.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.User.HardDelete(id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrLastAdmin):
+			app.lastAdminResponse(w, r)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityUser, id, data.AuditActionDelete, user, nil)
+
 	app.writeJSON(w, http.StatusOK, envelope{"message": "user successfully deleted"}, nil)
 }
@@ -2,13 +2,16 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha1"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -41,7 +44,21 @@ func TestMain(m *testing.M) {
 		config: serverConfig{
 			env: "testing",
 		},
+		calendarLocation: time.UTC,
 	}
+	testApp.config.tokens.accessTTL = 15 * time.Minute
+	testApp.config.tokens.refreshTTL = 30 * 24 * time.Hour
+	testApp.config.lockout.threshold = 5
+	testApp.config.lockout.duration = time.Minute
+	testApp.config.lockout.maxDuration = 24 * time.Hour
+	testApp.config.twoFactor.issuer = "Police Training"
+	testApp.config.password.memory = int(data.DefaultPasswordParams.Memory)
+	testApp.config.password.iterations = int(data.DefaultPasswordParams.Iterations)
+	testApp.config.password.parallelism = int(data.DefaultPasswordParams.Parallelism)
+	testApp.config.password.minLength = 8
+	testApp.config.password.history = 0     // seeded passwords are set to the same values on every run
+	testApp.config.registration.open = true // the handler tests register their own users
+	testApp.config.registration.domains = []string{"police-training.bz", "test-police-training.bz"}
 
 	code := m.Run()
 	db.Close()
@@ -643,6 +660,99 @@ func TestUpdatePasswordHandler(t *testing.T) {
 	}
 }
 
+func TestPasswordPolicy(t *testing.T) {
+	t.Log("=== Testing Password Policy ===")
+
+	_, testUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(testUser.ID) // Cleanup
+
+	// Login needs an activated account; GetByEmail loads the password hash so Update keeps it
+	user, err := testApp.models.User.GetByEmail(testUser.Email)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	user.IsActivated = true
+	if err := testApp.models.User.Update(user); err != nil {
+		t.Fatalf("Failed to activate test user: %v", err)
+	}
+
+	breachFile := filepath.Join(t.TempDir(), "breached.txt")
+	breached := "Breached123!\n" + fmt.Sprintf("%X:42\n", sha1.Sum([]byte("Pwned12345678!")))
+	if err := os.WriteFile(breachFile, []byte(breached), 0o600); err != nil {
+		t.Fatalf("Failed to write breach list: %v", err)
+	}
+	breachList, err := data.LoadBreachList(breachFile)
+	if err != nil {
+		t.Fatalf("Failed to load breach list: %v", err)
+	}
+
+	config, breachedPasswords := testApp.config, testApp.breachedPasswords
+	defer func() { testApp.config, testApp.breachedPasswords = config, breachedPasswords }()
+	testApp.config.password.history = 3
+	testApp.config.password.minLength = 12
+	testApp.breachedPasswords = breachList
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	userID := strconv.FormatInt(user.ID, 10)
+
+	changePassword := func(password string) int {
+		body, _ := json.Marshal(map[string]string{"password": password})
+		req := httptest.NewRequest(http.MethodPatch, "/v1/users/"+userID+"/password", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = setUserContext(setURLParam(req, "id", userID), adminUser)
+
+		rec := httptest.NewRecorder()
+		testApp.updatePasswordHandler(rec, req)
+		return rec.Code
+	}
+
+	steps := []struct {
+		name           string
+		password       string
+		expectedStatus int
+	}{
+		{"Shorter than the minimum length", "Short12!", http.StatusUnprocessableEntity},
+		{"Plain password on the breach list", "Breached123!", http.StatusUnprocessableEntity},
+		{"SHA-1 hash on the breach list", "Pwned12345678!", http.StatusUnprocessableEntity},
+		{"Current password", "TestOfficerPass123!", http.StatusUnprocessableEntity},
+		{"New password", "FirstChange123!", http.StatusOK},
+		{"Another new password", "SecondChange123!", http.StatusOK},
+		{"Password within the history", "FirstChange123!", http.StatusUnprocessableEntity},
+		{"Third new password", "ThirdChange123!", http.StatusOK},
+		{"Password older than the history", "TestOfficerPass123!", http.StatusOK},
+	}
+
+	for _, step := range steps {
+		t.Logf("Step: %s", step.name)
+		if code := changePassword(step.password); code != step.expectedStatus {
+			t.Errorf("%s: expected status %d; got %d", step.name, step.expectedStatus, code)
+		}
+	}
+
+	t.Log("Step: Logging in rehashes a password made with other parameters")
+	testApp.config.password.iterations = config.password.iterations + 1
+
+	body, _ := json.Marshal(map[string]string{"email": user.Email, "password": "TestOfficerPass123!"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/tokens/authentication", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	testApp.createAuthenticationTokenHandler(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d; got %d", http.StatusCreated, rec.Code)
+	}
+
+	user, err = testApp.models.User.GetByEmail(user.Email)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	if user.Password.NeedsRehash(testApp.passwordParams()) {
+		t.Error("Expected the password to be rehashed with the current parameters")
+	}
+	if match, _ := user.Password.Matches("TestOfficerPass123!"); !match {
+		t.Error("Expected the rehashed password to still match")
+	}
+}
+
 func TestDeleteUserHandler(t *testing.T) {
 	t.Log("=== Testing Delete User Handler (Soft Delete) ===")
 
@@ -949,3 +1059,382 @@ func TestUserWorkflow(t *testing.T) {
 
 	t.Log("Step: Complete user workflow test passed successfully!")
 }
+
+func TestInvitationFlow(t *testing.T) {
+	t.Log("=== Testing Invitations ===")
+
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+
+	invite := func(email string) int64 {
+		t.Helper()
+		body, _ := json.Marshal(map[string]any{
+			"first_name": "Invited",
+			"last_name":  "User",
+			"email":      email,
+			"gender":     "f",
+			"roles":      []string{"Officer"},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/v1/invitations", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = setUserContext(req, adminUser)
+
+		rec := httptest.NewRecorder()
+		testApp.createInvitationHandler(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d; got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+		}
+
+		var response struct {
+			Invitation data.Invitation `json:"invitation"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response.Invitation.UserID
+	}
+
+	accept := func(token, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"token": token, "password": password})
+		req := httptest.NewRequest(http.MethodPut, "/v1/invitations/accept", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		testApp.acceptInvitationHandler(rec, req)
+		return rec
+	}
+
+	t.Log("Step: Inviting a user")
+	email := fmt.Sprintf("invited%d@test-police-training.bz", time.Now().UnixNano())
+	userID := invite(email)
+	defer testApp.models.User.HardDelete(userID) // Cleanup
+
+	user, err := testApp.models.User.Get(userID)
+	if err != nil {
+		t.Fatalf("Failed to get invited user: %v", err)
+	}
+	if user.IsActivated {
+		t.Error("Expected the invited user to be inactive until they accept")
+	}
+
+	t.Log("Step: The same email cannot be invited twice")
+	body, _ := json.Marshal(map[string]any{"first_name": "Invited", "last_name": "User", "email": email, "gender": "f", "roles": []string{"Officer"}})
+	req := setUserContext(httptest.NewRequest(http.MethodPost, "/v1/invitations", bytes.NewReader(body)), adminUser)
+	rec := httptest.NewRecorder()
+	testApp.createInvitationHandler(rec, req)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a duplicate email; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: The invitation is listed as pending")
+	req = setUserContext(httptest.NewRequest(http.MethodGet, "/v1/invitations", nil), adminUser)
+	rec = httptest.NewRecorder()
+	testApp.listInvitationsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	var list struct {
+		Invitations []data.Invitation `json:"invitations"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	var pending *data.Invitation
+	for i := range list.Invitations {
+		if list.Invitations[i].UserID == userID {
+			pending = &list.Invitations[i]
+		}
+	}
+	if pending == nil {
+		t.Fatal("Expected the invitation in the pending list")
+	}
+	if len(pending.Roles) != 1 || pending.Roles[0] != "Officer" {
+		t.Errorf("Expected the invitation to carry the Officer role; got %v", pending.Roles)
+	}
+
+	// No mailer runs in tests, so resend the invitation directly to learn a token
+	_, token, err := testApp.models.Invitation.Invite(userID, &adminUser.ID, invitationTTL)
+	if err != nil {
+		t.Fatalf("Failed to resend invitation: %v", err)
+	}
+
+	t.Log("Step: A weak password is refused")
+	if rec := accept(token.Plaintext, "short"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a weak password; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Accepting the invitation")
+	if rec := accept(token.Plaintext, "InvitedPass123!"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	user, err = testApp.models.User.GetByEmail(email)
+	if err != nil {
+		t.Fatalf("Failed to get invited user: %v", err)
+	}
+	if !user.IsActivated {
+		t.Error("Expected the user to be activated after accepting")
+	}
+	if matches, err := user.Password.Matches("InvitedPass123!"); err != nil || !matches {
+		t.Error("Expected the chosen password to be set")
+	}
+
+	t.Log("Step: The token only works once")
+	if rec := accept(token.Plaintext, "InvitedPass456!"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a used token; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Revoking another invitation")
+	revokedID := invite(fmt.Sprintf("revoked%d@test-police-training.bz", time.Now().UnixNano()))
+	defer testApp.models.User.HardDelete(revokedID) // Cleanup if the revoke fails
+
+	revoked, err := testApp.models.Invitation.GetAllPending()
+	if err != nil {
+		t.Fatalf("Failed to list invitations: %v", err)
+	}
+	var invitationID int64
+	for _, invitation := range revoked {
+		if invitation.UserID == revokedID {
+			invitationID = invitation.ID
+		}
+	}
+
+	id := fmt.Sprint(invitationID)
+	req = setUserContext(setURLParam(httptest.NewRequest(http.MethodDelete, "/v1/invitations/"+id, nil), "id", id), adminUser)
+	rec = httptest.NewRecorder()
+	testApp.revokeInvitationHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+	if _, err := testApp.models.User.Get(revokedID); !errors.Is(err, data.ErrRecordNotFound) {
+		t.Errorf("Expected the revoked invitation's account to be deleted; got %v", err)
+	}
+
+	t.Log("Step: Self-registration is refused when closed or outside the allowed domains")
+	register := func(email string) int {
+		body, _ := json.Marshal(map[string]any{"first_name": "Self", "last_name": "Registered", "email": email, "gender": "m", "password": "SelfRegistered123!"})
+		req := httptest.NewRequest(http.MethodPost, "/v1/users", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		testApp.registerUserHandler(rec, req)
+		return rec.Code
+	}
+
+	if code := register("someone@example.com"); code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a domain that is not allowed; got %d", http.StatusUnprocessableEntity, code)
+	}
+
+	testApp.config.registration.open = false
+	defer func() { testApp.config.registration.open = true }()
+
+	if code := register(fmt.Sprintf("closed%d@test-police-training.bz", time.Now().UnixNano())); code != http.StatusForbidden {
+		t.Errorf("Expected status %d while registration is closed; got %d", http.StatusForbidden, code)
+	}
+}
+
+func TestInvitationGrantLimits(t *testing.T) {
+	t.Log("=== Testing Invitation Grant Limits ===")
+
+	// An inviter whose only role lets them invite and view officers in formation 1
+	role := &data.Role{Role: fmt.Sprintf("Test-Inviter-%d", time.Now().UnixNano())}
+	if err := testApp.models.Role.Insert(role); err != nil {
+		t.Fatalf("Failed to create role: %v", err)
+	}
+	defer testApp.models.Role.Delete(role.ID) // Cleanup
+	if err := testApp.models.Permission.AssignToRole(role.ID, "users:invite", "officers:view"); err != nil {
+		t.Fatalf("Failed to assign permissions: %v", err)
+	}
+
+	_, inviter, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(inviter.ID) // Cleanup
+
+	var formationID int64 = 1
+	if err := testApp.models.RoleUser.Assign(inviter.ID, nil, &formationID, role.Role); err != nil {
+		t.Fatalf("Failed to assign scoped role: %v", err)
+	}
+
+	scope, err := testApp.models.RoleUser.GetScope(inviter.ID, "users:invite")
+	if err != nil {
+		t.Fatalf("Failed to get scope: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		input          map[string]any
+		expectedStatus int
+	}{
+		{
+			name:           "Own role in own formation",
+			input:          map[string]any{"roles": []string{role.Role}, "formation_id": 1},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Admin role",
+			input:          map[string]any{"roles": []string{"Admin"}, "formation_id": 1},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Own role nationally",
+			input:          map[string]any{"roles": []string{role.Role}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Own role in another formation",
+			input:          map[string]any{"roles": []string{role.Role}, "formation_id": 2},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Own role across a whole region",
+			input:          map[string]any{"roles": []string{role.Role}, "region_id": 1},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input["first_name"] = "Invited"
+			tt.input["last_name"] = "User"
+			tt.input["email"] = fmt.Sprintf("granted%d@test-police-training.bz", time.Now().UnixNano())
+			tt.input["gender"] = "f"
+
+			body, _ := json.Marshal(tt.input)
+			req := httptest.NewRequest(http.MethodPost, "/v1/invitations", bytes.NewReader(body))
+			req = testApp.contextSetScope(setUserContext(req, inviter), scope)
+
+			rec := httptest.NewRecorder()
+			testApp.createInvitationHandler(rec, req)
+
+			t.Logf("Step: Received status code %d", rec.Code)
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d; got %d: %s", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			if rec.Code == http.StatusCreated {
+				var response struct {
+					Invitation data.Invitation `json:"invitation"`
+				}
+				_ = json.NewDecoder(rec.Body).Decode(&response)
+				testApp.models.User.HardDelete(response.Invitation.UserID) // Cleanup
+			}
+		})
+	}
+}
+
+func TestEmailChangeFlow(t *testing.T) {
+	t.Log("=== Testing Email Changes ===")
+
+	_, testUser, _ := createTestOfficer(t)
+	defer testApp.models.User.HardDelete(testUser.ID) // Cleanup
+
+	// GetByEmail loads the password hash the handler checks
+	user, err := testApp.models.User.GetByEmail(testUser.Email)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	oldEmail := user.Email
+	newEmail := fmt.Sprintf("changed%d@test-police-training.bz", time.Now().UnixNano())
+
+	requestChange := func(email, password string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+		req := httptest.NewRequest(http.MethodPut, "/v1/me/email", bytes.NewReader(body))
+		req = setUserContext(req, user)
+
+		rec := httptest.NewRecorder()
+		testApp.requestCurrentUserEmailChangeHandler(rec, req)
+		return rec
+	}
+
+	useToken := func(handler http.HandlerFunc, token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]string{"token": token})
+		req := httptest.NewRequest(http.MethodPut, "/v1/users/email", bytes.NewReader(body))
+
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	currentEmail := func() string {
+		t.Helper()
+		user, err := testApp.models.User.Get(user.ID)
+		if err != nil {
+			t.Fatalf("Failed to get test user: %v", err)
+		}
+		return user.Email
+	}
+
+	t.Log("Step: Invalid requests are refused")
+	if rec := requestChange(newEmail, "WrongPassword123!"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a wrong password; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	if rec := requestChange("admin1@police-training.bz", "TestOfficerPass123!"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for an address in use; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	if rec := requestChange(oldEmail, "TestOfficerPass123!"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for the current address; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: Requesting a change leaves the email as it is")
+	if rec := requestChange(newEmail, "TestOfficerPass123!"); rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	if email := currentEmail(); email != oldEmail {
+		t.Errorf("Expected the email to stay %s until confirmed; got %s", oldEmail, email)
+	}
+
+	// No mailer runs in tests, so request the change directly to learn the tokens
+	confirmToken, cancelToken, err := testApp.models.EmailChange.Request(&data.EmailChange{UserID: user.ID, NewEmail: newEmail}, emailChangeTTL)
+	if err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+
+	t.Log("Step: Cancelling from the old address")
+	if rec := useToken(testApp.cancelEmailChangeHandler, cancelToken.Plaintext); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := useToken(testApp.confirmEmailChangeHandler, confirmToken.Plaintext); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d confirming a cancelled change; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	t.Log("Step: An admin update of the email also waits for confirmation")
+	adminUser := getSeededUser(t, "admin1@police-training.bz")
+	id := fmt.Sprint(user.ID)
+	body, _ := json.Marshal(map[string]any{"email": newEmail, "version": user.Version})
+	req := httptest.NewRequest(http.MethodPatch, "/v1/users/"+id, bytes.NewReader(body))
+	req = setUserContext(setURLParam(req, "id", id), adminUser)
+	rec := httptest.NewRecorder()
+	testApp.updateUserHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response["email_change"] == nil {
+		t.Error("Expected the pending email change in the response")
+	}
+	if email := currentEmail(); email != oldEmail {
+		t.Errorf("Expected the email to stay %s until confirmed; got %s", oldEmail, email)
+	}
+
+	t.Log("Step: Confirming from the new address")
+	confirmToken, cancelToken, err = testApp.models.EmailChange.Request(&data.EmailChange{UserID: user.ID, NewEmail: newEmail}, emailChangeTTL)
+	if err != nil {
+		t.Fatalf("Failed to request email change: %v", err)
+	}
+	if rec := useToken(testApp.confirmEmailChangeHandler, confirmToken.Plaintext); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d; got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if email := currentEmail(); email != newEmail {
+		t.Errorf("Expected the email to be %s; got %s", newEmail, email)
+	}
+
+	t.Log("Step: The tokens only work once")
+	if rec := useToken(testApp.confirmEmailChangeHandler, confirmToken.Plaintext); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d for a used token; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+	if rec := useToken(testApp.cancelEmailChangeHandler, cancelToken.Plaintext); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d cancelling a confirmed change; got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+}
@@ -0,0 +1,198 @@
+// Filename: cmd/api/waitlists.go
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/data"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+// showSessionWaitlistHandler lists a training session's waitlisted enrollments in the order they will be
+// offered seats
+//
+//	@Summary		Show a session waitlist
+//	@Description	List the waitlisted enrollments of a training session by waitlist position, first in line first
+//	@Tags			training-sessions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Training session ID"
+//	@Success		200	{object}	envelope
+//	@Failure		401	{object}	errorResponse
+//	@Failure		403	{object}	errorResponse
+//	@Failure		404	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/v1/training/sessions/{id}/waitlist [get]
+func (app *appDependencies) showSessionWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if _, err := app.models.TrainingSession.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	waitlist, err := app.models.TrainingEnrollment.GetWaitlist(id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"waitlist": waitlist}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// reorderSessionWaitlistHandler puts a training session's waitlist in a new order, given every
+// waitlisted enrollment of the session
+//
+//	@Summary		Reorder a session waitlist
+//	@Description	Renumber the waitlist in the order of enrollment_ids, which must list every waitlisted enrollment of the session exactly once
+//	@Tags			training-sessions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int							true	"Training session ID"
+//	@Param			waitlist	body		ReorderWaitlistRequest_T	true	"Enrollment IDs in their new order"
+//	@Success		200			{object}	envelope
+//	@Failure		400			{object}	errorResponse
+//	@Failure		401			{object}	errorResponse
+//	@Failure		403			{object}	errorResponse
+//	@Failure		404			{object}	errorResponse
+//	@Failure		422			{object}	errorResponse
+//	@Failure		500			{object}	errorResponse
+//	@Router			/v1/training/sessions/{id}/waitlist [put]
+func (app *appDependencies) reorderSessionWaitlistHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParameter(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		EnrollmentIDs []int64 `json:"enrollment_ids"`
+	}
+
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(input.EnrollmentIDs != nil, "enrollment_ids", "must be provided")
+
+	if !v.IsEmpty() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := app.models.TrainingSession.GetInScope(id, app.contextGetScope(r)); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	waitlist, err := app.models.TrainingEnrollment.ReorderWaitlist(id, input.EnrollmentIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrWaitlistMismatch):
+			v.AddError("enrollment_ids", "must list every waitlisted enrollment of the session exactly once")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.recordAudit(r, data.AuditEntityTrainingSession, id, data.AuditActionReorderWaitlist, nil, map[string]any{"enrollment_ids": input.EnrollmentIDs})
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"waitlist": waitlist}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// promoteWaitlist fills any seats freed in a session from its waitlist and emails the promoted
+// officers. Failures are logged rather than returned because the change that freed the seat has
+// already been saved.
+func (app *appDependencies) promoteWaitlist(sessionID int64) {
+	promoted, err := app.models.TrainingEnrollment.PromoteFromWaitlist(sessionID)
+	if err != nil {
+		app.logger.Error("failed to promote waitlisted enrollments", "session_id", sessionID, "error", err)
+		return
+	}
+
+	for _, enrollment := range promoted {
+		app.logger.Info("promoted enrollment from waitlist", "enrollment_id", enrollment.ID, "session_id", sessionID)
+		app.notifyWaitlistPromotion(enrollment)
+	}
+}
+
+// notifyWaitlistPromotion emails an officer whose enrollment was promoted from the waitlist.
+func (app *appDependencies) notifyWaitlistPromotion(enrollment *data.TrainingEnrollment) {
+	if app.mailer == nil {
+		return
+	}
+
+	app.background(func() {
+		officer, err := app.models.Officer.Get(enrollment.OfficerID)
+		if err != nil {
+			app.logger.Error("failed to load promoted officer", "enrollment_id", enrollment.ID, "error", err)
+			return
+		}
+
+		user, err := app.models.User.Get(officer.UserID)
+		if err != nil {
+			app.logger.Error("failed to load promoted officer's user", "enrollment_id", enrollment.ID, "error", err)
+			return
+		}
+
+		session, err := app.models.TrainingSession.Get(enrollment.SessionID)
+		if err != nil {
+			app.logger.Error("failed to load session for promotion email", "enrollment_id", enrollment.ID, "error", err)
+			return
+		}
+
+		workshop, err := app.models.Workshop.Get(session.WorkshopID)
+		if err != nil {
+			app.logger.Error("failed to load workshop for promotion email", "enrollment_id", enrollment.ID, "error", err)
+			return
+		}
+
+		location := ""
+		if session.Location != nil {
+			location = *session.Location
+		}
+
+		data := map[string]any{
+			"firstName":    user.FirstName,
+			"workshopName": workshop.WorkshopName,
+			"sessionDate":  session.SessionDate.Format("Monday, 2 January 2006"),
+			"startTime":    session.StartTime.Format("15:04"),
+			"endTime":      session.EndTime.Format("15:04"),
+			"location":     location,
+			"enrollmentID": enrollment.ID,
+		}
+
+		if err := app.mailer.Send(user.Email, "waitlist_promotion.tmpl", data); err != nil {
+			app.logger.Error("failed to send waitlist promotion email", "error", err)
+		}
+	})
+}
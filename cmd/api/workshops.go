@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/data"
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
@@ -72,6 +73,8 @@ func (app *appDependencies) createWorkshopHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityWorkshop, workshop.ID, data.AuditActionCreate, nil, workshop)
+
 	headers := make(http.Header)
 	headers.Set("Location", fmt.Sprintf("/v1/workshops/%d", workshop.ID))
 
@@ -132,6 +135,11 @@ func (app *appDependencies) listWorkshopsHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if app.wantsCSV(r) {
+		app.writeWorkshopsCSV(w, r, name, categoryID, typeID, isActive, filters)
+		return
+	}
+
 	workshops, metadata, err := app.models.Workshop.GetAll(name, categoryID, typeID, isActive, filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -144,6 +152,30 @@ func (app *appDependencies) listWorkshopsHandler(w http.ResponseWriter, r *http.
 	}
 }
 
+// writeWorkshopsCSV streams every workshop matching the filters, with their category and type spelled out
+func (app *appDependencies) writeWorkshopsCSV(w http.ResponseWriter, r *http.Request, name string, categoryID, typeID *int64, isActive *bool, filters data.Filters) {
+	names, err := app.models.Lookup.Names(data.LookupTrainingCategories, data.LookupTrainingTypes)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	header := []string{"ID", "Workshop", "Category", "Type", "Credit Hours", "Description", "Active"}
+	app.streamCSV(w, r, "workshops", header, func(write func([]string) error) error {
+		return app.models.Workshop.Each(name, categoryID, typeID, isActive, filters, func(ws *data.Workshop) error {
+			return write([]string{
+				strconv.FormatInt(ws.ID, 10),
+				ws.WorkshopName,
+				csvName(names[data.LookupTrainingCategories], ws.CategoryID),
+				csvName(names[data.LookupTrainingTypes], ws.TypeID),
+				strconv.Itoa(ws.CreditHours),
+				csvString(ws.Description),
+				csvBool(ws.IsActive),
+			})
+		})
+	})
+}
+
 func (app *appDependencies) updateWorkshopHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDParameter(r)
 	if err != nil {
@@ -162,6 +194,8 @@ func (app *appDependencies) updateWorkshopHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	before := *workshop
+
 	var input struct {
 		WorkshopName *string `json:"workshop_name"`
 		CategoryID   *int64  `json:"category_id"`
@@ -220,6 +254,8 @@ func (app *appDependencies) updateWorkshopHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityWorkshop, workshop.ID, data.AuditActionUpdate, &before, workshop)
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"workshop": workshop}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -233,6 +269,17 @@ func (app *appDependencies) deleteWorkshopHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	workshop, err := app.models.Workshop.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
 	err = app.models.Workshop.Delete(id)
 	if err != nil {
 		switch {
@@ -244,6 +291,8 @@ func (app *appDependencies) deleteWorkshopHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	app.recordAudit(r, data.AuditEntityWorkshop, workshop.ID, data.AuditActionDelete, workshop, nil)
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"message": "workshop successfully deleted"}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -111,7 +111,7 @@ func populateUsers(db *sql.DB) {
 		}
 
 		// Set password - this automatically hashes it
-		err = user.Password.Set("TrainingPass123!")
+		err = user.Password.Set("TrainingPass123!", data.DefaultPasswordParams)
 		if err != nil {
 			log.Printf("Failed to set password for %s: %v", userData.Email, err)
 			continue
@@ -434,7 +434,7 @@ func populateEnrollments(db *sql.DB) {
 				CertificateNumber:  &certificateNumber,
 			}
 
-			err = enrollmentModel.Insert(enrollment)
+			err = enrollmentModel.Insert(enrollment, false)
 			if err != nil {
 				log.Printf("Failed to create enrollment for officer %d in session %d: %v", officer.ID, session.ID, err)
 				continue
@@ -17,14 +17,10 @@ const docTemplate = `{
     "paths": {
         "/v1/attendance/status": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
                 "description": "Retrieve a list of attendance statuses with optional filtering",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
                     "attendance-statuses"
@@ -60,6 +56,12 @@ const docTemplate = `{
                         "description": "Sort order",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -81,14 +83,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Create a new attendance status",
                 "consumes": [
                     "application/json"
@@ -136,16 +138,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/attendance/status/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/attendance/status/{id}": {
+            "get": {
                 "description": "Retrieve an attendance status by its ID",
                 "produces": [
                     "application/json"
@@ -182,14 +184,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "patch": {
                 "description": "Perform a partial update on an attendance status record",
                 "consumes": [
                     "application/json"
@@ -250,20 +252,181 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/v1/enrollment/status": {
+        "/v1/audit": {
             "get": {
+                "description": "Retrieve recorded changes with optional filtering by actor, entity, action and date range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by the user who made the change",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity type, such as officer or training_enrollment",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by action, such as create, update or delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes on or after this date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes on or before this date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
+                ]
+            }
+        },
+        "/v1/certificates/public-key": {
+            "get": {
+                "description": "Public endpoint returning the PEM encoded Ed25519 public key that certificate number signatures can be checked against offline. Returns 404 when certificate signing is not configured.",
+                "produces": [
+                    "application/x-pem-file"
                 ],
-                "description": "Retrieve a list of enrollment statuses with optional filtering by status name",
+                "tags": [
+                    "certificates"
+                ],
+                "summary": "Get the certificate signing public key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/certificates/verify/{number}": {
+            "get": {
+                "description": "Public endpoint confirming a certificate number is genuine and still valid. Signed numbers are checked against the certificate signing key as well as the database, and signature_verified reports whether that check was made.",
                 "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "certificates"
+                ],
+                "summary": "Verify a certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Certificate number",
+                        "name": "number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/enrollment/status": {
+            "get": {
+                "description": "Retrieve a list of enrollment statuses with optional filtering by status name",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
                 "tags": [
                     "enrollment-statuses"
                 ],
@@ -292,6 +455,12 @@ const docTemplate = `{
                         "description": "Sort order",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -313,14 +482,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Create a new enrollment status",
                 "consumes": [
                     "application/json"
@@ -368,16 +537,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/enrollment/status/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/enrollment/status/{id}": {
+            "get": {
                 "description": "Retrieve an enrollment status by its ID",
                 "produces": [
                     "application/json"
@@ -414,14 +583,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "patch": {
                 "description": "Perform a partial update on an enrollment status record",
                 "consumes": [
                     "application/json"
@@ -482,19 +651,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/formations": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/formations": {
+            "get": {
                 "description": "Retrieve a list of formations with optional filtering by name and region",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
                     "formations"
@@ -530,6 +700,12 @@ const docTemplate = `{
                         "description": "Sort order",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -551,14 +727,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Create a new formation",
                 "consumes": [
                     "application/json"
@@ -606,16 +782,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/formations/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/formations/{id}": {
+            "get": {
                 "description": "Retrieve a formation by its ID",
                 "produces": [
                     "application/json"
@@ -652,14 +828,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            },
+            "patch": {
                 "description": "Perform a partial update on a formation record",
                 "consumes": [
                     "application/json"
@@ -720,24 +896,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/me": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve the user record associated with the current request context",
+                ]
+            }
+        },
+        "/v1/invitations": {
+            "get": {
+                "description": "Retrieve the invitations that have not been accepted, newest first, including expired ones",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invitations"
                 ],
-                "summary": "Get current user",
+                "summary": "List pending invitations",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -745,59 +921,3455 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
-                    }
-                }
-            }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create an inactive account for an email with the given roles, optionally held in one region or formation, and an optional officer record, then email the invitee a link to accept. The roles cannot carry permissions the inviter lacks, nor reach beyond the regions and formations the inviter's own are held in. The link expires after 7 days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Invite a user",
+                "parameters": [
+                    {
+                        "description": "Invitation details",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateInvitationRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
         },
-        "/v1/officers": {
+        "/v1/invitations/accept": {
+            "put": {
+                "description": "Choose a password with the token from an invitation email. The account is activated and the token stops working.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.AcceptInvitationRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/invitations/{id}": {
+            "delete": {
+                "description": "Withdraw an invitation that has not been accepted, deleting the inactive account, roles and officer record created for it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/invitations/{id}/resend": {
+            "post": {
+                "description": "Email a pending invitation again with a new link valid for 7 days. Links sent earlier stop working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invitations"
+                ],
+                "summary": "Resend an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me": {
+            "get": {
+                "description": "Retrieve the user record associated with the current request context",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/2fa": {
             "get": {
+                "description": "Report whether two-factor authentication is enabled and how many recovery codes are left",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Show my two-factor status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Generate a TOTP secret and otpauth:// provisioning URI for an authenticator app. Replaces any setup that was not confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Start two-factor setup",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Turn two-factor authentication off, given a current code from the authenticator app or an unused recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Disable two-factor authentication",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/2fa/confirm": {
+            "post": {
+                "description": "Enable two-factor authentication with a code from the authenticator app. The response holds single-use recovery codes, which are not shown again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Confirm two-factor setup",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/2fa/recovery-codes": {
+            "post": {
+                "description": "Replace every recovery code with a new set, given a current code from the authenticator app or an unused recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Regenerate recovery codes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/certificates": {
+            "get": {
+                "description": "Retrieve the certificates issued to you that have not been revoked, most recently completed first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "List your certificates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/compliance": {
+            "get": {
+                "description": "Compare your credit hours from completed enrollments in a year against your rank requirement",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Get your training compliance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Compliance year (defaults to the current year)",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/email": {
+            "put": {
+                "description": "Start changing your email address. The new address is emailed a token to confirm it, valid for 24 hours, and your current address is told about the change with a token to cancel it. Your email only changes once the new address is confirmed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change your email address",
+                "parameters": [
+                    {
+                        "description": "New email address and current password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ChangeEmailRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Cancel your pending email address change. The confirmation token sent to the new address stops working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Cancel your email change",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/enrollments": {
+            "get": {
+                "description": "Retrieve your training enrollments with pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "List your training enrollments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (created_at, -created_at, completion_date, -completion_date)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/officer": {
+            "get": {
+                "description": "Retrieve your officer record with your rank, posting, formation and region",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Get your officer record",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/sessions": {
+            "get": {
+                "description": "Retrieve the current user's active logins with when and where each was created and last used. The session making the request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List my sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/sessions/{id}": {
+            "delete": {
+                "description": "Revoke the authentication and refresh tokens of one of the current user's logins, logging that device out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke one of my sessions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/me/upcoming-sessions": {
+            "get": {
+                "description": "Retrieve the training sessions from today on that you hold a seat in or are waitlisted for, in chronological order. Cancelled sessions and enrollments you no longer hold are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "List your upcoming sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers": {
+            "get": {
+                "description": "Retrieve a list of officers with optional filtering and pagination",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "List officers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by regulation number",
+                        "name": "regulation_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by rank ID",
+                        "name": "rank_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by posting ID",
+                        "name": "posting_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by formation ID",
+                        "name": "formation_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by region ID",
+                        "name": "region_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new officer record linked to a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Create a new officer",
+                "parameters": [
+                    {
+                        "description": "Officer data",
+                        "name": "officer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateOfficerRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/officers/import": {
+            "post": {
+                "description": "Create users and officers from a CSV or XLSX file of up to 500 officers and 5 MB. The first row names the columns first_name, last_name, email, gender, regulation_number, rank, posting, formation and an optional region. With dry_run=true every row is validated and reported without creating anything. Otherwise the whole file is imported in one transaction, nothing is created if any row is invalid, and each imported officer is invited to choose a password.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Import officers",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or XLSX file of officers",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate the file without importing it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Dry run results",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers/{id}": {
+            "get": {
+                "description": "Retrieve an officer by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Get an officer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Remove an officer record",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Delete an officer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Update an existing officer's information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Update an officer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Officer update data",
+                        "name": "officer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateOfficerRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers/{id}/compliance": {
+            "get": {
+                "description": "Compare credit hours from completed enrollments in a year against the officer's rank requirement",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Get officer training compliance",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Compliance year (defaults to the current year)",
+                        "name": "year",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers/{id}/details": {
+            "get": {
+                "description": "Retrieve an officer with all related information (user, rank, posting, etc.)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "Get an officer with details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers/{id}/enrollments": {
+            "get": {
+                "description": "Retrieve the enrollments of an officer within the caller's scope, with pagination, or export every one as CSV",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "officers"
+                ],
+                "summary": "List an officer's enrollments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field (created_at or completion_date, prefix with - for descending)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/officers/{id}/schedule.ics": {
+            "get": {
+                "description": "Publish the sessions an officer is enrolled in as an RFC 5545 iCalendar feed. Waitlisted places are marked tentative, and cancelled or withdrawn enrollments as cancelled. Calendar apps can subscribe by passing a calendar token in the token query parameter.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Officer schedule calendar feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Officer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Calendar token, for subscribing without an Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest session date (YYYY-MM-DD, default a year ago)",
+                        "name": "from",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/permissions": {
+            "get": {
+                "description": "Retrieve every permission code that can be given to a role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/postings": {
+            "get": {
+                "description": "Retrieve a list of postings with optional filtering by name and code",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "postings"
+                ],
+                "summary": "List postings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by posting name",
+                        "name": "posting",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by posting code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new posting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "postings"
+                ],
+                "summary": "Create a new posting",
+                "parameters": [
+                    {
+                        "description": "Posting data",
+                        "name": "posting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreatePostingRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/postings/{id}": {
+            "get": {
+                "description": "Retrieve a posting by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "postings"
+                ],
+                "summary": "Get a posting",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Posting ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a posting record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "postings"
+                ],
+                "summary": "Update a posting",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Posting ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Posting data",
+                        "name": "posting",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdatePostingRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/progress/status": {
+            "get": {
+                "description": "Retrieve a list of progress statuses with optional filtering",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "progress-statuses"
+                ],
+                "summary": "List progress statuses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status name",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new progress status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress-statuses"
+                ],
+                "summary": "Create a new progress status",
+                "parameters": [
+                    {
+                        "description": "Progress status data",
+                        "name": "progress_status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateProgressStatusRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/progress/status/{id}": {
+            "get": {
+                "description": "Retrieve a progress status by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress-statuses"
+                ],
+                "summary": "Get a progress status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Progress status ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a progress status record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress-statuses"
+                ],
+                "summary": "Update a progress status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Progress status ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Progress status data",
+                        "name": "progress_status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateProgressStatusRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/ranks": {
+            "get": {
+                "description": "Retrieve a list of ranks with optional filtering by rank name and code",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "ranks"
+                ],
+                "summary": "List ranks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by rank name",
+                        "name": "rank",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by rank code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new rank",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranks"
+                ],
+                "summary": "Create a new rank",
+                "parameters": [
+                    {
+                        "description": "Rank data",
+                        "name": "rank",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateRankRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/ranks/{id}": {
+            "get": {
+                "description": "Retrieve a rank by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranks"
+                ],
+                "summary": "Get a rank",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a rank record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ranks"
+                ],
+                "summary": "Update a rank",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rank ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rank data",
+                        "name": "rank",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateRankRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/regions": {
+            "get": {
+                "description": "Retrieve a list of regions with optional filtering",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "List regions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by region name",
+                        "name": "region",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a new region",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Create a new region",
+                "parameters": [
+                    {
+                        "description": "Region data",
+                        "name": "region",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateRegionRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/regions/{id}": {
+            "get": {
+                "description": "Retrieve a region by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Get a region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a region record",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "regions"
+                ],
+                "summary": "Update a region",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Region ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Region data",
+                        "name": "region",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateRegionRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/reports/compliance": {
+            "get": {
+                "description": "Count compliant, on-track, at-risk and non-compliant officers and average credit hours per region or formation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Compliance rollup report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group by region or formation (default region)",
+                        "name": "group_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Compliance year (defaults to the current year)",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include officers holding this rank",
+                        "name": "rank_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include officers in this posting",
+                        "name": "posting_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/roles": {
+            "get": {
+                "description": "Retrieve every role along with its permission codes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Create a role with an optional list of permission codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a role",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/roles/{id}": {
+            "get": {
+                "description": "Retrieve a role and its permission codes by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Delete a role and take it away from every user that holds it. The Admin role cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "patch": {
+                "description": "Change the name of a role. The Admin role cannot be renamed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Rename a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/roles/{id}/permissions": {
+            "post": {
+                "description": "Give a role a list of permission codes. Codes the role already has are ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Add permissions to a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Take a list of permission codes away from a role. The Admin role keeps every permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Remove permissions from a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/tokens/authentication": {
+            "post": {
+                "description": "Generates an authentication token for a user based on provided email and password. Users with two-factor authentication enabled get a 202 with a two_factor_token to complete at POST /v1/tokens/two-factor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Create Authentication Token",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAuthenticationTokenRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revokes the authentication token used for this request, and the refresh tokens issued with it, logging out this device only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Delete Authentication Token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/tokens/calendar": {
+            "post": {
+                "description": "Generates a long-lived token for subscribing to .ics calendar feeds with ?token=. Any previous calendar token is revoked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Create Calendar Feed Token",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
+                ]
+            },
+            "delete": {
+                "description": "Revokes the current user's calendar feed token so existing subscriptions stop working.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Revoke Calendar Feed Token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/tokens/password-reset": {
+            "post": {
+                "description": "Generates a password reset token and sends it to the user's email address.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve a list of officers with optional filtering and pagination",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "officers"
+                    "Tokens"
                 ],
-                "summary": "List officers",
+                "summary": "Create Password Reset Token",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreatePasswordResetTokenRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tokens/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new authentication token and a new refresh token. Each refresh token can be used once; replaying one that was already used revokes every token from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Refresh Authentication Token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RefreshAuthenticationTokenRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tokens/two-factor": {
+            "post": {
+                "description": "Exchanges the two_factor_token returned by POST /v1/tokens/authentication and a code from the authenticator app, or an unused recovery code, for authentication and refresh tokens. Wrong codes count towards account lockout.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tokens"
+                ],
+                "summary": "Complete Two-Factor Login",
+                "parameters": [
+                    {
+                        "description": "Two-factor token and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateTwoFactorAuthenticationTokenRequest_T"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/training/categories": {
+            "get": {
+                "description": "Retrieve a list of training categories with optional filtering by name and active status",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "training-categories"
+                ],
+                "summary": "List training categories",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by regulation number",
-                        "name": "regulation_number",
+                        "description": "Filter by category name",
+                        "name": "name",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Filter by rank ID",
-                        "name": "rank_id",
+                        "type": "boolean",
+                        "description": "Filter by active status",
+                        "name": "is_active",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Filter by posting ID",
-                        "name": "posting_id",
+                        "description": "Page number for pagination",
+                        "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Filter by formation ID",
-                        "name": "formation_id",
+                        "description": "Number of items per page",
+                        "name": "page_size",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Filter by region ID",
-                        "name": "region_id",
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
@@ -808,16 +4380,27 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             },
             "post": {
-                "description": "Create a new officer record linked to a user",
+                "description": "Create a new training category",
                 "consumes": [
                     "application/json"
                 ],
@@ -825,17 +4408,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "officers"
+                    "training-categories"
                 ],
-                "summary": "Create a new officer",
+                "summary": "Create a new training category",
                 "parameters": [
                     {
-                        "description": "Officer data",
-                        "name": "officer",
+                        "description": "Training category data",
+                        "name": "training_category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateOfficerRequest_T"
+                            "$ref": "#/definitions/main.CreateTrainingCategoryRequest_T"
                         }
                     }
                 ],
@@ -864,28 +4447,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/officers/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve an officer by their ID",
+                ]
+            }
+        },
+        "/v1/training/categories/{id}": {
+            "get": {
+                "description": "Retrieve a training category by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "officers"
+                    "training-categories"
                 ],
-                "summary": "Get an officer",
+                "summary": "Get a training category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Officer ID",
+                        "description": "Training category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -910,48 +4493,54 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a training category record",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Remove an officer record",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "officers"
+                    "training-categories"
                 ],
-                "summary": "Delete an officer",
+                "summary": "Update a training category",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Officer ID",
+                        "description": "Training category ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Training category data",
+                        "name": "training_category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateTrainingCategoryRequest_T"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/main.envelope"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "message": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
                         }
                     },
                     "404": {
@@ -960,21 +4549,29 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Update an existing officer's information",
+                ]
+            }
+        },
+        "/v1/training/enrollments/{id}/certificate": {
+            "post": {
+                "description": "Issue the next sequential certificate number for an enrollment that counts as present and is completed, signed when certificate signing is configured. completion_date (YYYY-MM-DD) defaults to today.",
                 "consumes": [
                     "application/json"
                 ],
@@ -982,24 +4579,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "officers"
+                    "certificates"
                 ],
-                "summary": "Update an officer",
+                "summary": "Issue a certificate",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Officer ID",
+                        "description": "Training enrollment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Officer update data",
-                        "name": "officer",
+                        "description": "Completion date",
+                        "name": "certificate",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateOfficerRequest_T"
+                            "$ref": "#/definitions/main.IssueCertificateRequest_T"
                         }
                     }
                 ],
@@ -1016,12 +4612,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -1034,28 +4648,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/officers/{id}/details": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve an officer with all related information (user, rank, posting, etc.)",
+                ]
+            }
+        },
+        "/v1/training/enrollments/{id}/certificate.pdf": {
+            "get": {
+                "description": "Render the certificate issued for a completed enrollment as a PDF document, showing the officer, workshop, credit hours, completion date and certificate number",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
-                    "officers"
+                    "certificates"
                 ],
-                "summary": "Get an officer with details",
+                "summary": "Download a certificate",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Officer ID",
+                        "description": "Training enrollment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1065,80 +4679,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.envelope"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/v1/postings": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieve a list of postings with optional filtering by name and code",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "postings"
-                ],
-                "summary": "List postings",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Filter by posting name",
-                        "name": "posting",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by posting code",
-                        "name": "code",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "page_size",
-                        "in": "query"
                     },
-                    {
-                        "type": "string",
-                        "description": "Sort order",
-                        "name": "sort",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/main.envelope"
+                            "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -1149,15 +4712,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Create a new posting",
+                ]
+            }
+        },
+        "/v1/training/enrollments/{id}/certificate/reissue": {
+            "post": {
+                "description": "Issue a new certificate number for an enrollment that still counts as present and completed, revoking the current certificate with the given reason when it is still valid",
                 "consumes": [
                     "application/json"
                 ],
@@ -1165,23 +4730,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "postings"
+                    "certificates"
                 ],
-                "summary": "Create a new posting",
+                "summary": "Reissue a certificate",
                 "parameters": [
                     {
-                        "description": "Posting data",
-                        "name": "posting",
+                        "type": "integer",
+                        "description": "Training enrollment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the reissue",
+                        "name": "reissue",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreatePostingRequest_T"
+                            "$ref": "#/definitions/main.CertificateRevocationRequest_T"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/main.envelope"
                         }
@@ -1192,6 +4764,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -1204,28 +4800,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/postings/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a posting by its ID",
+                ]
+            }
+        },
+        "/v1/training/enrollments/{id}/certificate/revocations": {
+            "get": {
+                "description": "Retrieve every revocation recorded against an enrollment's certificates, with the revoked number, reason, who revoked it and when",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "postings"
+                    "certificates"
                 ],
-                "summary": "Get a posting",
+                "summary": "List certificate revocations",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Posting ID",
+                        "description": "Training enrollment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1238,6 +4834,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1250,15 +4858,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Perform a partial update on a posting record",
+                    }
+                ]
+            }
+        },
+        "/v1/training/enrollments/{id}/certificate/revoke": {
+            "post": {
+                "description": "Revoke the certificate issued for an enrollment with a reason. The certificate number then fails public verification.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1266,24 +4876,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "postings"
+                    "certificates"
                 ],
-                "summary": "Update a posting",
+                "summary": "Revoke a certificate",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Posting ID",
+                        "description": "Training enrollment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Posting data",
-                        "name": "posting",
+                        "description": "Reason for the revocation",
+                        "name": "revocation",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdatePostingRequest_T"
+                            "$ref": "#/definitions/main.CertificateRevocationRequest_T"
                         }
                     }
                 ],
@@ -1300,12 +4910,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -1318,46 +4946,58 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/progress/status": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of progress statuses with optional filtering",
+                ]
+            }
+        },
+        "/v1/training/session-conflicts": {
+            "get": {
+                "description": "List pairs of overlapping sessions that share a facilitator or a location, limited to the caller's regions and formations",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "progress-statuses"
+                    "training-sessions"
                 ],
-                "summary": "List progress statuses",
+                "summary": "Audit session conflicts",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by status name",
-                        "name": "status",
+                        "description": "facilitator or location (default both)",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest session date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest session date (YYYY-MM-DD)",
+                        "name": "to",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page number for pagination",
+                        "description": "Page number",
                         "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of items per page",
+                        "description": "Page size",
                         "name": "page_size",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order",
+                        "description": "Sort field",
                         "name": "sort",
                         "in": "query"
                     }
@@ -1369,57 +5009,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
-                    }
-                }
-            },
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Create a new progress status",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "progress-statuses"
-                ],
-                "summary": "Create a new progress status",
-                "parameters": [
-                    {
-                        "description": "Progress status data",
-                        "name": "progress_status",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateProgressStatusRequest_T"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/main.envelope"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -1436,104 +5033,89 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/progress/status/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a progress status by its ID",
+                ]
+            }
+        },
+        "/v1/training/sessions.ics": {
+            "get": {
+                "description": "Publish training sessions as an RFC 5545 iCalendar feed, filtered like the session list. Calendar apps can subscribe by passing a calendar token in the token query parameter instead of an Authorization header.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "progress-statuses"
+                    "calendar"
                 ],
-                "summary": "Get a progress status",
+                "summary": "Training sessions calendar feed",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar token, for subscribing without an Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest session date (YYYY-MM-DD, default a year ago)",
+                        "name": "from",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Progress status ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/main.envelope"
-                        }
+                        "description": "Filter by facilitator ID",
+                        "name": "facilitator_id",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "description": "Filter by workshop ID",
+                        "name": "workshop_id",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    }
-                }
-            },
-            "patch": {
-                "security": [
                     {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Perform a partial update on a progress status record",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "progress-statuses"
-                ],
-                "summary": "Update a progress status",
-                "parameters": [
+                        "type": "integer",
+                        "description": "Filter by formation ID",
+                        "name": "formation_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Progress status ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by region ID",
+                        "name": "region_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Progress status data",
-                        "name": "progress_status",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateProgressStatusRequest_T"
-                        }
+                        "type": "integer",
+                        "description": "Filter by training status ID",
+                        "name": "training_status_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by session date (YYYY-MM-DD)",
+                        "name": "session_date",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.envelope"
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -1550,54 +5132,56 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/ranks": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of ranks with optional filtering by rank name and code",
+                ]
+            }
+        },
+        "/v1/training/sessions/{id}/enrollments": {
+            "get": {
+                "description": "Retrieve the enrollments of a training session within the caller's scope, with pagination, or export every one as CSV",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "ranks"
+                    "training-sessions"
                 ],
-                "summary": "List ranks",
+                "summary": "List a session's enrollments",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by rank name",
-                        "name": "rank",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by rank code",
-                        "name": "code",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Training session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Page number for pagination",
+                        "description": "Page number",
                         "name": "page",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of items per page",
+                        "description": "Page size",
                         "name": "page_size",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order",
+                        "description": "Sort field (created_at or completion_date, prefix with - for descending)",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1607,6 +5191,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -1619,15 +5215,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Create a new rank",
+                ]
+            }
+        },
+        "/v1/training/sessions/{id}/enrollments/bulk": {
+            "post": {
+                "description": "Enroll up to 500 officers listed in officer_ids, or every officer matching formation_id, posting_id, rank_id or region_id, in one transaction. Each officer is reported as enrolled, waitlisted, already_enrolled or rejected, with a summary of the counts. Officers who would be double booked are rejected unless override_schedule_clash is set by a user allowed to override clashes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1635,23 +5233,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ranks"
+                    "training-sessions"
                 ],
-                "summary": "Create a new rank",
+                "summary": "Bulk enroll officers in a session",
                 "parameters": [
                     {
-                        "description": "Rank data",
-                        "name": "rank",
+                        "type": "integer",
+                        "description": "Training session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Officers to enroll",
+                        "name": "enrollments",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateRankRequest_T"
+                            "$ref": "#/definitions/main.BulkEnrollSessionRequest_T"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/main.envelope"
                         }
@@ -1662,6 +5267,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -1674,28 +5297,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/ranks/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a rank by its ID",
+                ]
+            }
+        },
+        "/v1/training/sessions/{id}/waitlist": {
+            "get": {
+                "description": "List the waitlisted enrollments of a training session by waitlist position, first in line first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ranks"
+                    "training-sessions"
                 ],
-                "summary": "Get a rank",
+                "summary": "Show a session waitlist",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rank ID",
+                        "description": "Training session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1708,6 +5331,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1720,15 +5355,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Perform a partial update on a rank record",
+                ]
+            },
+            "put": {
+                "description": "Renumber the waitlist in the order of enrollment_ids, which must list every waitlisted enrollment of the session exactly once",
                 "consumes": [
                     "application/json"
                 ],
@@ -1736,24 +5371,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ranks"
+                    "training-sessions"
                 ],
-                "summary": "Update a rank",
+                "summary": "Reorder a session waitlist",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rank ID",
+                        "description": "Training session ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Rank data",
-                        "name": "rank",
+                        "description": "Enrollment IDs in their new order",
+                        "name": "waitlist",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateRankRequest_T"
+                            "$ref": "#/definitions/main.ReorderWaitlistRequest_T"
                         }
                     }
                 ],
@@ -1770,6 +5405,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1788,29 +5435,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/regions": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of regions with optional filtering",
+                ]
+            }
+        },
+        "/v1/training/status": {
+            "get": {
+                "description": "Retrieve a list of training statuses with optional filtering by status name",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "regions"
+                    "training-statuses"
                 ],
-                "summary": "List regions",
+                "summary": "List training statuses",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by region name",
-                        "name": "region",
+                        "description": "Filter by status name",
+                        "name": "status",
                         "in": "query"
                     },
                     {
@@ -1830,6 +5478,12 @@ const docTemplate = `{
                         "description": "Sort order",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1851,15 +5505,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Create a new region",
+                ]
+            },
+            "post": {
+                "description": "Create a new training status",
                 "consumes": [
                     "application/json"
                 ],
@@ -1867,17 +5521,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "regions"
+                    "training-statuses"
                 ],
-                "summary": "Create a new region",
+                "summary": "Create a new training status",
                 "parameters": [
                     {
-                        "description": "Region data",
-                        "name": "region",
+                        "description": "Training status data",
+                        "name": "training_status",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateRegionRequest_T"
+                            "$ref": "#/definitions/main.CreateTrainingStatusRequest_T"
                         }
                     }
                 ],
@@ -1906,28 +5560,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/regions/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a region by its ID",
+                ]
+            }
+        },
+        "/v1/training/status/{id}": {
+            "get": {
+                "description": "Retrieve a training status by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "regions"
+                    "training-statuses"
                 ],
-                "summary": "Get a region",
+                "summary": "Get a training status",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Region ID",
+                        "description": "Training status ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1952,80 +5606,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Perform a partial update on a region record",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "regions"
-                ],
-                "summary": "Update a region",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Region ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Region data",
-                        "name": "region",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateRegionRequest_T"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/main.envelope"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/v1/tokens/authentication": {
-            "post": {
-                "description": "Generates an authentication token for a user based on provided email and password.",
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a training status record",
                 "consumes": [
                     "application/json"
                 ],
@@ -2033,75 +5622,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "training-statuses"
                 ],
-                "summary": "Create Authentication Token",
+                "summary": "Update a training status",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateAuthenticationTokenRequest_T"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/main.envelope"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/v1/tokens/password-reset": {
-            "post": {
-                "description": "Generates a password reset token and sends it to the user's email address.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Tokens"
-                ],
-                "summary": "Create Password Reset Token",
-                "parameters": [
+                        "type": "integer",
+                        "description": "Training status ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
-                        "description": "User email",
-                        "name": "input",
+                        "description": "Training status data",
+                        "name": "training_status",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreatePasswordResetTokenRequest_T"
+                            "$ref": "#/definitions/main.UpdateTrainingStatusRequest_T"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/main.envelope"
                         }
@@ -2112,6 +5656,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -2124,35 +5674,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/training/categories": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of training categories with optional filtering by name and active status",
+                ]
+            }
+        },
+        "/v1/training/types": {
+            "get": {
+                "description": "Retrieve a list of training types with optional filtering by type name",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "training-categories"
+                    "training-types"
                 ],
-                "summary": "List training categories",
+                "summary": "List training types",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by category name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Filter by active status",
-                        "name": "is_active",
+                        "description": "Filter by training type name",
+                        "name": "type",
                         "in": "query"
                     },
                     {
@@ -2172,6 +5717,12 @@ const docTemplate = `{
                         "description": "Sort order",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2193,15 +5744,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Create a new training category",
+                ]
+            },
+            "post": {
+                "description": "Create a new training type",
                 "consumes": [
                     "application/json"
                 ],
@@ -2209,17 +5760,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-categories"
+                    "training-types"
                 ],
-                "summary": "Create a new training category",
+                "summary": "Create a new training type",
                 "parameters": [
                     {
-                        "description": "Training category data",
-                        "name": "training_category",
+                        "description": "Training type data",
+                        "name": "training_type",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateTrainingCategoryRequest_T"
+                            "$ref": "#/definitions/main.CreateTrainingTypeRequest_T"
                         }
                     }
                 ],
@@ -2248,28 +5799,28 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/training/categories/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a training category by its ID",
+                ]
+            }
+        },
+        "/v1/training/types/{id}": {
+            "get": {
+                "description": "Retrieve a training type by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-categories"
+                    "training-types"
                 ],
-                "summary": "Get a training category",
+                "summary": "Get a training type",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Training category ID",
+                        "description": "Training type ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2294,15 +5845,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Perform a partial update on a training category record",
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a training type record",
                 "consumes": [
                     "application/json"
                 ],
@@ -2310,24 +5861,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-categories"
+                    "training-types"
                 ],
-                "summary": "Update a training category",
+                "summary": "Update a training type",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Training category ID",
+                        "description": "Training type ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Training category data",
-                        "name": "training_category",
+                        "description": "Training type data",
+                        "name": "training_type",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateTrainingCategoryRequest_T"
+                            "$ref": "#/definitions/main.UpdateTrainingTypeRequest_T"
                         }
                     }
                 ],
@@ -2362,47 +5913,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/training/status": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of training statuses with optional filtering by status name",
+                ]
+            }
+        },
+        "/v1/users": {
+            "get": {
+                "description": "Retrieve a list of users with optional filters and pagination",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "training-statuses"
+                    "users"
                 ],
-                "summary": "List training statuses",
+                "summary": "List users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by status name",
-                        "name": "status",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort order",
-                        "name": "sort",
+                        "description": "Set to csv to export every matching row",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
@@ -2413,27 +5947,21 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Create a new training status",
+                ]
+            },
+            "post": {
+                "description": "Create a new user account and send an activation email. Only available when open registration is enabled, for emails at the allowed domains.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2441,17 +5969,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-statuses"
+                    "users"
                 ],
-                "summary": "Create a new training status",
+                "summary": "Register a new user",
                 "parameters": [
                     {
-                        "description": "Training status data",
-                        "name": "training_status",
+                        "description": "User registration data",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateTrainingStatusRequest_T"
+                            "$ref": "#/definitions/main.registerUserRequest"
                         }
                     }
                 ],
@@ -2468,6 +5996,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -2483,28 +6017,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/training/status/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/v1/users/activate": {
+            "put": {
+                "description": "Activate a user account using an activation token",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve a training status by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-statuses"
+                    "users"
                 ],
-                "summary": "Get a training status",
+                "summary": "Activate a user account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Training status ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Activation token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.activateUserRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -2514,8 +6048,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -2527,14 +6067,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Perform a partial update on a training status record",
+            }
+        },
+        "/v1/users/email/cancel": {
+            "put": {
+                "description": "Cancel a pending email address change with the token emailed to the current address. The confirmation token sent to the new address stops working.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2542,24 +6079,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-statuses"
+                    "users"
                 ],
-                "summary": "Update a training status",
+                "summary": "Cancel an email change",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Training status ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Training status data",
-                        "name": "training_status",
+                        "description": "Token from the email change notice",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateTrainingStatusRequest_T"
+                            "$ref": "#/definitions/main.EmailChangeTokenRequest_T"
                         }
                     }
                 ],
@@ -2576,12 +6106,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -2597,45 +6121,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/training/types": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/v1/users/email/confirm": {
+            "put": {
+                "description": "Confirm a new email address with the token emailed to it. The user's email is changed and the tokens sent for the change stop working.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve a list of training types with optional filtering by type name",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-types"
+                    "users"
                 ],
-                "summary": "List training types",
+                "summary": "Confirm an email change",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by training type name",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort order",
-                        "name": "sort",
-                        "in": "query"
+                        "description": "Token from the confirmation email",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.EmailChangeTokenRequest_T"
+                        }
                     }
                 ],
                 "responses": {
@@ -2645,6 +6152,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -2658,14 +6171,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Create a new training type",
+            }
+        },
+        "/v1/users/password-reset": {
+            "put": {
+                "description": "Resets a user's password using a valid password reset token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2673,23 +6183,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "training-types"
+                    "Tokens"
                 ],
-                "summary": "Create a new training type",
+                "summary": "Reset Password",
                 "parameters": [
                     {
-                        "description": "Training type data",
-                        "name": "training_type",
+                        "description": "Password reset data",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateTrainingTypeRequest_T"
+                            "$ref": "#/definitions/main.ResetPasswordRequest_T"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/main.envelope"
                         }
@@ -2715,25 +6225,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/training/types/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Retrieve a training type by its ID",
+        "/v1/users/{id}": {
+            "delete": {
+                "description": "Delete a user record by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-types"
+                    "users"
                 ],
-                "summary": "Get a training type",
+                "summary": "Delete a user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Training type ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2743,7 +6248,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.envelope"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "404": {
@@ -2758,41 +6275,29 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Perform a partial update on a training type record",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            },
+            "patch": {
+                "description": "Perform a partial update on a user record. A new email is not applied straight away: the new address is sent a token to confirm it and the user keeps their current email until then.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "training-types"
+                    "users"
                 ],
-                "summary": "Update a training type",
+                "summary": "Update a user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Training type ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Training type data",
-                        "name": "training_type",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateTrainingTypeRequest_T"
-                        }
                     }
                 ],
                 "responses": {
@@ -2808,12 +6313,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
                     "422": {
                         "description": "Unprocessable Entity",
                         "schema": {
@@ -2826,29 +6325,74 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/users": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Retrieve a list of users with optional filters and pagination",
+                ]
+            }
+        },
+        "/v1/users/{id}/facilitation.ics": {
+            "get": {
+                "description": "Publish the sessions a user facilitates as an RFC 5545 iCalendar feed, limited to the regions and formations of the caller's scope. Calendar apps can subscribe by passing a calendar token in the token query parameter.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "users"
+                    "calendar"
+                ],
+                "summary": "Facilitator calendar feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Calendar token, for subscribing without an Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest session date (YYYY-MM-DD, default a year ago)",
+                        "name": "from",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.envelope"
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
                         }
                     },
                     "500": {
@@ -2857,46 +6401,42 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "post": {
-                "description": "Create a new user account and send an activation email",
-                "consumes": [
-                    "application/json"
-                ],
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/v1/users/{id}/roles": {
+            "get": {
+                "description": "Retrieve the names of the roles held by a user, and under assignments the region or formation each role is limited to",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "roles"
                 ],
-                "summary": "Register a new user",
+                "summary": "List a user's roles",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.registerUserRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.errorResponse"
-                        }
-                    },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -2907,12 +6447,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/users/activate": {
-            "put": {
-                "description": "Activate a user account using an activation token",
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Give a user a list of roles by name. Set region_id or formation_id to limit the roles to that region or formation; roles the user already holds take on the new scope.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2920,18 +6463,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "roles"
                 ],
-                "summary": "Activate a user account",
+                "summary": "Assign roles to a user",
                 "parameters": [
                     {
-                        "description": "Activation token",
-                        "name": "token",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.activateUserRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2941,8 +6482,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -2959,12 +6500,15 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/users/password-reset": {
-            "put": {
-                "description": "Resets a user's password using a valid password reset token.",
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Take a list of roles away from a user. The Admin role cannot be taken from the last active admin.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2972,18 +6516,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tokens"
+                    "roles"
                 ],
-                "summary": "Reset Password",
+                "summary": "Unassign roles from a user",
                 "parameters": [
                     {
-                        "description": "Password reset data",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.ResetPasswordRequest_T"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2993,8 +6535,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -3011,24 +6559,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/users/{id}": {
-            "delete": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Delete a user record by ID",
+                ]
+            }
+        },
+        "/v1/users/{id}/sessions": {
+            "delete": {
+                "description": "Revoke every authentication and refresh token held by a user, logging them out on all devices",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "sessions"
                 ],
-                "summary": "Delete a user",
+                "summary": "Force logout a user",
                 "parameters": [
                     {
                         "type": "integer",
@@ -3042,19 +6590,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/main.envelope"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "message": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/main.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
                         }
                     },
                     "404": {
@@ -3069,22 +6617,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
-                "description": "Perform a partial update on a user record",
+                ]
+            }
+        },
+        "/v1/users/{id}/unlock": {
+            "post": {
+                "description": "Lift the lock placed on an account after too many failed logins in a row and reset the failure count, so the user can log in again straight away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "users"
                 ],
-                "summary": "Update a user",
+                "summary": "Unlock a user",
                 "parameters": [
                     {
                         "type": "integer",
@@ -3101,14 +6651,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.envelope"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     },
-                    "422": {
-                        "description": "Unprocessable Entity",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.errorResponse"
                         }
@@ -3119,16 +6675,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/users/{user_id}/officer": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/users/{user_id}/officer": {
+            "get": {
                 "description": "Retrieve an officer record by their associated user ID",
                 "produces": [
                     "application/json"
@@ -3165,16 +6721,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.errorResponse"
                         }
                     }
-                }
-            }
-        },
-        "/v1/workshops": {
-            "post": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/workshops": {
+            "post": {
                 "description": "Create a new workshop",
                 "consumes": [
                     "application/json"
@@ -3231,16 +6787,16 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
-            }
-        },
-        "/v1/workshops/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
-                ],
+                ]
+            }
+        },
+        "/v1/workshops/{id}": {
+            "get": {
                 "description": "Retrieve a workshop by ID",
                 "consumes": [
                     "application/json"
@@ -3287,11 +6843,78 @@ const docTemplate = `{
                             }
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         }
     },
     "definitions": {
+        "main.AcceptInvitationRequest_T": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BulkEnrollSessionRequest_T": {
+            "type": "object",
+            "properties": {
+                "enrollment_status_id": {
+                    "type": "integer"
+                },
+                "formation_id": {
+                    "type": "integer"
+                },
+                "officer_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "override_schedule_clash": {
+                    "type": "boolean"
+                },
+                "posting_id": {
+                    "type": "integer"
+                },
+                "progress_status_id": {
+                    "type": "integer"
+                },
+                "rank_id": {
+                    "type": "integer"
+                },
+                "region_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.CertificateRevocationRequest_T": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ChangeEmailRequest_T": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "main.CreateAttendanceStatusRequest_T": {
             "type": "object",
             "properties": {
@@ -3333,6 +6956,41 @@ const docTemplate = `{
                 }
             }
         },
+        "main.CreateInvitationRequest_T": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "formation_id": {
+                    "type": "integer"
+                },
+                "gender": {
+                    "type": "string"
+                },
+                "is_facilitator": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "officer": {
+                    "$ref": "#/definitions/main.InvitationOfficerRequest_T"
+                },
+                "region_id": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "main.CreateOfficerRequest_T": {
             "type": "object",
             "properties": {
@@ -3432,6 +7090,72 @@ const docTemplate = `{
                 }
             }
         },
+        "main.CreateTwoFactorAuthenticationTokenRequest_T": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "two_factor_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.EmailChangeTokenRequest_T": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.InvitationOfficerRequest_T": {
+            "type": "object",
+            "properties": {
+                "formation_id": {
+                    "type": "integer"
+                },
+                "posting_id": {
+                    "type": "integer"
+                },
+                "rank_id": {
+                    "type": "integer"
+                },
+                "region_id": {
+                    "type": "integer"
+                },
+                "regulation_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.IssueCertificateRequest_T": {
+            "type": "object",
+            "properties": {
+                "completion_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.RefreshAuthenticationTokenRequest_T": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ReorderWaitlistRequest_T": {
+            "type": "object",
+            "properties": {
+                "enrollment_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "main.ResetPasswordRequest_T": {
             "type": "object",
             "properties": {
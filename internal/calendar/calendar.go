@@ -0,0 +1,124 @@
+// Filename: internal/calendar/calendar.go
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Event statuses understood by calendar clients
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// productID identifies the application that produced the feed
+const productID = "-//Police Training//Training Calendar//EN"
+
+// Event is a single VEVENT in a feed. UID must stay the same every time the feed is produced so
+// calendar clients update events instead of duplicating them.
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Start        time.Time
+	End          time.Time
+	Status       string
+	LastModified time.Time
+}
+
+// Calendar is a named collection of events published as an iCalendar (RFC 5545) feed
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Encode produces the calendar in iCalendar format. All times are written in UTC.
+func Encode(c Calendar) []byte {
+	var buf bytes.Buffer
+	now := time.Now()
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+productID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+e.UID)
+		writeLine(&buf, "DTSTAMP:"+formatTime(now))
+		writeLine(&buf, "DTSTART:"+formatTime(e.Start))
+		writeLine(&buf, "DTEND:"+formatTime(e.End))
+		writeLine(&buf, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if e.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.Status != "" {
+			writeLine(&buf, "STATUS:"+e.Status)
+		}
+		if !e.LastModified.IsZero() {
+			writeLine(&buf, "LAST-MODIFIED:"+formatTime(e.LastModified))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// formatTime writes a time in the UTC form of the DATE-TIME value type
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// textEscaper escapes the characters with special meaning in TEXT values
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText prepares a string for use as a TEXT property value
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine writes a content line terminated by CRLF, folding it so no line exceeds 75 octets.
+// Continuation lines start with a space, and folds never split a multi-byte UTF-8 character.
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // leave room for the leading space
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// isRuneStart reports whether b is the first byte of a UTF-8 encoded character
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,169 @@
+// Filename: internal/calendar/calendar_test.go
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Plain text", input: "Firearms Refresher", expected: "Firearms Refresher"},
+		{name: "Backslash", input: `C:\range`, expected: `C:\\range`},
+		{name: "Semicolon and comma", input: "Belmopan; Room 1, Block A", expected: `Belmopan\; Room 1\, Block A`},
+		{name: "LF newline", input: "line one\nline two", expected: `line one\nline two`},
+		{name: "CRLF newline", input: "line one\r\nline two", expected: `line one\nline two`},
+		{name: "CR newline", input: "line one\rline two", expected: `line one\nline two`},
+		{name: "Escapes are not applied twice", input: `a\;b`, expected: `a\\\;b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.input); got != tt.expected {
+				t.Errorf("Expected %q; got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		lines int
+	}{
+		{name: "Short line", input: "SUMMARY:Short", lines: 1},
+		{name: "Exactly 75 octets", input: strings.Repeat("a", 75), lines: 1},
+		{name: "76 octets", input: strings.Repeat("a", 76), lines: 2},
+		{name: "Long ASCII line", input: "DESCRIPTION:" + strings.Repeat("x", 300), lines: 5},
+		{name: "Multi-byte characters on the fold", input: "SUMMARY:" + strings.Repeat("é", 100), lines: 3},
+		{name: "Four byte characters", input: "LOCATION:" + strings.Repeat("🚓", 40), lines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeLine(&buf, tt.input)
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("Expected output to end with CRLF; got %q", out)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("Expected %d lines; got %d", tt.lines, len(lines))
+			}
+
+			var unfolded strings.Builder
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("Line %d is %d octets long", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("Line %d splits a UTF-8 character: %q", i, line)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(line, " ") {
+						t.Fatalf("Continuation line %d does not start with a space: %q", i, line)
+					}
+					line = line[1:]
+				}
+				unfolded.WriteString(line)
+			}
+
+			if unfolded.String() != tt.input {
+				t.Errorf("Unfolding did not restore the line: got %q", unfolded.String())
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2025, 3, 14, 9, 0, 0, 0, time.FixedZone("CST", -6*60*60))
+
+	tests := []struct {
+		name     string
+		calendar Calendar
+		expected []string
+		missing  []string
+	}{
+		{
+			name:     "Empty calendar",
+			calendar: Calendar{},
+			expected: []string{"BEGIN:VCALENDAR\r\n", "VERSION:2.0\r\n", "PRODID:" + productID + "\r\n", "END:VCALENDAR\r\n"},
+			missing:  []string{"X-WR-CALNAME", "BEGIN:VEVENT"},
+		},
+		{
+			name: "Event times are written in UTC",
+			calendar: Calendar{
+				Name: "Training, Belize",
+				Events: []Event{{
+					UID:     "session-1@police-training",
+					Summary: "Use of Force",
+					Start:   start,
+					End:     start.Add(2 * time.Hour),
+					Status:  StatusConfirmed,
+				}},
+			},
+			expected: []string{
+				"X-WR-CALNAME:Training\\, Belize\r\n",
+				"UID:session-1@police-training\r\n",
+				"DTSTART:20250314T150000Z\r\n",
+				"DTEND:20250314T170000Z\r\n",
+				"STATUS:CONFIRMED\r\n",
+			},
+			missing: []string{"DESCRIPTION:", "LOCATION:", "LAST-MODIFIED:"},
+		},
+		{
+			name: "Optional properties are written when set",
+			calendar: Calendar{
+				Events: []Event{{
+					UID:          "session-2@police-training",
+					Summary:      "Cancelled; rescheduled",
+					Description:  "Bring ID",
+					Location:     "Range",
+					Start:        start,
+					End:          start.Add(time.Hour),
+					Status:       StatusCancelled,
+					LastModified: start,
+				}},
+			},
+			expected: []string{
+				"SUMMARY:Cancelled\\; rescheduled\r\n",
+				"DESCRIPTION:Bring ID\r\n",
+				"LOCATION:Range\r\n",
+				"STATUS:CANCELLED\r\n",
+				"LAST-MODIFIED:20250314T150000Z\r\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := string(Encode(tt.calendar))
+
+			for _, want := range tt.expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("Expected output to contain %q", want)
+				}
+			}
+			for _, unwanted := range tt.missing {
+				if strings.Contains(out, unwanted) {
+					t.Errorf("Expected output not to contain %q", unwanted)
+				}
+			}
+
+			if got := strings.Count(out, "BEGIN:VEVENT"); got != len(tt.calendar.Events) {
+				t.Errorf("Expected %d events; got %d", len(tt.calendar.Events), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Filename: internal/certificate/certificate.go
+package certificate
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+//go:embed "templates/*"
+var templateFS embed.FS // embed the templates directory
+
+// Certificate holds the details printed on a training certificate
+type Certificate struct {
+	OfficerName      string
+	RegulationNumber string
+	Rank             string
+	Workshop         string
+	CreditHours      int
+	CompletionDate   time.Time
+	Number           string
+}
+
+// templateFuncs are the drawing helpers available to the content stream template
+var templateFuncs = template.FuncMap{
+	"centered": centered,
+	"centerAt": centerAt,
+	"text":     text,
+	"date": func(t time.Time) string {
+		return t.Format("2 January 2006")
+	},
+}
+
+// Render produces the certificate as a PDF document.
+func Render(c Certificate) ([]byte, error) {
+	tmpl, err := template.New("certificate.tmpl").Funcs(templateFuncs).ParseFS(templateFS, "templates/certificate.tmpl") // parse the content template
+	if err != nil {
+		return nil, err
+	}
+
+	content := new(bytes.Buffer)
+	err = tmpl.Execute(content, c) // lay out the page
+	if err != nil {
+		return nil, err
+	}
+
+	return writePDF(content.Bytes()), nil
+}
+
+// centered draws s horizontally centred on the page with its baseline at y.
+func centered(font string, size, y float64, s string) string {
+	return centerAt(font, size, pageWidth/2, y, s)
+}
+
+// centerAt draws s horizontally centred on cx with its baseline at y.
+func centerAt(font string, size, cx, y float64, s string) string {
+	x := cx - textWidth(font, size, s)/2
+	return text(font, size, x, y, s)
+}
+
+// text draws s with its baseline starting at x, y.
+func text(font string, size, x, y float64, s string) string {
+	return fmt.Sprintf("BT /%s %g Tf %.2f %.2f Td %s Tj ET", font, size, x, y, encodeText(s))
+}
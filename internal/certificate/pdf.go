@@ -0,0 +1,122 @@
+// Filename: internal/certificate/pdf.go
+package certificate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page size of an A4 sheet in landscape, in PDF points
+const (
+	pageWidth  = 842.0
+	pageHeight = 595.0
+)
+
+// fonts maps the resource names used by the content template to the standard PDF fonts. Standard fonts
+// are built into every PDF reader, so nothing needs to be embedded in the document.
+var fonts = []struct {
+	name     string
+	baseFont string
+}{
+	{"F1", "Helvetica"},
+	{"F2", "Helvetica-Bold"},
+	{"F3", "Helvetica-Oblique"},
+}
+
+// writePDF assembles a single page PDF document around the given content stream.
+func writePDF(content []byte) []byte {
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+
+	// addObject writes the next numbered object and remembers where it starts for the xref table
+	addObject := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	fontRefs := make([]string, len(fonts))
+	for i, font := range fonts {
+		fontRefs[i] = fmt.Sprintf("/%s %d 0 R", font.name, 5+i)
+	}
+
+	addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	addObject("<< /Type /Pages /Kids [3 0 R] /Count 1 >>")
+	addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << %s >> >> /Contents 4 0 R >>",
+		pageWidth, pageHeight, strings.Join(fontRefs, " ")))
+	addObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	for _, font := range fonts {
+		addObject(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.baseFont))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// encodeText converts s to a PDF literal string in WinAnsiEncoding. Characters outside Latin-1 are
+// replaced with '?' since the standard fonts cannot draw them.
+func encodeText(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// textWidth returns the width of s in points when set in the given font and size.
+func textWidth(font string, size float64, s string) float64 {
+	widths := helveticaWidths
+	if font == "F2" {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			total += widths[r-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Glyph widths of the printable ASCII characters, in thousandths of the font size, taken from the
+// Adobe font metrics. Oblique shares the regular widths.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
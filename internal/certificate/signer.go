@@ -0,0 +1,114 @@
+// Filename: internal/certificate/signer.go
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a certificate number's signature does not match its base number
+var ErrInvalidSignature = errors.New("invalid certificate signature")
+
+// signatureSeparator joins the base certificate number and its encoded signature
+const signatureSeparator = "."
+
+// Signer appends Ed25519 signatures to certificate numbers so tampering can be detected by anyone
+// holding the public key, without access to the database.
+type Signer struct {
+	privateKey ed25519.PrivateKey
+}
+
+// LoadSigner reads a PEM encoded PKCS #8 Ed25519 private key, such as one generated with
+// `openssl genpkey -algorithm ed25519`.
+func LoadSigner(path string) (*Signer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: no PEM encoded private key found", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 private key", path)
+	}
+
+	return NewSigner(privateKey), nil
+}
+
+// NewSigner returns a Signer using the given private key.
+func NewSigner(privateKey ed25519.PrivateKey) *Signer {
+	return &Signer{privateKey: privateKey}
+}
+
+// PublicKey returns the key used to verify signed certificate numbers.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.privateKey.Public().(ed25519.PublicKey)
+}
+
+// PublicKeyPEM returns the verification key as a PEM encoded PKIX public key.
+func (s *Signer) PublicKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(s.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Sign returns the certificate number with its signature appended, e.g. "BPD-2026-0001.<signature>".
+func (s *Signer) Sign(number string) string {
+	signature := ed25519.Sign(s.privateKey, []byte(number))
+	return number + signatureSeparator + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// HasSignature reports whether a certificate number ends in something shaped like a signature. Numbers
+// issued before signing was enabled do not.
+func HasSignature(number string) bool {
+	i := strings.LastIndex(number, signatureSeparator)
+	if i < 0 {
+		return false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(number[i+len(signatureSeparator):])
+	return err == nil && len(signature) == ed25519.SignatureSize
+}
+
+// Verify checks a signed certificate number against the public key and returns the base number.
+func Verify(publicKey ed25519.PublicKey, signed string) (string, error) {
+	i := strings.LastIndex(signed, signatureSeparator)
+	if i < 0 {
+		return "", ErrInvalidSignature
+	}
+
+	number, encoded := signed[:i], signed[i+len(signatureSeparator):]
+
+	signature, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return "", ErrInvalidSignature
+	}
+
+	if !ed25519.Verify(publicKey, []byte(number), signature) {
+		return "", ErrInvalidSignature
+	}
+
+	return number, nil
+}
+
+// Verify checks a signed certificate number against this signer's public key.
+func (s *Signer) Verify(signed string) (string, error) {
+	return Verify(s.PublicKey(), signed)
+}
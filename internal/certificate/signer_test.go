@@ -0,0 +1,177 @@
+// Filename: internal/certificate/signer_test.go
+package certificate
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSigner returns a Signer with a freshly generated key
+func newTestSigner(t *testing.T) *Signer {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return NewSigner(privateKey)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer := newTestSigner(t)
+	other := newTestSigner(t)
+
+	signed := signer.Sign("BPD-2026-0001")
+	base, signature, _ := strings.Cut(signed, signatureSeparator)
+
+	tests := []struct {
+		name     string
+		signer   *Signer
+		number   string
+		expected string
+		wantErr  bool
+	}{
+		{name: "Signed number", signer: signer, number: signed, expected: "BPD-2026-0001"},
+		{name: "Unsigned number", signer: signer, number: "BPD-2026-0001", wantErr: true},
+		{name: "Base number changed", signer: signer, number: "BPD-2026-0002" + signatureSeparator + signature, wantErr: true},
+		{name: "Signature changed", signer: signer, number: base + signatureSeparator + strings.Repeat("A", len(signature)), wantErr: true},
+		{name: "Signature not base64", signer: signer, number: base + signatureSeparator + "!!!", wantErr: true},
+		{name: "Signature truncated", signer: signer, number: signed[:len(signed)-4], wantErr: true},
+		{name: "Different key", signer: other, number: signed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, err := tt.signer.Verify(tt.number)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Fatalf("Expected ErrInvalidSignature; got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if number != tt.expected {
+				t.Errorf("Expected %q; got %q", tt.expected, number)
+			}
+		})
+	}
+}
+
+func TestHasSignature(t *testing.T) {
+	signer := newTestSigner(t)
+	signed := signer.Sign("BPD-2026-0001")
+
+	tests := []struct {
+		name     string
+		number   string
+		expected bool
+	}{
+		{name: "Signed number", number: signed, expected: true},
+		{name: "Unsigned number", number: "BPD-2026-0001", expected: false},
+		{name: "Separator without a signature", number: "BPD-2026-0001.", expected: false},
+		{name: "Short suffix", number: "BPD-2026-0001.abc", expected: false},
+		{name: "Suffix not base64", number: "BPD-2026-0001." + strings.Repeat("!", 86), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasSignature(tt.number); got != tt.expected {
+				t.Errorf("Expected %v; got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPublicKeyPEM(t *testing.T) {
+	signer := newTestSigner(t)
+
+	encoded, err := signer.PublicKeyPEM()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	block, _ := pem.Decode(encoded)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		t.Fatalf("Expected a PEM encoded public key, got %q", encoded)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		t.Fatalf("Expected an Ed25519 public key, got %T", key)
+	}
+
+	// The published key verifies numbers without the signer
+	if _, err := Verify(publicKey, signer.Sign("BPD-2026-0001")); err != nil {
+		t.Errorf("Expected the published key to verify a signed number: %v", err)
+	}
+}
+
+func TestLoadSigner(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, contents []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, contents, 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatalf("Failed to encode public key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "PKCS #8 Ed25519 key", path: write("signing.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))},
+		{name: "Missing file", path: filepath.Join(dir, "missing.pem"), wantErr: true},
+		{name: "Not PEM", path: write("garbage.pem", []byte("not a key")), wantErr: true},
+		{name: "Public key instead of private", path: write("public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})), wantErr: true},
+		{name: "Corrupt key bytes", path: write("corrupt.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("corrupt")})), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := LoadSigner(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !signer.PublicKey().Equal(privateKey.Public()) {
+				t.Error("Expected the loaded signer to use the key from the file")
+			}
+		})
+	}
+}
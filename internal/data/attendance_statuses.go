@@ -92,7 +92,7 @@ func (m AttendanceStatusModel) GetAll(name string, countsAsPresent *bool, filter
 	query := fmt.Sprintf(`
 		SELECT COUNT(*) OVER(), id, status, counts_as_present
 		FROM attendance_statuses
-		WHER (to_tsvector('simple', status) @@ plainto_tsquery('simple', $1) OR $1 = '')
+		WHERE (to_tsvector('simple', status) @@ plainto_tsquery('simple', $1) OR $1 = '')
 		AND ($2::boolean IS NULL OR counts_as_present = $2)
 		ORDER BY %s %s, id ASC
 		LIMIT $3 OFFSET $4`, filters.sortColumn(), filters.sortDirection())
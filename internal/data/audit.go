@@ -0,0 +1,264 @@
+// FileName: internal/data/audit.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/************************************************************************************************************/
+// Audit Declarations
+/************************************************************************************************************/
+
+// Kinds of entity recorded in the audit log
+const (
+	AuditEntityUser               = "user"
+	AuditEntityOfficer            = "officer"
+	AuditEntityRegion             = "region"
+	AuditEntityFormation          = "formation"
+	AuditEntityPosting            = "posting"
+	AuditEntityRank               = "rank"
+	AuditEntityWorkshop           = "workshop"
+	AuditEntityTrainingCategory   = "training_category"
+	AuditEntityTrainingType       = "training_type"
+	AuditEntityTrainingStatus     = "training_status"
+	AuditEntityEnrollmentStatus   = "enrollment_status"
+	AuditEntityAttendanceStatus   = "attendance_status"
+	AuditEntityProgressStatus     = "progress_status"
+	AuditEntityTrainingSession    = "training_session"
+	AuditEntityTrainingEnrollment = "training_enrollment"
+	AuditEntityCalendarToken      = "calendar_token"
+	AuditEntityRole               = "role"
+	AuditEntitySession            = "session"
+	AuditEntityInvitation         = "invitation"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionCreate             = "create"
+	AuditActionUpdate             = "update"
+	AuditActionDelete             = "delete"
+	AuditActionRestore            = "restore"
+	AuditActionActivate           = "activate"
+	AuditActionPasswordChange     = "password_change"
+	AuditActionReorderWaitlist    = "reorder_waitlist"
+	AuditActionIssueCertificate   = "issue_certificate"
+	AuditActionRevokeCertificate  = "revoke_certificate"
+	AuditActionReissueCertificate = "reissue_certificate"
+	AuditActionRevokeSessions     = "revoke_sessions"
+	AuditActionUnlock             = "unlock"
+	AuditActionSetupTwoFactor     = "setup_two_factor"
+	AuditActionEnableTwoFactor    = "enable_two_factor"
+	AuditActionDisableTwoFactor   = "disable_two_factor"
+	AuditActionRegenerateRecovery = "regenerate_recovery_codes"
+	AuditActionAcceptInvitation   = "accept_invitation"
+	AuditActionRequestEmailChange = "request_email_change"
+	AuditActionConfirmEmailChange = "confirm_email_change"
+	AuditActionCancelEmailChange  = "cancel_email_change"
+)
+
+// auditIgnoredFields change on every update, so they are left out of update diffs
+var auditIgnoredFields = []string{"updated_at", "version"}
+
+// AuditEvent is a single change recorded in the audit log. Changes holds the fields that changed,
+// as {"before": {...}, "after": {...}}; before is null for creations and after is null for deletions.
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    *int64          `json:"actor_id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int64           `json:"entity_id"`
+	Action     string          `json:"action"`
+	Changes    json.RawMessage `json:"changes"`
+	RequestID  string          `json:"request_id"`
+	IPAddress  string          `json:"ip_address"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilters narrows the events returned by GetAll. From and To are inclusive dates.
+type AuditFilters struct {
+	ActorID    *int64
+	EntityType string
+	EntityID   *int64
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditModel wraps the database connection pool for the audit log
+type AuditModel struct {
+	DB *sql.DB
+}
+
+/************************************************************************************************************/
+// Diffs
+/************************************************************************************************************/
+
+// AuditChanges builds the changes recorded for an event from the entity before and after it changed.
+// Either side may be nil. Both are compared by their JSON form, so fields hidden from the API, such
+// as password hashes, never reach the audit log.
+func AuditChanges(before, after any) (json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creations and deletions record the whole entity
+	if beforeFields == nil || afterFields == nil {
+		return json.Marshal(map[string]any{"before": beforeFields, "after": afterFields})
+	}
+
+	for _, field := range auditIgnoredFields {
+		delete(beforeFields, field)
+		delete(afterFields, field)
+	}
+
+	changedBefore := map[string]any{}
+	changedAfter := map[string]any{}
+
+	for field, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[field]) {
+			changedBefore[field] = value
+			changedAfter[field] = afterFields[field]
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changedBefore[field] = nil
+			changedAfter[field] = value
+		}
+	}
+
+	return json.Marshal(map[string]any{"before": changedBefore, "after": changedAfter})
+}
+
+// auditFields converts an entity to its JSON fields, returning nil for a nil entity
+func auditFields(entity any) (map[string]any, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	if v := reflect.ValueOf(entity); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, nil
+	}
+
+	js, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return nil, fmt.Errorf("audit: entity must encode as a JSON object: %w", err)
+	}
+
+	return fields, nil
+}
+
+/************************************************************************************************************/
+// Audit Model Methods
+/************************************************************************************************************/
+
+// Insert records an event in the audit log
+func (m *AuditModel) Insert(event *AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (actor_id, entity_type, entity_id, action, changes, request_id, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	changes := event.Changes
+	if changes == nil {
+		changes = json.RawMessage(`{}`)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query,
+		event.ActorID,
+		event.EntityType,
+		event.EntityID,
+		event.Action,
+		[]byte(changes),
+		event.RequestID,
+		event.IPAddress,
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// GetAll returns audit events matching the filters
+func (m *AuditModel) GetAll(auditFilters AuditFilters, filters Filters) ([]*AuditEvent, MetaData, error) {
+	if filters.Sort == "" {
+		filters.Sort = "-created_at"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) OVER(), id, actor_id, entity_type, entity_id, action, changes, request_id, ip_address, created_at
+		FROM audit_events
+		WHERE (actor_id = $1 OR $1 IS NULL)
+		AND (entity_type = $2 OR $2 = '')
+		AND (entity_id = $3 OR $3 IS NULL)
+		AND (action = $4 OR $4 = '')
+		AND ($5::date IS NULL OR created_at >= $5::date)
+		AND ($6::date IS NULL OR created_at < $6::date + 1)
+		ORDER BY %s %s, id DESC
+		LIMIT $7 OFFSET $8`, filters.sortColumn(), filters.sortDirection())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query,
+		auditFilters.ActorID,
+		auditFilters.EntityType,
+		auditFilters.EntityID,
+		auditFilters.Action,
+		auditFilters.From,
+		auditFilters.To,
+		filters.limit(),
+		filters.offset(),
+	)
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	events := []*AuditEvent{}
+
+	for rows.Next() {
+		var event AuditEvent
+		var changes []byte
+
+		err := rows.Scan(
+			&totalRecords,
+			&event.ID,
+			&event.ActorID,
+			&event.EntityType,
+			&event.EntityID,
+			&event.Action,
+			&changes,
+			&event.RequestID,
+			&event.IPAddress,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, MetaData{}, err
+		}
+
+		event.Changes = changes
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return events, metadata, nil
+}
@@ -0,0 +1,160 @@
+// FileName: internal/data/bulk_enrollments.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+/************************************************************************************************************/
+// Bulk Enrollment Declarations
+/************************************************************************************************************/
+
+// Outcomes reported for each officer in a bulk enrollment
+const (
+	BulkEnrollmentEnrolled        = "enrolled"
+	BulkEnrollmentWaitlisted      = "waitlisted"
+	BulkEnrollmentAlreadyEnrolled = "already_enrolled"
+	BulkEnrollmentRejected        = "rejected"
+)
+
+// MaxBulkEnrollment caps the number of officers enrolled by one bulk request
+const MaxBulkEnrollment = 500
+
+// BulkEnrollmentResult is the outcome of enrolling one officer during a bulk enrollment
+type BulkEnrollmentResult struct {
+	OfficerID        int64  `json:"officer_id"`
+	Status           string `json:"status"`
+	EnrollmentID     *int64 `json:"enrollment_id,omitempty"`
+	WaitlistPosition *int   `json:"waitlist_position,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// BulkInsert enrolls the given officers into a session in a single transaction. Each officer is handled
+// like Insert: officers are waitlisted once the session is full, and those with a schedule clash are
+// rejected unless allowScheduleClash is set. A zero statusID or progressID defaults to
+// Enrolled and Not Started; callers are expected to have checked that non-zero IDs exist. Problems with
+// one officer are reported in their result rather than aborting the others; ErrForeignKeyViolation is
+// returned when the session does not exist.
+func (m *TrainingEnrollmentModel) BulkInsert(sessionID, statusID, progressID int64, officerIDs []int64, allowScheduleClash bool) ([]*BulkEnrollmentResult, error) {
+	// Every officer takes several statements, so the whole batch gets a longer deadline than a single insert
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the session up front so the whole batch allocates seats in one go. As in Insert, the session is
+	// locked before any officer is
+	if _, err := lockSession(ctx, tx, sessionID); err != nil {
+		return nil, err
+	}
+
+	if statusID == 0 {
+		statusID, err = enrollmentStatusID(ctx, tx, EnrollmentStatusEnrolled)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if progressID == 0 {
+		err = tx.QueryRowContext(ctx, `SELECT id FROM progress_statuses WHERE status = $1`, ProgressStatusNotStarted).Scan(&progressID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := []*BulkEnrollmentResult{}
+	seen := make(map[int64]bool, len(officerIDs))
+
+	for _, officerID := range officerIDs {
+		if seen[officerID] {
+			continue
+		}
+		seen[officerID] = true
+
+		result, err := bulkInsertOfficer(ctx, tx, &TrainingEnrollment{
+			OfficerID:          officerID,
+			SessionID:          sessionID,
+			EnrollmentStatusID: statusID,
+			ProgressStatusID:   progressID,
+		}, allowScheduleClash)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// bulkInsertOfficer enrolls one officer within a bulk enrollment. The insert runs under a savepoint so
+// a rejected officer does not abort the rest of the transaction.
+func bulkInsertOfficer(ctx context.Context, tx *sql.Tx, enrollment *TrainingEnrollment, allowScheduleClash bool) (*BulkEnrollmentResult, error) {
+	result := &BulkEnrollmentResult{OfficerID: enrollment.OfficerID}
+
+	var existingID int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM training_enrollments
+		WHERE officer_id = $1 AND session_id = $2`, enrollment.OfficerID, enrollment.SessionID).Scan(&existingID)
+	switch {
+	case err == nil:
+		result.Status = BulkEnrollmentAlreadyEnrolled
+		result.EnrollmentID = &existingID
+		return result, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_enrollment`); err != nil {
+		return nil, err
+	}
+
+	err = insertEnrollment(ctx, tx, enrollment, allowScheduleClash)
+	if err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT bulk_enrollment`); rollbackErr != nil {
+			return nil, rollbackErr
+		}
+
+		var clash *ScheduleClashError
+		switch {
+		case errors.As(err, &clash):
+			result.Status = BulkEnrollmentRejected
+			result.Reason = clash.Error()
+		case errors.Is(err, ErrSessionFull):
+			result.Status = BulkEnrollmentRejected
+			result.Reason = "the training session has reached its maximum capacity"
+		case errors.Is(err, ErrDuplicateValue):
+			result.Status = BulkEnrollmentAlreadyEnrolled
+		case errors.Is(err, ErrForeignKeyViolation):
+			result.Status = BulkEnrollmentRejected
+			result.Reason = "officer not found"
+		default:
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT bulk_enrollment`); err != nil {
+		return nil, err
+	}
+
+	result.Status = BulkEnrollmentEnrolled
+	if enrollment.WaitlistPosition != nil {
+		result.Status = BulkEnrollmentWaitlisted
+		result.WaitlistPosition = enrollment.WaitlistPosition
+	}
+	result.EnrollmentID = &enrollment.ID
+
+	return result, nil
+}
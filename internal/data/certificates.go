@@ -0,0 +1,493 @@
+// FileName: internal/data/certificates.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+/************************************************************************************************************/
+// Certificate Declarations
+/************************************************************************************************************/
+
+// CertificateDetails gathers what is printed on the certificate of a completed enrollment
+type CertificateDetails struct {
+	EnrollmentID      int64     `json:"enrollment_id"`
+	FirstName         string    `json:"first_name"`
+	LastName          string    `json:"last_name"`
+	RegulationNumber  string    `json:"regulation_number"`
+	Rank              string    `json:"rank"`
+	WorkshopName      string    `json:"workshop_name"`
+	CreditHours       int       `json:"credit_hours"`
+	CompletionDate    time.Time `json:"completion_date"`
+	CertificateNumber string    `json:"certificate_number"`
+}
+
+// CertificateVerification is the minimal, public view of an issued certificate
+type CertificateVerification struct {
+	CertificateNumber string    `json:"certificate_number"`
+	OfficerName       string    `json:"officer_name"`
+	Rank              string    `json:"rank"`
+	WorkshopName      string    `json:"workshop_name"`
+	CreditHours       int       `json:"credit_hours"`
+	CompletionDate    time.Time `json:"completion_date"`
+}
+
+// CertificateRevocation records a revoked certificate number and, once reissued, its replacement
+type CertificateRevocation struct {
+	ID                int64     `json:"id"`
+	EnrollmentID      int64     `json:"enrollment_id"`
+	CertificateNumber string    `json:"certificate_number"`
+	Reason            string    `json:"reason"`
+	RevokedBy         *int64    `json:"revoked_by,omitempty"`
+	RevokedAt         time.Time `json:"revoked_at"`
+	ReissuedNumber    *string   `json:"reissued_number,omitempty"`
+}
+
+// CertificateNumberPrefix starts every generated certificate number
+const CertificateNumberPrefix = "BPD"
+
+// ValidateRevocationReason ensures a reason is given for revoking or reissuing a certificate.
+func ValidateRevocationReason(v *validator.Validator, reason string) {
+	v.Check(reason != "", "reason", "must be provided")
+	v.Check(len(reason) <= 500, "reason", "must not exceed 500 characters")
+}
+
+// CertificateModel struct to read certificates issued for training enrollments
+type CertificateModel struct {
+	DB *sql.DB
+}
+
+// GetForEnrollment returns the certificate details of an enrollment, or ErrCertificateNotIssued when
+// no certificate has been issued for it yet.
+func (m *CertificateModel) GetForEnrollment(enrollmentID int64) (*CertificateDetails, error) {
+	if enrollmentID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT te.id, u.first_name, u.last_name, o.regulation_number, r.rank, w.workshop_name, w.credit_hours,
+			te.completion_date, te.certificate_issued, te.certificate_number
+		FROM training_enrollments te
+		INNER JOIN officers o ON o.id = te.officer_id
+		INNER JOIN users u ON u.id = o.user_id
+		INNER JOIN ranks r ON r.id = o.rank_id
+		INNER JOIN training_sessions ts ON ts.id = te.session_id
+		INNER JOIN workshops w ON w.id = ts.workshop_id
+		WHERE te.id = $1`
+
+	var (
+		details        CertificateDetails
+		completionDate sql.NullTime
+		issued         bool
+		number         sql.NullString
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, enrollmentID).Scan(
+		&details.EnrollmentID,
+		&details.FirstName,
+		&details.LastName,
+		&details.RegulationNumber,
+		&details.Rank,
+		&details.WorkshopName,
+		&details.CreditHours,
+		&completionDate,
+		&issued,
+		&number,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if !issued || !number.Valid || number.String == "" || !completionDate.Valid {
+		return nil, ErrCertificateNotIssued
+	}
+
+	details.CompletionDate = completionDate.Time
+	details.CertificateNumber = number.String
+
+	return &details, nil
+}
+
+// GetAllForOfficer returns the certificates issued to an officer that have not been revoked, most
+// recently completed first
+func (m *CertificateModel) GetAllForOfficer(officerID int64) ([]*CertificateDetails, error) {
+	query := `
+		SELECT te.id, u.first_name, u.last_name, o.regulation_number, r.rank, w.workshop_name, w.credit_hours,
+			te.completion_date, te.certificate_number
+		FROM training_enrollments te
+		INNER JOIN officers o ON o.id = te.officer_id
+		INNER JOIN users u ON u.id = o.user_id
+		INNER JOIN ranks r ON r.id = o.rank_id
+		INNER JOIN training_sessions ts ON ts.id = te.session_id
+		INNER JOIN workshops w ON w.id = ts.workshop_id
+		WHERE te.officer_id = $1
+		AND te.certificate_issued AND NOT te.certificate_revoked
+		AND te.certificate_number IS NOT NULL AND te.certificate_number <> ''
+		AND te.completion_date IS NOT NULL
+		ORDER BY te.completion_date DESC, te.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, officerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certificates := []*CertificateDetails{}
+	for rows.Next() {
+		var details CertificateDetails
+		err := rows.Scan(
+			&details.EnrollmentID,
+			&details.FirstName,
+			&details.LastName,
+			&details.RegulationNumber,
+			&details.Rank,
+			&details.WorkshopName,
+			&details.CreditHours,
+			&details.CompletionDate,
+			&details.CertificateNumber,
+		)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, &details)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// GetByNumber looks up an issued certificate by its number for public verification.
+func (m *CertificateModel) GetByNumber(number string) (*CertificateVerification, error) {
+	query := `
+		SELECT te.certificate_number, u.first_name || ' ' || u.last_name, r.rank, w.workshop_name, w.credit_hours, te.completion_date
+		FROM training_enrollments te
+		INNER JOIN officers o ON o.id = te.officer_id
+		INNER JOIN users u ON u.id = o.user_id
+		INNER JOIN ranks r ON r.id = o.rank_id
+		INNER JOIN training_sessions ts ON ts.id = te.session_id
+		INNER JOIN workshops w ON w.id = ts.workshop_id
+		WHERE te.certificate_number = $1
+		AND te.certificate_issued = true
+		AND te.completion_date IS NOT NULL`
+
+	var verification CertificateVerification
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, number).Scan(
+		&verification.CertificateNumber,
+		&verification.OfficerName,
+		&verification.Rank,
+		&verification.WorkshopName,
+		&verification.CreditHours,
+		&verification.CompletionDate,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &verification, nil
+}
+
+// certificateState is the locked state of an enrollment used when issuing or revoking its certificate
+type certificateState struct {
+	enrollmentID     int64
+	regionID         int64
+	regionCode       string
+	present          bool
+	progressComplete bool
+	issued           bool
+	revoked          bool
+	number           sql.NullString
+	completionDate   sql.NullTime
+}
+
+// lockCertificateState locks an enrollment row and reads what is needed to issue or revoke its certificate.
+func lockCertificateState(ctx context.Context, tx *sql.Tx, enrollmentID int64) (*certificateState, error) {
+	state := certificateState{enrollmentID: enrollmentID}
+
+	// Region codes fall back to the first word of the region name, e.g. "Southern Region" becomes SOUTHERN
+	err := tx.QueryRowContext(ctx, `
+		SELECT ts.region_id,
+			COALESCE(NULLIF(r.certificate_code, ''), UPPER(regexp_replace(split_part(r.region, ' ', 1), '[^A-Za-z0-9]', '', 'g'))),
+			COALESCE(ast.counts_as_present, false),
+			ps.status = '`+ProgressStatusCompleted+`',
+			te.certificate_issued AND te.certificate_number IS NOT NULL,
+			te.certificate_revoked,
+			te.certificate_number,
+			te.completion_date
+		FROM training_enrollments te
+		INNER JOIN training_sessions ts ON ts.id = te.session_id
+		INNER JOIN regions r ON r.id = ts.region_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		LEFT JOIN attendance_statuses ast ON ast.id = te.attendance_status_id
+		WHERE te.id = $1
+		FOR UPDATE OF te`, enrollmentID).Scan(
+		&state.regionID,
+		&state.regionCode,
+		&state.present,
+		&state.progressComplete,
+		&state.issued,
+		&state.revoked,
+		&state.number,
+		&state.completionDate,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &state, nil
+}
+
+// issueCertificate checks the enrollment is eligible, takes the next number in the sequence for its
+// year and region and marks the certificate as issued.
+func issueCertificate(ctx context.Context, tx *sql.Tx, state *certificateState, issuedBy int64, completionDate time.Time, sign func(string) string) (string, error) {
+	var ineligible []error
+	if !state.present {
+		ineligible = append(ineligible, ErrAttendanceNotPresent)
+	}
+	if !state.progressComplete {
+		ineligible = append(ineligible, ErrProgressIncomplete)
+	}
+	if len(ineligible) > 0 {
+		return "", errors.Join(ineligible...)
+	}
+
+	// The upsert locks the sequence row, so concurrent issuances for the same year and region queue up
+	var sequence int
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO certificate_sequences (year, region_id, last_number)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (year, region_id) DO UPDATE SET last_number = certificate_sequences.last_number + 1
+		RETURNING last_number`, completionDate.Year(), state.regionID).Scan(&sequence)
+	if err != nil {
+		return "", err
+	}
+
+	number := fmt.Sprintf("%s-%d-%s-%06d", CertificateNumberPrefix, completionDate.Year(), state.regionCode, sequence)
+	if sign != nil {
+		number = sign(number)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE training_enrollments
+		SET certificate_issued = true, certificate_revoked = false, certificate_number = $1, completion_date = $2,
+			certificate_issued_by = $3, certificate_issued_at = NOW(), updated_at = NOW()
+		WHERE id = $4`, number, completionDate, issuedBy, state.enrollmentID)
+	if err != nil {
+		switch {
+		case isDuplicateKeyViolation(err):
+			return "", ErrDuplicateValue
+		default:
+			return "", err
+		}
+	}
+
+	return number, nil
+}
+
+// revokeCertificate records the enrollment's current certificate number in the revocation history
+// and withdraws it from the enrollment.
+func revokeCertificate(ctx context.Context, tx *sql.Tx, state *certificateState, revokedBy int64, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO certificate_revocations (enrollment_id, certificate_number, reason, revoked_by)
+		VALUES ($1, $2, $3, $4)`, state.enrollmentID, state.number.String, reason, revokedBy)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE training_enrollments
+		SET certificate_issued = false, certificate_revoked = true, certificate_number = NULL,
+			certificate_issued_by = NULL, certificate_issued_at = NULL, updated_at = NOW()
+		WHERE id = $1`, state.enrollmentID)
+	return err
+}
+
+// Revoke withdraws the certificate issued for an enrollment. The number stays in the revocation
+// history so it fails verification, and the enrollment's hours stop counting towards compliance.
+func (m *CertificateModel) Revoke(enrollmentID, revokedBy int64, reason string) (*CertificateRevocation, error) {
+	if enrollmentID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	state, err := lockCertificateState(ctx, tx, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !state.issued {
+		return nil, ErrCertificateNotIssued
+	}
+
+	if err := revokeCertificate(ctx, tx, state, revokedBy, reason); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	revocations, err := m.GetRevocations(enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return revocations[0], nil
+}
+
+// Reissue replaces the certificate of an enrollment with a newly numbered one. A certificate that is
+// still valid is revoked first with the given reason. The enrollment must still be eligible, and the
+// original completion date is kept.
+func (m *CertificateModel) Reissue(enrollmentID, issuedBy int64, reason string, sign func(string) string) (string, error) {
+	if enrollmentID < 1 {
+		return "", ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	state, err := lockCertificateState(ctx, tx, enrollmentID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case state.issued:
+		if err := revokeCertificate(ctx, tx, state, issuedBy, reason); err != nil {
+			return "", err
+		}
+	case !state.revoked:
+		return "", ErrCertificateNotIssued
+	}
+
+	completionDate := time.Now()
+	if state.completionDate.Valid {
+		completionDate = state.completionDate.Time
+	}
+
+	number, err := issueCertificate(ctx, tx, state, issuedBy, completionDate, sign)
+	if err != nil {
+		return "", err
+	}
+
+	// Link the most recent revocation to its replacement
+	_, err = tx.ExecContext(ctx, `
+		UPDATE certificate_revocations
+		SET reissued_number = $1
+		WHERE id = (
+			SELECT id FROM certificate_revocations
+			WHERE enrollment_id = $2 AND reissued_number IS NULL
+			ORDER BY revoked_at DESC, id DESC
+			LIMIT 1
+		)`, number, enrollmentID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return number, nil
+}
+
+// GetRevocations returns the revocation history of an enrollment's certificates, newest first.
+func (m *CertificateModel) GetRevocations(enrollmentID int64) ([]*CertificateRevocation, error) {
+	query := `
+		SELECT id, enrollment_id, certificate_number, reason, revoked_by, revoked_at, reissued_number
+		FROM certificate_revocations
+		WHERE enrollment_id = $1
+		ORDER BY revoked_at DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, enrollmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revocations := []*CertificateRevocation{}
+
+	for rows.Next() {
+		var revocation CertificateRevocation
+		if err := rows.Scan(
+			&revocation.ID,
+			&revocation.EnrollmentID,
+			&revocation.CertificateNumber,
+			&revocation.Reason,
+			&revocation.RevokedBy,
+			&revocation.RevokedAt,
+			&revocation.ReissuedNumber,
+		); err != nil {
+			return nil, err
+		}
+		revocations = append(revocations, &revocation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revocations, nil
+}
+
+// IsRevoked reports whether a certificate number appears in the revocation history.
+func (m *CertificateModel) IsRevoked(number string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM certificate_revocations WHERE certificate_number = $1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var revoked bool
+	err := m.DB.QueryRowContext(ctx, query, number).Scan(&revoked)
+	return revoked, err
+}
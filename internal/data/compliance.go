@@ -0,0 +1,278 @@
+// FileName: internal/data/compliance.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+)
+
+/************************************************************************************************************/
+// Compliance Declarations
+/************************************************************************************************************/
+
+// Compliance statuses reported for an officer's annual training hours
+const (
+	ComplianceStatusCompliant    = "compliant"
+	ComplianceStatusOnTrack      = "on_track"
+	ComplianceStatusAtRisk       = "at_risk"
+	ComplianceStatusNonCompliant = "non_compliant"
+)
+
+// OfficerCompliance struct to represent an officer's training hours for a single year
+type OfficerCompliance struct {
+	OfficerID        int64  `json:"officer_id"`
+	RegulationNumber string `json:"regulation_number"`
+	RankID           int64  `json:"rank_id"`
+	Rank             string `json:"rank"`
+	Year             int    `json:"year"`
+	HoursEarned      int    `json:"hours_earned"`
+	HoursRequired    int    `json:"hours_required"`
+	HoursRemaining   int    `json:"hours_remaining"`
+	Status           string `json:"status"`
+}
+
+// ComplianceModel struct to calculate training compliance from the database
+type ComplianceModel struct {
+	DB *sql.DB
+}
+
+// ValidateComplianceYear ensures the requested compliance year is sensible.
+func ValidateComplianceYear(v *validator.Validator, year int) {
+	v.Check(year >= 2000, "year", "must be 2000 or later")
+	v.Check(year <= time.Now().Year()+1, "year", "must not be more than one year in the future")
+}
+
+// complianceYearProgress reports whether the given year has ended and the fraction of it
+// that has elapsed as of now. The fraction is used to pro-rate the hours an officer should
+// have earned so far, which separates officers on track from those at risk.
+func complianceYearProgress(year int, now time.Time) (closed bool, elapsed float64) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, now.Location())
+	end := start.AddDate(1, 0, 0)
+
+	switch {
+	case !now.Before(end):
+		return true, 1
+	case now.Before(start):
+		return false, 0
+	default:
+		return false, float64(now.Sub(start)) / float64(end.Sub(start))
+	}
+}
+
+// complianceStatus classifies earned hours against the requirement for a year.
+func complianceStatus(earned, required, year int, now time.Time) string {
+	if earned >= required {
+		return ComplianceStatusCompliant
+	}
+
+	closed, elapsed := complianceYearProgress(year, now)
+	switch {
+	case closed:
+		return ComplianceStatusNonCompliant
+	case float64(earned) >= float64(required)*elapsed:
+		return ComplianceStatusOnTrack
+	default:
+		return ComplianceStatusAtRisk
+	}
+}
+
+// completedHoursQuery sums the credit hours of completed enrollments for the officer
+// referenced as o.id, bounded by the completion dates in $2 (inclusive) and $3 (exclusive).
+// Enrollments whose certificate has been revoked do not count.
+const completedHoursQuery = `
+	COALESCE((
+		SELECT SUM(w.credit_hours)
+		FROM training_enrollments te
+		INNER JOIN training_sessions ts ON ts.id = te.session_id
+		INNER JOIN workshops w ON w.id = ts.workshop_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		WHERE te.officer_id = o.id
+		AND ps.status = '` + ProgressStatusCompleted + `'
+		AND te.completion_date >= $2
+		AND te.completion_date < $3
+		AND NOT te.certificate_revoked
+	), 0)`
+
+// GetForOfficer calculates an officer's training compliance for the given year.
+func (m *ComplianceModel) GetForOfficer(officerID int64, year int) (*OfficerCompliance, error) {
+	if officerID < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT o.id, o.regulation_number, r.id, r.rank, r.annual_training_hours,` + completedHoursQuery + `
+		FROM officers o
+		INNER JOIN ranks r ON r.id = o.rank_id
+		WHERE o.id = $1`
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+
+	compliance := OfficerCompliance{Year: year}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, officerID, yearStart, yearEnd).Scan(
+		&compliance.OfficerID,
+		&compliance.RegulationNumber,
+		&compliance.RankID,
+		&compliance.Rank,
+		&compliance.HoursRequired,
+		&compliance.HoursEarned,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	compliance.HoursRemaining = max(compliance.HoursRequired-compliance.HoursEarned, 0)
+	compliance.Status = complianceStatus(compliance.HoursEarned, compliance.HoursRequired, year, time.Now())
+
+	return &compliance, nil
+}
+
+// Compliance rollup groupings
+const (
+	ComplianceGroupRegion    = "region"
+	ComplianceGroupFormation = "formation"
+)
+
+// ComplianceGroups lists the units a compliance rollup can be grouped by
+var ComplianceGroups = []string{ComplianceGroupRegion, ComplianceGroupFormation}
+
+// complianceUnits maps each grouping onto its table, name column and officer foreign key, along with the
+// region and formation columns that place the unit itself in a scope. Regions have no formation of their own.
+var complianceUnits = map[string]struct {
+	table, name, officerColumn string
+	scope                      [2]string
+}{
+	ComplianceGroupRegion:    {table: "regions", name: "region", officerColumn: "region_id", scope: [2]string{"u.id", "NULL::bigint"}},
+	ComplianceGroupFormation: {table: "formations", name: "formation", officerColumn: "formation_id", scope: [2]string{"u.region_id", "u.id"}},
+}
+
+// ComplianceRollup struct to represent aggregated compliance for a single region or formation
+type ComplianceRollup struct {
+	UnitID       int64   `json:"unit_id"`
+	Unit         string  `json:"unit"`
+	Officers     int     `json:"officers"`
+	Compliant    int     `json:"compliant"`
+	OnTrack      int     `json:"on_track"`
+	AtRisk       int     `json:"at_risk"`
+	NonCompliant int     `json:"non_compliant"`
+	AverageHours float64 `json:"average_hours"`
+}
+
+// GetRollup aggregates officer compliance for a year by region or formation, optionally
+// limited to officers holding a rank or posting. Only officers inside filters.Scope are counted, and
+// only units inside the scope or holding such officers are listed.
+func (m *ComplianceModel) GetRollup(groupBy string, rankID, postingID *int64, year int, filters Filters) ([]*ComplianceRollup, MetaData, error) {
+	unit, ok := complianceUnits[groupBy]
+	if !ok {
+		unit = complianceUnits[ComplianceGroupRegion]
+	}
+
+	if filters.Sort == "" {
+		filters.Sort = "unit"
+	}
+
+	query := fmt.Sprintf(`
+		WITH officer_hours AS (
+			SELECT o.id, o.%[1]s AS unit_id, r.annual_training_hours AS required,%[2]s AS earned
+			FROM officers o
+			INNER JOIN ranks r ON r.id = o.rank_id
+			WHERE ($1 = 0 OR o.rank_id = $1)
+			AND ($4 = 0 OR o.posting_id = $4)
+			AND %[11]s
+		), classified AS (
+			SELECT id, unit_id, earned,
+				CASE
+					WHEN earned >= required THEN '%[5]s'
+					WHEN $5::boolean THEN '%[8]s'
+					WHEN earned >= required * $6::float8 THEN '%[6]s'
+					ELSE '%[7]s'
+				END AS status
+			FROM officer_hours
+		)
+		SELECT COUNT(*) OVER(), u.id, u.%[3]s AS unit,
+			COUNT(c.id) AS officers,
+			COUNT(c.id) FILTER (WHERE c.status = '%[5]s') AS compliant,
+			COUNT(c.id) FILTER (WHERE c.status = '%[6]s') AS on_track,
+			COUNT(c.id) FILTER (WHERE c.status = '%[7]s') AS at_risk,
+			COUNT(c.id) FILTER (WHERE c.status = '%[8]s') AS non_compliant,
+			COALESCE(ROUND(AVG(c.earned), 2), 0)::float8 AS average_hours
+		FROM %[4]s u
+		LEFT JOIN classified c ON c.unit_id = u.id
+		WHERE %[12]s OR EXISTS (SELECT 1 FROM officer_hours oh WHERE oh.unit_id = u.id)
+		GROUP BY u.id, u.%[3]s
+		ORDER BY %[9]s %[10]s, u.id ASC
+		LIMIT $7 OFFSET $8`,
+		unit.officerColumn, completedHoursQuery, unit.name, unit.table,
+		ComplianceStatusCompliant, ComplianceStatusOnTrack, ComplianceStatusAtRisk, ComplianceStatusNonCompliant,
+		filters.sortColumn(), filters.sortDirection(),
+		scopeCondition(9, [2]string{"o.region_id", "o.formation_id"}), scopeCondition(9, unit.scope))
+
+	rankArg := int64(0)
+	if rankID != nil {
+		rankArg = *rankID
+	}
+
+	postingArg := int64(0)
+	if postingID != nil {
+		postingArg = *postingID
+	}
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+	closed, elapsed := complianceYearProgress(year, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := append([]any{rankArg, yearStart, yearEnd, postingArg, closed, elapsed, filters.limit(), filters.offset()}, filters.Scope.args()...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+	defer rows.Close()
+
+	var (
+		rollups      []*ComplianceRollup
+		totalRecords int
+	)
+
+	for rows.Next() {
+		var rollup ComplianceRollup
+		if err := rows.Scan(
+			&totalRecords,
+			&rollup.UnitID,
+			&rollup.Unit,
+			&rollup.Officers,
+			&rollup.Compliant,
+			&rollup.OnTrack,
+			&rollup.AtRisk,
+			&rollup.NonCompliant,
+			&rollup.AverageHours,
+		); err != nil {
+			return nil, MetaData{}, err
+		}
+		rollups = append(rollups, &rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return rollups, metadata, nil
+}
@@ -0,0 +1,193 @@
+// FileName: internal/data/email_changes.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+/************************************************************************************************************/
+// Email Change Declarations
+/************************************************************************************************************/
+
+// EmailChange is a request to change a user's email address that is waiting for the new address to be
+// confirmed. The user keeps their old address until then.
+type EmailChange struct {
+	UserID      int64     `json:"user_id"`
+	NewEmail    string    `json:"new_email"`
+	RequestedBy *int64    `json:"requested_by"`
+	Expiry      time.Time `json:"expiry"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EmailChangeModel wraps the database connection pool for email changes
+type EmailChangeModel struct {
+	DB *sql.DB
+}
+
+/************************************************************************************************************/
+// Email Change Methods
+/************************************************************************************************************/
+
+// Request records an email change, replacing any pending one for the user, and creates the token that
+// confirms it, for the new address, and the token that cancels it, for the old one
+func (m *EmailChangeModel) Request(change *EmailChange, ttl time.Duration) (*Token, *Token, error) {
+	confirmToken, err := generateToken(change.UserID, ttl, ScopeEmailChange)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancelToken, err := generateToken(change.UserID, ttl, ScopeEmailChangeCancel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO email_changes (user_id, new_email, requested_by, expiry)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET new_email = EXCLUDED.new_email, requested_by = EXCLUDED.requested_by, expiry = EXCLUDED.expiry, created_at = NOW()
+		RETURNING created_at`,
+		change.UserID, change.NewEmail, change.RequestedBy, confirmToken.Expiry,
+	).Scan(&change.CreatedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	change.Expiry = confirmToken.Expiry
+
+	if err := deleteEmailChangeTokens(ctx, tx, change.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	for _, token := range []*Token{confirmToken, cancelToken} {
+		if err := insertToken(ctx, tx, token); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return confirmToken, cancelToken, tx.Commit()
+}
+
+// Get retrieves a user's pending email change that has not expired
+func (m *EmailChangeModel) Get(userID int64) (*EmailChange, error) {
+	query := `
+		SELECT user_id, new_email, requested_by, expiry, created_at
+		FROM email_changes
+		WHERE user_id = $1 AND expiry > NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var change EmailChange
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&change.UserID,
+		&change.NewEmail,
+		&change.RequestedBy,
+		&change.Expiry,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// Confirm replaces a user's email address with the one in their pending change and clears the change
+// and its tokens. Returns ErrRecordNotFound when no change is pending, or ErrDuplicateEmail when the
+// new address was taken by another user in the meantime.
+func (m *EmailChangeModel) Confirm(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var newEmail string
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM email_changes
+		WHERE user_id = $1 AND expiry > NOW()
+		RETURNING new_email`,
+		userID,
+	).Scan(&newEmail)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET email = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2`,
+		newEmail, userID)
+	if err != nil {
+		if isDuplicateKeyViolation(err) {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	if err := deleteEmailChangeTokens(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel discards a user's pending email change and its tokens, returning the change. Returns
+// ErrRecordNotFound when no change is pending.
+func (m *EmailChangeModel) Cancel(userID int64) (*EmailChange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var change EmailChange
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM email_changes
+		WHERE user_id = $1
+		RETURNING user_id, new_email, requested_by, expiry, created_at`,
+		userID,
+	).Scan(&change.UserID, &change.NewEmail, &change.RequestedBy, &change.Expiry, &change.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if err := deleteEmailChangeTokens(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	return &change, tx.Commit()
+}
+
+// deleteEmailChangeTokens removes the confirm and cancel tokens sent for a user's email change
+func deleteEmailChangeTokens(ctx context.Context, tx *sql.Tx, userID int64) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = $1 AND scope IN ($2, $3)`, userID, ScopeEmailChange, ScopeEmailChangeCancel)
+	return err
+}
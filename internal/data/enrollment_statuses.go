@@ -21,6 +21,14 @@ type EnrollmentStatus struct {
 	Status string `json:"status"`
 }
 
+// Enrollment statuses with special meaning for seat allocation. Waitlisted and cancelled
+// enrollments do not hold a seat in their training session.
+const (
+	EnrollmentStatusEnrolled   = "Enrolled"
+	EnrollmentStatusWaitlisted = "Waitlisted"
+	EnrollmentStatusCancelled  = "Cancelled"
+)
+
 // EnrollmentStatusModel struct to interact with the enrollment_statuses table in the database
 type EnrollmentStatusModel struct {
 	DB *sql.DB
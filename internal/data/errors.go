@@ -10,13 +10,24 @@ import (
 
 // Predefined errors for common scenarios
 var (
-	ErrRecordNotFound      = errors.New("record not found")
-	ErrEditConflict        = errors.New("edit conflict")
-	ErrDuplicateEmail      = errors.New("duplicate email")
-	ErrDuplicateValue      = errors.New("duplicate value")
-	ErrInvalidCredentials  = errors.New("invalid credentials")
-	ErrNoMatch             = errors.New("no matching records found")
-	ErrForeignKeyViolation = errors.New("constraint violation")
+	ErrRecordNotFound           = errors.New("record not found")
+	ErrEditConflict             = errors.New("edit conflict")
+	ErrDuplicateEmail           = errors.New("duplicate email")
+	ErrDuplicateValue           = errors.New("duplicate value")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrNoMatch                  = errors.New("no matching records found")
+	ErrForeignKeyViolation      = errors.New("constraint violation")
+	ErrSessionFull              = errors.New("session is at capacity")
+	ErrWaitlistMismatch         = errors.New("waitlist does not match")
+	ErrCertificateNotIssued     = errors.New("certificate not issued")
+	ErrCertificateAlreadyIssued = errors.New("certificate already issued")
+	ErrCertificateRevoked       = errors.New("certificate revoked")
+	ErrAttendanceNotPresent     = errors.New("attendance does not count as present")
+	ErrProgressIncomplete       = errors.New("progress is not complete")
+	ErrAdminRole                = errors.New("the admin role cannot be changed")
+	ErrLastAdmin                = errors.New("last admin")
+	ErrTokenReused              = errors.New("refresh token reused")
+	ErrTwoFactorEnabled         = errors.New("two-factor authentication already enabled")
 )
 
 func isDuplicateKeyViolation(err error) bool {
@@ -14,6 +14,8 @@ type Filters struct {
 	PageSize     int      // Number of records per page
 	Sort         string   // Sort parameter
 	SortSafelist []string // List of permitted sort values
+	Unpaged      bool     // Return every matching record, ignoring Page and PageSize
+	Scope        *Scope   // Limit records to these regions and formations, where the model supports it
 }
 
 // MetaData holds pagination metadata.
@@ -34,13 +36,20 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(v.Permitted(f.Sort, f.SortSafelist...), "sort", "invalid sort value") // Sort must be in the safelist
 }
 
-// limit returns the limit for SQL queries based on the PageSize.
-func (f Filters) limit() int {
+// limit returns the limit for SQL queries based on the PageSize. Unpaged filters return nil, which
+// PostgreSQL treats as LIMIT ALL.
+func (f Filters) limit() any {
+	if f.Unpaged {
+		return nil
+	}
 	return f.PageSize
 }
 
 // offset returns the offset for SQL queries based on the Page and PageSize.
 func (f Filters) offset() int {
+	if f.Unpaged {
+		return 0
+	}
 	return (f.Page - 1) * f.PageSize
 }
 
@@ -0,0 +1,319 @@
+// FileName: internal/data/invitations.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+/************************************************************************************************************/
+// Invitation Declarations
+/************************************************************************************************************/
+
+// Invitation is an invitation for someone to join. Their account is created inactive along with it and
+// becomes usable once they accept and choose a password.
+type Invitation struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	InvitedBy  *int64     `json:"invited_by"`
+	Expiry     time.Time  `json:"expiry"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User        *User    `json:"user,omitempty"`
+	Roles       []string `json:"roles"`
+	RegionID    *int64   `json:"-"` // region the roles are limited to, if any
+	FormationID *int64   `json:"-"` // formation the roles are limited to, if any
+	Officer     *Officer `json:"officer,omitempty"`
+}
+
+// InvitationModel wraps the database connection pool for invitations
+type InvitationModel struct {
+	DB *sql.DB
+}
+
+/************************************************************************************************************/
+// Invitation Methods
+/************************************************************************************************************/
+
+// Insert creates the invited user, their roles and officer record, the invitation and the token sent to
+// accept it, all in one transaction. Returns ErrDuplicateEmail, ErrDuplicateValue for a regulation number
+// already in use, or ErrForeignKeyViolation for an unknown region, formation, rank or posting.
+func (m *InvitationModel) Insert(invitation *Invitation, ttl time.Duration) (*Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user := invitation.User
+	user.IsActivated = false
+	user.IsOfficer = invitation.Officer != nil
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO users (first_name, last_name, gender, email, password_hash, is_activated, is_facilitator, is_officer)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at, version`,
+		user.FirstName, user.LastName, user.Gender, user.Email, user.Password.hash, user.IsActivated, user.IsFacilitator, user.IsOfficer,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+	if err != nil {
+		if isDuplicateKeyViolation(err) {
+			return nil, ErrDuplicateEmail
+		}
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO roles_users (user_id, role_id, region_id, formation_id)
+		SELECT $1, r.id, $2, $3
+		FROM roles r
+		WHERE r.role = ANY($4)`,
+		user.ID, invitation.RegionID, invitation.FormationID, pq.Array(invitation.Roles))
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, ErrForeignKeyViolation
+		}
+		return nil, err
+	}
+
+	if officer := invitation.Officer; officer != nil {
+		officer.UserID = user.ID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO officers (user_id, regulation_number, rank_id, posting_id, formation_id, region_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at`,
+			officer.UserID, officer.RegulationNumber, officer.RankID, officer.PostingID, officer.FormationID, officer.RegionID,
+		).Scan(&officer.ID, &officer.CreatedAt, &officer.UpdatedAt)
+		if err != nil {
+			switch {
+			case isDuplicateKeyViolation(err):
+				return nil, ErrDuplicateValue
+			case isForeignKeyViolation(err):
+				return nil, ErrForeignKeyViolation
+			default:
+				return nil, err
+			}
+		}
+	}
+
+	invitation.UserID = user.ID
+	token, err := issueInvitation(ctx, tx, invitation, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, tx.Commit()
+}
+
+// Invite sends an invitation to a user who already exists but has not activated their account, such as
+// one created by an officer import. An earlier invitation for the user is replaced.
+func (m *InvitationModel) Invite(userID int64, invitedBy *int64, ttl time.Duration) (*Invitation, *Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// An invitation sets the password of the account it is for, so it is never issued for an active one
+	var pending bool
+	err = tx.QueryRowContext(ctx, `SELECT true FROM users WHERE id = $1 AND is_activated = false FOR UPDATE`, userID).Scan(&pending)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrRecordNotFound
+		}
+		return nil, nil, err
+	}
+
+	invitation := &Invitation{UserID: userID, InvitedBy: invitedBy}
+	token, err := issueInvitation(ctx, tx, invitation, ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return invitation, token, tx.Commit()
+}
+
+// issueInvitation records an invitation, replacing any earlier one for the user, and creates the token
+// that accepts it in place of any sent before
+func issueInvitation(ctx context.Context, tx *sql.Tx, invitation *Invitation, ttl time.Duration) (*Token, error) {
+	token, err := generateToken(invitation.UserID, ttl, ScopeInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO invitations (user_id, invited_by, expiry)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET invited_by = EXCLUDED.invited_by, expiry = EXCLUDED.expiry, created_at = NOW()
+		RETURNING id, created_at`,
+		invitation.UserID, invitation.InvitedBy, token.Expiry,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	invitation.Expiry = token.Expiry
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = $1 AND scope = $2`, invitation.UserID, ScopeInvitation); err != nil {
+		return nil, err
+	}
+
+	if err := insertToken(ctx, tx, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Get retrieves an invitation that has not been accepted yet, with the invited user and their roles
+func (m *InvitationModel) Get(id int64) (*Invitation, error) {
+	invitations, err := m.getPending(`AND i.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invitations) == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	return invitations[0], nil
+}
+
+// GetAllPending lists the invitations that have not been accepted yet, newest first, including expired
+// ones so they can be resent or revoked
+func (m *InvitationModel) GetAllPending() ([]*Invitation, error) {
+	return m.getPending(``)
+}
+
+// getPending runs the pending invitation query with an extra condition
+func (m *InvitationModel) getPending(condition string, args ...any) ([]*Invitation, error) {
+	query := `
+		SELECT i.id, i.user_id, i.invited_by, i.expiry, i.created_at,
+			u.first_name, u.last_name, u.email, u.gender, u.is_facilitator, u.is_officer, u.created_at, u.version,
+			ARRAY(
+				SELECT r.role FROM roles_users ru INNER JOIN roles r ON r.id = ru.role_id
+				WHERE ru.user_id = i.user_id ORDER BY r.role
+			)
+		FROM invitations i
+		INNER JOIN users u ON u.id = i.user_id
+		WHERE i.accepted_at IS NULL ` + condition + `
+		ORDER BY i.created_at DESC, i.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*Invitation{}
+	for rows.Next() {
+		invitation := Invitation{User: &User{}}
+		err := rows.Scan(
+			&invitation.ID,
+			&invitation.UserID,
+			&invitation.InvitedBy,
+			&invitation.Expiry,
+			&invitation.CreatedAt,
+			&invitation.User.FirstName,
+			&invitation.User.LastName,
+			&invitation.User.Email,
+			&invitation.User.Gender,
+			&invitation.User.IsFacilitator,
+			&invitation.User.IsOfficer,
+			&invitation.User.CreatedAt,
+			&invitation.User.Version,
+			pq.Array(&invitation.Roles),
+		)
+		if err != nil {
+			return nil, err
+		}
+		invitation.User.ID = invitation.UserID
+		invitations = append(invitations, &invitation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// Accept saves an invited user who has chosen their password and activated their account, marks their
+// invitation accepted and deletes their invitation and activation tokens, all in one transaction. Returns
+// ErrEditConflict when the user changed since they were read.
+func (m *InvitationModel) Accept(user *User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateUser(ctx, tx, user); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE invitations
+		SET accepted_at = NOW()
+		WHERE user_id = $1 AND accepted_at IS NULL`,
+		user.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE user_id = $1 AND scope IN ($2, $3)`, user.ID, ScopeInvitation, ScopeActivation)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Revoke withdraws an invitation that has not been accepted by deleting the inactive account created for
+// it, along with its roles, officer record and token
+func (m *InvitationModel) Revoke(id int64) error {
+	query := `
+		DELETE FROM users
+		WHERE is_activated = false AND id = (
+			SELECT user_id FROM invitations WHERE id = $1 AND accepted_at IS NULL
+		)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrForeignKeyViolation
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
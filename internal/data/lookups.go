@@ -0,0 +1,97 @@
+// FileName: internal/data/lookups.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+/************************************************************************************************************/
+// Lookup Declarations
+/************************************************************************************************************/
+
+// Lookup identifies a table whose IDs can be resolved to display names
+type Lookup string
+
+// Tables with display names
+const (
+	LookupRegions            Lookup = "regions"
+	LookupFormations         Lookup = "formations"
+	LookupPostings           Lookup = "postings"
+	LookupRanks              Lookup = "ranks"
+	LookupTrainingTypes      Lookup = "training_types"
+	LookupTrainingCategories Lookup = "training_categories"
+	LookupWorkshops          Lookup = "workshops"
+	LookupUsers              Lookup = "users"
+	LookupOfficers           Lookup = "officers"
+	LookupTrainingSessions   Lookup = "training_sessions"
+	LookupTrainingStatuses   Lookup = "training_status"
+	LookupEnrollmentStatuses Lookup = "enrollment_statuses"
+	LookupAttendanceStatuses Lookup = "attendance_statuses"
+	LookupProgressStatuses   Lookup = "progress_statuses"
+)
+
+// lookupQueries selects the id and display name of every row for each lookup
+var lookupQueries = map[Lookup]string{
+	LookupRegions:            `SELECT id, region FROM regions`,
+	LookupFormations:         `SELECT id, formation FROM formations`,
+	LookupPostings:           `SELECT id, posting FROM postings`,
+	LookupRanks:              `SELECT id, rank FROM ranks`,
+	LookupTrainingTypes:      `SELECT id, name FROM training_types`,
+	LookupTrainingCategories: `SELECT id, name FROM training_categories`,
+	LookupWorkshops:          `SELECT id, workshop_name FROM workshops`,
+	LookupUsers:              `SELECT id, CONCAT_WS(' ', first_name, last_name) FROM users`,
+	LookupOfficers: `
+		SELECT o.id, CONCAT_WS(' ', o.regulation_number, u.first_name, u.last_name)
+		FROM officers o
+		INNER JOIN users u ON u.id = o.user_id`,
+	LookupTrainingSessions: `
+		SELECT s.id, w.workshop_name || ' (' || to_char(s.session_date, 'YYYY-MM-DD') || ')'
+		FROM training_sessions s
+		INNER JOIN workshops w ON w.id = s.workshop_id`,
+	LookupTrainingStatuses:   `SELECT id, status FROM training_status`,
+	LookupEnrollmentStatuses: `SELECT id, status FROM enrollment_statuses`,
+	LookupAttendanceStatuses: `SELECT id, status FROM attendance_statuses`,
+	LookupProgressStatuses:   `SELECT id, status FROM progress_statuses`,
+}
+
+// LookupModel struct to resolve IDs to display names, e.g. for exports
+type LookupModel struct {
+	DB *sql.DB
+}
+
+// Names returns the display name of every row in each requested table, keyed by table and then id.
+func (m *LookupModel) Names(lookups ...Lookup) (map[Lookup]map[int64]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	names := make(map[Lookup]map[int64]string, len(lookups))
+
+	for _, lookup := range lookups {
+		rows, err := m.DB.QueryContext(ctx, lookupQueries[lookup])
+		if err != nil {
+			return nil, err
+		}
+
+		names[lookup] = map[int64]string{}
+
+		for rows.Next() {
+			var id int64
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			names[lookup][id] = name
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
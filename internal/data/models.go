@@ -26,6 +26,13 @@ type Models struct {
 	AttendanceStatus   AttendanceStatusModel
 	ProgressStatus     ProgressStatusModel
 	TrainingEnrollment TrainingEnrollmentModel
+	Compliance         ComplianceModel
+	Certificate        CertificateModel
+	Lookup             LookupModel
+	Audit              AuditModel
+	TwoFactor          TwoFactorModel
+	Invitation         InvitationModel
+	EmailChange        EmailChangeModel
 }
 
 // NewModels returns a Models struct containing the initialized models.
@@ -51,5 +58,12 @@ func NewModels(db *sql.DB) Models {
 		AttendanceStatus:   AttendanceStatusModel{DB: db},
 		ProgressStatus:     ProgressStatusModel{DB: db},
 		TrainingEnrollment: TrainingEnrollmentModel{DB: db},
+		Compliance:         ComplianceModel{DB: db},
+		Certificate:        CertificateModel{DB: db},
+		Lookup:             LookupModel{DB: db},
+		Audit:              AuditModel{DB: db},
+		TwoFactor:          TwoFactorModel{DB: db},
+		Invitation:         InvitationModel{DB: db},
+		EmailChange:        EmailChangeModel{DB: db},
 	}
 }
@@ -0,0 +1,96 @@
+// FileName: internal/data/officer_imports.go
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+/************************************************************************************************************/
+// Officer Import Declarations
+/************************************************************************************************************/
+
+// MaxOfficerImport caps the number of officers created by one import
+const MaxOfficerImport = 500
+
+// OfficerImport is a user account and the officer record to create for it during an import
+type OfficerImport struct {
+	User    *User
+	Officer *Officer
+}
+
+// OfficerImportError reports which import entry could not be created
+type OfficerImportError struct {
+	Index int
+	Err   error
+}
+
+func (e *OfficerImportError) Error() string {
+	return fmt.Sprintf("officer import entry %d: %v", e.Index, e.Err)
+}
+
+func (e *OfficerImportError) Unwrap() error {
+	return e.Err
+}
+
+// Import creates every user and officer in a single transaction, giving each user the Officer role.
+// Either all of the entries are created or none are; the failing entry is reported in an
+// *OfficerImportError wrapping ErrDuplicateEmail, ErrDuplicateValue or ErrForeignKeyViolation.
+func (m *OfficerModel) Import(entries []*OfficerImport) error {
+	// Every entry takes several statements, so the whole import gets a longer deadline than a single insert
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, entry := range entries {
+		user := entry.User
+		user.IsOfficer = true
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO users (first_name, last_name, gender, email, password_hash, is_activated, is_facilitator, is_officer)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at, version`,
+			user.FirstName, user.LastName, user.Gender, user.Email, user.Password.hash, user.IsActivated, user.IsFacilitator, user.IsOfficer,
+		).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Version)
+		if err != nil {
+			if isDuplicateKeyViolation(err) {
+				err = ErrDuplicateEmail
+			}
+			return &OfficerImportError{Index: i, Err: err}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO roles_users (user_id, role_id)
+			SELECT $1, id FROM roles WHERE role = 'Officer'`, user.ID)
+		if err != nil {
+			return &OfficerImportError{Index: i, Err: err}
+		}
+
+		officer := entry.Officer
+		officer.UserID = user.ID
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO officers (user_id, regulation_number, rank_id, posting_id, formation_id, region_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at, updated_at`,
+			officer.UserID, officer.RegulationNumber, officer.RankID, officer.PostingID, officer.FormationID, officer.RegionID,
+		).Scan(&officer.ID, &officer.CreatedAt, &officer.UpdatedAt)
+		if err != nil {
+			switch {
+			case isDuplicateKeyViolation(err):
+				err = ErrDuplicateValue
+			case isForeignKeyViolation(err):
+				err = ErrForeignKeyViolation
+			}
+			return &OfficerImportError{Index: i, Err: err}
+		}
+	}
+
+	return tx.Commit()
+}
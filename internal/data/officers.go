@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"github.com/lib/pq"
 )
 
 /************************************************************************************************************/
@@ -93,8 +94,45 @@ func (m *OfficerModel) Insert(officer *Officer) error {
 	return nil
 }
 
+// OutsideScope returns the ids, out of those given, of the officers that fall outside scope
+func (m *OfficerModel) OutsideScope(ids []int64, scope *Scope) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM officers
+		WHERE id = ANY($1::bigint[])
+		AND NOT ` + scopeCondition(2, unitColumns) + `
+		ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := append([]any{pq.Array(ids)}, scope.args()...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outside := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		outside = append(outside, id)
+	}
+
+	return outside, rows.Err()
+}
+
 // Get retrieves an officer by id.
 func (m *OfficerModel) Get(id int64) (*Officer, error) {
+	return m.GetInScope(id, nil)
+}
+
+// GetInScope retrieves an officer by id, returning ErrRecordNotFound when the officer is outside scope
+func (m *OfficerModel) GetInScope(id int64, scope *Scope) (*Officer, error) {
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
@@ -102,14 +140,17 @@ func (m *OfficerModel) Get(id int64) (*Officer, error) {
 	query := `
 		SELECT id, user_id, regulation_number, rank_id, posting_id, formation_id, region_id, created_at, updated_at
 		FROM officers
-		WHERE id = $1`
+		WHERE id = $1
+		AND ` + scopeCondition(2, unitColumns)
 
 	var officer Officer
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+	args := append([]any{id}, scope.args()...)
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
 		&officer.ID,
 		&officer.UserID,
 		&officer.RegulationNumber,
@@ -276,6 +317,38 @@ func (m *OfficerModel) Delete(id int64) error {
 
 // GetAll retrieves all officers from the database with filtering and pagination
 func (m *OfficerModel) GetAll(regulationNumber string, rankID, postingID, formationID, regionID *int64, filters Filters) ([]*Officer, MetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	officers := []*Officer{}
+	totalRecords, err := m.scanAll(ctx, regulationNumber, rankID, postingID, formationID, regionID, filters, func(officer *Officer) error {
+		officers = append(officers, officer)
+		return nil
+	})
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return officers, metadata, nil
+}
+
+// Each calls fn with every officer matching the criteria of GetAll, ignoring the page, as each row is
+// read, so exports never hold the whole list. It stops at the first error fn returns.
+func (m *OfficerModel) Each(regulationNumber string, rankID, postingID, formationID, regionID *int64, filters Filters, fn func(*Officer) error) error {
+	filters.Unpaged = true
+
+	// Rows are read only as fast as the client takes them, so allow longer than a single page
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := m.scanAll(ctx, regulationNumber, rankID, postingID, formationID, regionID, filters, fn)
+	return err
+}
+
+// scanAll runs the query behind GetAll and Each, passing each officer to fn, and returns the total
+// number of matching records
+func (m *OfficerModel) scanAll(ctx context.Context, regulationNumber string, rankID, postingID, formationID, regionID *int64, filters Filters, fn func(*Officer) error) (int, error) {
 	query := `
 		SELECT COUNT(*) OVER(), id, user_id, regulation_number, rank_id, posting_id, formation_id, region_id, created_at, updated_at
 		FROM officers
@@ -284,21 +357,19 @@ func (m *OfficerModel) GetAll(regulationNumber string, rankID, postingID, format
 		AND (posting_id = $3 OR $3 IS NULL)
 		AND (formation_id = $4 OR $4 IS NULL)
 		AND (region_id = $5 OR $5 IS NULL)
+		AND ` + scopeCondition(8, unitColumns) + `
 		ORDER BY id ASC
 		LIMIT $6 OFFSET $7`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	args := append([]any{regulationNumber, rankID, postingID, formationID, regionID, filters.limit(), filters.offset()}, filters.Scope.args()...)
 
-	rows, err := m.DB.QueryContext(ctx, query, regulationNumber, rankID, postingID, formationID, regionID, filters.limit(), filters.offset())
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 	defer rows.Close()
 
 	totalRecords := 0
-	officers := []*Officer{}
-
 	for rows.Next() {
 		var officer Officer
 		err := rows.Scan(
@@ -314,17 +385,18 @@ func (m *OfficerModel) GetAll(regulationNumber string, rankID, postingID, format
 			&officer.UpdatedAt,
 		)
 		if err != nil {
-			return nil, MetaData{}, err
+			return 0, err
+		}
+		if err := fn(&officer); err != nil {
+			return 0, err
 		}
-		officers = append(officers, &officer)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
-	return officers, metadata, nil
+	return totalRecords, nil
 }
 
 // GetWithDetails retrieves an officer with all related information (user, rank, posting, etc.)
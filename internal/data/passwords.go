@@ -0,0 +1,192 @@
+// FileName: internal/data/passwords.go
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"golang.org/x/crypto/argon2"
+)
+
+/************************************************************************************************************/
+// Password Hashing Declarations
+/************************************************************************************************************/
+
+// PasswordParams are the Argon2id cost parameters new password hashes are made with. They are stored in
+// each hash, so changing them only affects passwords set or rehashed afterwards.
+type PasswordParams struct {
+	Memory      uint32 // memory in KiB
+	Iterations  uint32 // passes over the memory
+	Parallelism uint8  // threads used
+}
+
+// DefaultPasswordParams follow the OWASP recommendation for Argon2id
+var DefaultPasswordParams = PasswordParams{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// errInvalidPasswordHash is returned for a stored hash in neither the bcrypt nor the Argon2id format
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// isBcryptHash reports whether a stored hash predates Argon2id. bcrypt hashes start with $2a$, $2b$ or $2y$.
+func isBcryptHash(hash []byte) bool {
+	return bytes.HasPrefix(hash, []byte("$2"))
+}
+
+// hashArgon2id hashes a password in the PHC string format, $argon2id$v=19$m=...,t=...,p=...$salt$key,
+// so the parameters travel with the hash
+func hashArgon2id(plaintext string, params PasswordParams) ([]byte, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(plaintext), salt, params.Iterations, params.Memory, params.Parallelism, argon2KeyLength)
+
+	return encodeArgon2id(params, salt, key), nil
+}
+
+// unusableArgon2id returns a well-formed Argon2id hash whose key is random rather than derived from a
+// password, so no password matches it while logins against it still take the usual time
+func unusableArgon2id(params PasswordParams) ([]byte, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, argon2KeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return encodeArgon2id(params, salt, key), nil
+}
+
+// encodeArgon2id writes the parameters, salt and key of a hash as a PHC string
+func encodeArgon2id(params PasswordParams, salt, key []byte) []byte {
+	encoded := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+	return []byte(encoded)
+}
+
+// decodeArgon2id splits a PHC string into its parameters, salt and key
+func decodeArgon2id(hash []byte) (PasswordParams, []byte, []byte, error) {
+	var params PasswordParams
+
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	return params, salt, key, nil
+}
+
+// matchArgon2id checks a password against an Argon2id hash using the parameters stored in the hash
+func matchArgon2id(hash []byte, plaintext string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(plaintext), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1, nil
+}
+
+/************************************************************************************************************/
+// Password Policy Declarations
+/************************************************************************************************************/
+
+// PasswordHistoryLimit is the most previous passwords kept for each user
+const PasswordHistoryLimit = 24
+
+// PasswordPolicy is the rules new passwords must meet on top of ValidatePasswordPlaintext
+type PasswordPolicy struct {
+	MinLength   int        // shortest password accepted
+	HistorySize int        // most recent passwords, including the current one, that cannot be reused
+	Breached    BreachList // passwords known from breaches
+}
+
+// BreachList is a set of passwords known from data breaches, held as SHA-1 hashes so the list can be
+// given either as plain passwords or as the hashes Have I Been Pwned publishes
+type BreachList map[[sha1.Size]byte]struct{}
+
+// LoadBreachList reads a breach list with one entry per line. An entry is either a password, or a
+// SHA-1 hash in hex optionally followed by :count as in the Have I Been Pwned downloads.
+func LoadBreachList(path string) (BreachList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := make(BreachList)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if entry, _, _ := strings.Cut(line, ":"); len(entry) == 2*sha1.Size {
+			var sum [sha1.Size]byte
+			if _, err := hex.Decode(sum[:], []byte(entry)); err == nil {
+				list[sum] = struct{}{}
+				continue
+			}
+		}
+
+		list[sha1.Sum([]byte(line))] = struct{}{}
+	}
+
+	return list, scanner.Err()
+}
+
+// Contains reports whether a password is on the breach list
+func (b BreachList) Contains(password string) bool {
+	_, found := b[sha1.Sum([]byte(password))]
+	return found
+}
+
+// ValidatePasswordPolicy checks a new password against the configured length and breach list. Reuse is
+// checked separately with UserModel.PasswordReused, as it needs the user's history.
+func ValidatePasswordPolicy(v *validator.Validator, password string, policy PasswordPolicy) {
+	ValidatePasswordPlaintext(v, password)
+	v.Check(len(password) >= policy.MinLength, "password", fmt.Sprintf("must be at least %d characters long", policy.MinLength))
+	v.Check(!policy.Breached.Contains(password), "password", "has appeared in a data breach, choose a different password")
+}
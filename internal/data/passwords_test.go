@@ -0,0 +1,261 @@
+// Filename: internal/data/passwords_test.go
+package data
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hash, err := hashArgon2id("Pa55word!", testPasswordParams)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if !strings.HasPrefix(string(hash), "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Unexpected hash format %q", hash)
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		t.Fatalf("Failed to decode hash: %v", err)
+	}
+	if params != testPasswordParams {
+		t.Errorf("Expected params %+v; got %+v", testPasswordParams, params)
+	}
+	if len(salt) != argon2SaltLength || len(key) != argon2KeyLength {
+		t.Errorf("Expected a %d byte salt and %d byte key; got %d and %d", argon2SaltLength, argon2KeyLength, len(salt), len(key))
+	}
+
+	again, err := hashArgon2id("Pa55word!", testPasswordParams)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	if string(again) == string(hash) {
+		t.Error("Expected each hash to use a new salt")
+	}
+
+	tests := []struct {
+		name      string
+		plaintext string
+		expected  bool
+	}{
+		{name: "Correct password", plaintext: "Pa55word!", expected: true},
+		{name: "Wrong password", plaintext: "Pa55word?", expected: false},
+		{name: "Different case", plaintext: "pa55word!", expected: false},
+		{name: "Empty password", plaintext: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := matchArgon2id(hash, tt.plaintext)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matches != tt.expected {
+				t.Errorf("Expected %v; got %v", tt.expected, matches)
+			}
+		})
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	const salt = "c29tZXNhbHRzb21lc2FsdA"
+	const key = "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+	tests := []struct {
+		name     string
+		hash     string
+		expected PasswordParams
+		wantErr  bool
+	}{
+		{
+			name:     "Valid hash",
+			hash:     "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + key,
+			expected: PasswordParams{Memory: 65536, Iterations: 3, Parallelism: 2},
+		},
+		{name: "Argon2i rather than Argon2id", hash: "$argon2i$v=19$m=65536,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "Unsupported version", hash: "$argon2id$v=16$m=65536,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "Malformed parameters", hash: "$argon2id$v=19$m=lots,t=3,p=2$" + salt + "$" + key, wantErr: true},
+		{name: "Salt not base64", hash: "$argon2id$v=19$m=65536,t=3,p=2$!!!$" + key, wantErr: true},
+		{name: "Key not base64", hash: "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$!!!", wantErr: true},
+		{name: "Missing key", hash: "$argon2id$v=19$m=65536,t=3,p=2$" + salt, wantErr: true},
+		{name: "bcrypt hash", hash: "$2a$12$R9h/cIPz0gi.URNNX3kh2OPST9/PgBkqquzi.Ss7KIUgO2t0jWMUW", wantErr: true},
+		{name: "Empty", hash: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, _, err := decodeArgon2id([]byte(tt.hash))
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidPasswordHash) {
+					t.Fatalf("Expected errInvalidPasswordHash; got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if params != tt.expected {
+				t.Errorf("Expected params %+v; got %+v", tt.expected, params)
+			}
+		})
+	}
+
+	if _, err := matchArgon2id([]byte("$argon2id$v=19$broken"), "Pa55word!"); !errors.Is(err, errInvalidPasswordHash) {
+		t.Errorf("Expected matching against a malformed hash to fail with errInvalidPasswordHash; got %v", err)
+	}
+}
+
+func TestPasswordMatchesAndNeedsRehash(t *testing.T) {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("Pa55word!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to create bcrypt hash: %v", err)
+	}
+
+	current, err := hashArgon2id("Pa55word!", testPasswordParams)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	stronger := PasswordParams{Memory: 128, Iterations: 2, Parallelism: 1}
+	outdated, err := hashArgon2id("Pa55word!", stronger)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		hash          []byte
+		needsRehash   bool
+		matchesRight  bool
+		matchesWrong  bool
+		expectedError bool
+	}{
+		{name: "Legacy bcrypt hash", hash: bcryptHash, needsRehash: true, matchesRight: true},
+		{name: "Argon2id with current params", hash: current, needsRehash: false, matchesRight: true},
+		{name: "Argon2id with other params", hash: outdated, needsRehash: true, matchesRight: true},
+		{name: "Unrecognised hash", hash: []byte("plaintext"), needsRehash: true, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Password{hash: tt.hash}
+
+			if got := p.NeedsRehash(testPasswordParams); got != tt.needsRehash {
+				t.Errorf("Expected NeedsRehash %v; got %v", tt.needsRehash, got)
+			}
+
+			matches, err := p.Matches("Pa55word!")
+			if tt.expectedError {
+				if err == nil {
+					t.Fatal("Expected an error matching an unrecognised hash")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matches != tt.matchesRight {
+				t.Errorf("Expected the right password to match: %v; got %v", tt.matchesRight, matches)
+			}
+
+			matches, err = p.Matches("wrong password")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matches != tt.matchesWrong {
+				t.Errorf("Expected the wrong password to match: %v; got %v", tt.matchesWrong, matches)
+			}
+		})
+	}
+}
+
+func TestLoadBreachList(t *testing.T) {
+	hashed := sha1.Sum([]byte("hunter2"))
+	upper := strings.ToUpper(hex.EncodeToString(hashed[:]))
+	lower := sha1.Sum([]byte("trustno1"))
+	notHex := strings.Repeat("z", 2*sha1.Size)
+
+	contents := strings.Join([]string{
+		"password123",
+		"",
+		"Summer2024!\r",
+		upper + ":2543",
+		hex.EncodeToString(lower[:]),
+		notHex,
+	}, "\n")
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write breach list: %v", err)
+	}
+
+	list, err := LoadBreachList(path)
+	if err != nil {
+		t.Fatalf("Failed to load breach list: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expected bool
+	}{
+		{name: "Plain entry", password: "password123", expected: true},
+		{name: "Plain entry with CRLF line ending", password: "Summer2024!", expected: true},
+		{name: "Upper case SHA-1 with count", password: "hunter2", expected: true},
+		{name: "Lower case SHA-1", password: "trustno1", expected: true},
+		{name: "40 characters that are not hex are a password", password: notHex, expected: true},
+		{name: "Not listed", password: "Correct-Horse-Battery-Staple-9", expected: false},
+		{name: "Blank lines are not entries", password: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list.Contains(tt.password); got != tt.expected {
+				t.Errorf("Expected %v; got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := LoadBreachList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected an error for a missing breach list")
+	}
+}
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength: 12,
+		Breached:  BreachList{sha1.Sum([]byte("Password123!")): {}},
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{name: "Meets the policy", password: "Str0ng!Passphrase", valid: true},
+		{name: "Shorter than the minimum length", password: "Sh0rt-Pass!", valid: false},
+		{name: "On the breach list", password: "Password123!", valid: false},
+		{name: "Missing a special character", password: "Str0ngPassphrase", valid: false},
+		{name: "Empty", password: "", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidatePasswordPolicy(v, tt.password, policy)
+			if v.IsEmpty() != tt.valid {
+				t.Errorf("Expected valid %v; got errors %v", tt.valid, v.Errors)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"slices"
 	"time"
 
@@ -93,3 +94,61 @@ func (m *PermissionModel) AssignToRole(roleID int64, codes ...string) error {
 
 	return nil
 }
+
+// GetAll - Retrieve every permission, ordered by code
+func (m *PermissionModel) GetAll() ([]*Permission, error) {
+	query := `
+		SELECT id, code
+		FROM permissions
+		ORDER BY code`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []*Permission{}
+	for rows.Next() {
+		var permission Permission
+		if err := rows.Scan(&permission.ID, &permission.Code); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, &permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// RemoveFromRole - Remove a list of permissions from a specific role. The Admin role keeps every
+// permission, so removing from it returns ErrAdminRole.
+func (m *PermissionModel) RemoveFromRole(roleID int64, codes ...string) error {
+	query := `
+		DELETE FROM roles_permissions rp
+		USING permissions p
+		WHERE rp.permission_id = p.id AND rp.role_id = $1 AND p.code = ANY($2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var role string
+	err := m.DB.QueryRowContext(ctx, `SELECT role FROM roles WHERE id = $1`, roleID).Scan(&role)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrRecordNotFound
+	case err != nil:
+		return err
+	case role == AdminRole:
+		return ErrAdminRole
+	}
+
+	_, err = m.DB.ExecContext(ctx, query, roleID, pq.Array(codes))
+	return err
+}
@@ -104,6 +104,28 @@ func (m *PostingModel) GetByName(name string) (*Posting, error) {
 	return &posting, nil
 }
 
+// GetByCode retrieves a posting by its code.
+func (m *PostingModel) GetByCode(code string) (*Posting, error) {
+	query := `SELECT id, posting, code FROM postings WHERE code = $1`
+
+	var posting Posting
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, code).Scan(&posting.ID, &posting.Posting, &posting.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &posting, nil
+}
+
 // GetAll returns postings filtered by name or code.
 func (m *PostingModel) GetAll(name string, code string, filters Filters) ([]*Posting, MetaData, error) {
 	if filters.Sort == "" {
@@ -21,6 +21,13 @@ type ProgressStatus struct {
 	Status string `json:"status"`
 }
 
+// Progress statuses with special meaning for compliance and seat allocation
+const (
+	ProgressStatusNotStarted = "Not Started"
+	ProgressStatusCompleted  = "Completed"
+	ProgressStatusWithdrawn  = "Withdrawn"
+)
+
 // ProgressStatusModel struct to interact with the progress_statuses table in the database
 type ProgressStatusModel struct {
 	DB *sql.DB
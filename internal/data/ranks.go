@@ -107,6 +107,28 @@ func (m *RankModel) GetByName(name string) (*Rank, error) {
 	return &rank, nil
 }
 
+// GetByCode retrieves a rank by its code.
+func (m *RankModel) GetByCode(code string) (*Rank, error) {
+	query := `SELECT id, rank, code, annual_training_hours FROM ranks WHERE code = $1`
+
+	var rank Rank
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, code).Scan(&rank.ID, &rank.Rank, &rank.Code, &rank.AnnualTrainingHours)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &rank, nil
+}
+
 // GetAll returns ranks filtered by rank or code.
 func (m *RankModel) GetAll(name string, code string, filters Filters) ([]*Rank, MetaData, error) {
 	if filters.Sort == "" {
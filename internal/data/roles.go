@@ -4,9 +4,11 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"slices"
 	"time"
 
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
 	"github.com/lib/pq"
 )
 
@@ -14,10 +16,15 @@ import (
 // Role Declarations
 /************************************************************************************************************/
 
+// AdminRole is the role that holds every permission. It cannot be renamed, deleted or have permissions
+// removed, and at least one user must always hold it.
+const AdminRole = "Admin"
+
 // Role struct to represent a role in the system
 type Role struct {
-	ID   int64  `json:"id"`
-	Role string `json:"role"`
+	ID          int64       `json:"id"`
+	Role        string      `json:"role"`
+	Permissions Permissions `json:"permissions"`
 }
 
 // RoleModel struct to interact with the roles table in the database
@@ -33,6 +40,12 @@ func (r Roles) Include(role string) bool {
 	return slices.Contains(r, role)
 }
 
+// ValidateRole checks the name of a role
+func ValidateRole(v *validator.Validator, role *Role) {
+	v.Check(role.Role != "", "role", "must be provided")
+	v.Check(len(role.Role) <= 100, "role", "must not exceed 100 characters")
+}
+
 /*************************************************************************************************************/
 // Methods
 /*************************************************************************************************************/
@@ -78,7 +91,8 @@ func (m *RoleModel) AssignToUser(userID int64, roles ...string) error {
         INSERT INTO roles_users (user_id, role_id)
         SELECT $1, r.id
         FROM roles r
-        WHERE r.role = ANY($2)`
+        WHERE r.role = ANY($2)
+        ON CONFLICT (role_id, user_id) DO NOTHING`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -126,6 +140,40 @@ func (m *RoleModel) GetAllPermissionsForUser(userID int64) (Permissions, error)
 	return permissions, nil
 }
 
+// GetAllPermissionsForRoles - Retrieve all permission codes granted by the named roles
+func (m *RoleModel) GetAllPermissionsForRoles(roles ...string) (Permissions, error) {
+	query := `
+		SELECT DISTINCT p.code
+		FROM permissions p
+		INNER JOIN roles_permissions rp ON rp.permission_id = p.id
+		INNER JOIN roles r ON r.id = rp.role_id
+		WHERE r.role = ANY($1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(roles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions Permissions
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
 // HasPermission - Check if a user has a specific permission
 func (m *RoleModel) HasPermission(userID int64, permissionCode string) (bool, error) {
 	query := `
@@ -166,3 +214,255 @@ func (m *RoleModel) HasAllPermissions(userID int64, permissionCodes ...string) (
 	// User has all permissions if the count matches the number of required permissions
 	return count == len(permissionCodes), nil
 }
+
+/*************************************************************************************************************/
+// Administration
+/*************************************************************************************************************/
+
+// roleColumns selects a role along with its permission codes, for use with scanRole
+const roleColumns = `
+	r.id, r.role,
+	COALESCE(array_agg(p.code ORDER BY p.code) FILTER (WHERE p.code IS NOT NULL), '{}')
+	FROM roles r
+	LEFT JOIN roles_permissions rp ON rp.role_id = r.id
+	LEFT JOIN permissions p ON p.id = rp.permission_id`
+
+func scanRole(row interface{ Scan(...any) error }) (*Role, error) {
+	var role Role
+	var permissions []string
+	if err := row.Scan(&role.ID, &role.Role, pq.Array(&permissions)); err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	return &role, nil
+}
+
+// GetAll - Retrieve every role with its permissions, ordered by name
+func (m *RoleModel) GetAll() ([]*Role, error) {
+	query := `SELECT` + roleColumns + `
+		GROUP BY r.id
+		ORDER BY r.role`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Get - Retrieve a role with its permissions
+func (m *RoleModel) Get(id int64) (*Role, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT` + roleColumns + `
+		WHERE r.id = $1
+		GROUP BY r.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	role, err := scanRole(m.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// Insert - Create a role. Its permissions are assigned separately with PermissionModel.AssignToRole.
+func (m *RoleModel) Insert(role *Role) error {
+	query := `
+		INSERT INTO roles (role)
+		VALUES ($1)
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := m.DB.QueryRowContext(ctx, query, role.Role).Scan(&role.ID); err != nil {
+		if isDuplicateKeyViolation(err) {
+			return ErrDuplicateValue
+		}
+		return err
+	}
+
+	if role.Permissions == nil {
+		role.Permissions = Permissions{}
+	}
+
+	return nil
+}
+
+// Update - Rename a role. The Admin role cannot be renamed, which returns ErrAdminRole.
+func (m *RoleModel) Update(role *Role) error {
+	query := `
+		UPDATE roles
+		SET role = $1
+		WHERE id = $2 AND role <> $3
+		RETURNING id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, role.Role, role.ID, AdminRole).Scan(&role.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return m.adminOrNotFound(ctx, role.ID)
+		case isDuplicateKeyViolation(err):
+			return ErrDuplicateValue
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete - Remove a role, taking it away from every user and dropping its permissions. The Admin role
+// cannot be deleted, which returns ErrAdminRole.
+func (m *RoleModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `DELETE FROM roles WHERE id = $1 AND role <> $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, AdminRole)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return m.adminOrNotFound(ctx, id)
+	}
+
+	return nil
+}
+
+// adminOrNotFound explains why a statement guarded against the Admin role matched nothing
+func (m *RoleModel) adminOrNotFound(ctx context.Context, id int64) error {
+	var role string
+	err := m.DB.QueryRowContext(ctx, `SELECT role FROM roles WHERE id = $1`, id).Scan(&role)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrRecordNotFound
+	case err != nil:
+		return err
+	case role == AdminRole:
+		return ErrAdminRole
+	default:
+		return ErrRecordNotFound
+	}
+}
+
+// RemoveFromUser - Take roles away from a user. Removing the Admin role from the last active user that
+// holds it nationally returns ErrLastAdmin and removes nothing.
+func (m *RoleModel) RemoveFromUser(userID int64, roles ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if slices.Contains(roles, AdminRole) {
+		if err := guardLastAdmin(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		DELETE FROM roles_users ru
+		USING roles r
+		WHERE ru.role_id = r.id AND ru.user_id = $1 AND r.role = ANY($2)`
+
+	if _, err := tx.ExecContext(ctx, query, userID, pq.Array(roles)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// guardLastAdmin returns ErrLastAdmin when userID is the last active user holding the Admin role
+// nationally, so that removing, narrowing or deleting them would leave nobody able to administer the
+// system. The Admin role row stays locked until the transaction ends, so two requests cannot each take
+// away a different one of the last two admins.
+func guardLastAdmin(ctx context.Context, tx *sql.Tx, userID int64) error {
+	var adminID int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE role = $1 FOR UPDATE`, AdminRole).Scan(&adminID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	last, err := isLastAdmin(ctx, tx, adminID, userID)
+	if err != nil {
+		return err
+	}
+	if last {
+		return ErrLastAdmin
+	}
+
+	return nil
+}
+
+// isLastAdmin reports whether userID holds the Admin role nationally and no other active user does
+func isLastAdmin(ctx context.Context, tx *sql.Tx, adminID, userID int64) (bool, error) {
+	query := `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM roles_users
+				WHERE role_id = $1 AND user_id = $2 AND region_id IS NULL AND formation_id IS NULL
+			),
+			NOT EXISTS (
+				SELECT 1
+				FROM roles_users ru
+				INNER JOIN users u ON u.id = ru.user_id
+				WHERE ru.role_id = $1 AND ru.user_id <> $2
+				AND ru.region_id IS NULL AND ru.formation_id IS NULL
+				AND u.is_deleted = FALSE AND u.is_activated = TRUE
+			)`
+
+	var isAdmin, noOthers bool
+	if err := tx.QueryRowContext(ctx, query, adminID, userID).Scan(&isAdmin, &noOthers); err != nil {
+		return false, err
+	}
+
+	return isAdmin && noOthers, nil
+}
@@ -1,19 +1,158 @@
 // FileName: internal/data/roles_users.go
 package data
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"github.com/lib/pq"
+)
 
 /************************************************************************************************************/
 // RoleUser Declarations
 /************************************************************************************************************/
 
-// RoleUser struct to represent the many-to-many relationship between roles and users
+// RoleUser struct to represent the many-to-many relationship between roles and users. A role held with
+// a RegionID or FormationID only applies inside that region or formation; with neither it is national.
 type RoleUser struct {
-	RoleID int64 `json:"role_id"`
-	UserID int64 `json:"user_id"`
+	RoleID      int64  `json:"role_id"`
+	UserID      int64  `json:"user_id"`
+	Role        string `json:"role"`
+	RegionID    *int64 `json:"region_id"`
+	FormationID *int64 `json:"formation_id"`
 }
 
 // RoleUserModel struct to interact with the roles_users table in the database
 type RoleUserModel struct {
 	DB *sql.DB
 }
+
+// ValidateRoleScope checks that a role is limited to at most one region or formation
+func ValidateRoleScope(v *validator.Validator, regionID, formationID *int64) {
+	v.Check(regionID == nil || formationID == nil, "formation_id", "must not be provided together with region_id")
+	v.Check(regionID == nil || *regionID > 0, "region_id", "must be greater than zero")
+	v.Check(formationID == nil || *formationID > 0, "formation_id", "must be greater than zero")
+}
+
+/*************************************************************************************************************/
+// Methods
+/*************************************************************************************************************/
+
+// GetAllForUser - Retrieve the roles held by a user along with the region or formation each is held in
+func (m *RoleUserModel) GetAllForUser(userID int64) ([]*RoleUser, error) {
+	query := `
+		SELECT ru.role_id, ru.user_id, r.role, ru.region_id, ru.formation_id
+		FROM roles_users ru
+		INNER JOIN roles r ON r.id = ru.role_id
+		WHERE ru.user_id = $1
+		ORDER BY r.role`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*RoleUser{}
+	for rows.Next() {
+		var role RoleUser
+		if err := rows.Scan(&role.RoleID, &role.UserID, &role.Role, &role.RegionID, &role.FormationID); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Assign - Give a user roles limited to a region or formation, or nationally when both are nil. Roles
+// the user already holds take on the new scope. Returns ErrForeignKeyViolation for an unknown region
+// or formation, and ErrLastAdmin when the Admin role of the last national admin would be narrowed.
+func (m *RoleUserModel) Assign(userID int64, regionID, formationID *int64, roles ...string) error {
+	query := `
+		INSERT INTO roles_users (user_id, role_id, region_id, formation_id)
+		SELECT $1, r.id, $2, $3
+		FROM roles r
+		WHERE r.role = ANY($4)
+		ON CONFLICT (role_id, user_id) DO UPDATE
+		SET region_id = EXCLUDED.region_id, formation_id = EXCLUDED.formation_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if slices.Contains(roles, AdminRole) && (regionID != nil || formationID != nil) {
+		if err := guardLastAdmin(ctx, tx, userID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, query, userID, regionID, formationID, pq.Array(roles))
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrForeignKeyViolation
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetScope - Work out where a user may use a permission from the roles that grant it. Any role held
+// nationally makes the scope national; otherwise it covers the regions and formations the roles are
+// held in.
+func (m *RoleUserModel) GetScope(userID int64, permissionCode string) (*Scope, error) {
+	query := `
+		SELECT ru.region_id, ru.formation_id
+		FROM roles_users ru
+		INNER JOIN roles_permissions rp ON rp.role_id = ru.role_id
+		INNER JOIN permissions p ON p.id = rp.permission_id
+		WHERE ru.user_id = $1 AND p.code = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, permissionCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scope := &Scope{RegionIDs: []int64{}, FormationIDs: []int64{}}
+	for rows.Next() {
+		var regionID, formationID *int64
+		if err := rows.Scan(&regionID, &formationID); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case regionID != nil:
+			scope.RegionIDs = append(scope.RegionIDs, *regionID)
+		case formationID != nil:
+			scope.FormationIDs = append(scope.FormationIDs, *formationID)
+		default:
+			scope.National = true
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return scope, nil
+}
@@ -0,0 +1,65 @@
+// FileName: internal/data/scopes.go
+package data
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+/************************************************************************************************************/
+// Scope Declarations
+/************************************************************************************************************/
+
+// Scope limits the officers, training sessions and enrollments a user may work with to the regions and
+// formations their roles are held in. A nil Scope, or one with National set, places no limit.
+type Scope struct {
+	National     bool    `json:"national"`
+	RegionIDs    []int64 `json:"region_ids"`
+	FormationIDs []int64 `json:"formation_ids"`
+}
+
+// Includes reports whether a record in the given region and formation falls inside the scope
+func (s *Scope) Includes(regionID, formationID int64) bool {
+	if s == nil || s.National {
+		return true
+	}
+	return slices.Contains(s.RegionIDs, regionID) || slices.Contains(s.FormationIDs, formationID)
+}
+
+// Limited reports whether the scope places any limit at all
+func (s *Scope) Limited() bool {
+	return s != nil && !s.National
+}
+
+// args returns the three query arguments read by scopeCondition
+func (s *Scope) args() []any {
+	if s == nil || s.National {
+		return []any{true, pq.Array([]int64{}), pq.Array([]int64{})}
+	}
+	return []any{false, pq.Array(s.RegionIDs), pq.Array(s.FormationIDs)}
+}
+
+// scopeCondition returns a SQL condition for a scope whose args are bound from $n. Each unit is a pair of
+// region and formation column expressions; the row is in scope when any unit falls inside the scope.
+func scopeCondition(n int, units ...[2]string) string {
+	conditions := []string{fmt.Sprintf("$%d::boolean", n)}
+	for _, unit := range units {
+		conditions = append(conditions,
+			fmt.Sprintf("%s = ANY($%d::bigint[])", unit[0], n+1),
+			fmt.Sprintf("%s = ANY($%d::bigint[])", unit[1], n+2),
+		)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// unitColumns are the region and formation columns of officers and training sessions
+var unitColumns = [2]string{"region_id", "formation_id"}
+
+// enrollmentUnits place an enrollment in scope when either its officer or its session is
+var enrollmentUnits = [][2]string{
+	{"(SELECT o.region_id FROM officers o WHERE o.id = officer_id)", "(SELECT o.formation_id FROM officers o WHERE o.id = officer_id)"},
+	{"(SELECT s.region_id FROM training_sessions s WHERE s.id = session_id)", "(SELECT s.formation_id FROM training_sessions s WHERE s.id = session_id)"},
+}
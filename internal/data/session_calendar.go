@@ -0,0 +1,118 @@
+// FileName: internal/data/session_calendar.go
+package data
+
+import (
+	"context"
+	"time"
+)
+
+/************************************************************************************************************/
+// Session Calendar Declarations
+/************************************************************************************************************/
+
+// CalendarEvent is a training session as it appears in a calendar feed, or in an officer's list of
+// upcoming sessions
+type CalendarEvent struct {
+	SessionID       int64     `json:"session_id"`
+	WorkshopName    string    `json:"workshop_name"`
+	FacilitatorName string    `json:"facilitator_name"`
+	SessionDate     time.Time `json:"session_date"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	Location        *string   `json:"location,omitempty"`
+	Notes           *string   `json:"notes,omitempty"`
+	Cancelled       bool      `json:"cancelled"`  // the session was cancelled, or the officer no longer holds a seat in it
+	Waitlisted      bool      `json:"waitlisted"` // the officer is on the session waitlist
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CalendarFilters narrows the sessions included in a calendar feed. Sessions before From are left out
+// so long-running subscriptions stay small. OfficerID limits the feed to sessions the officer is
+// enrolled in, and Scope to sessions in the given regions and formations.
+type CalendarFilters struct {
+	FacilitatorID *int64
+	WorkshopID    *int64
+	FormationID   *int64
+	RegionID      *int64
+	StatusID      *int64
+	OfficerID     *int64
+	SessionDate   *time.Time
+	From          time.Time
+	Scope         *Scope
+}
+
+// GetCalendarEvents returns the sessions matching the filters in chronological order. Cancelled
+// sessions are included so subscribed calendars remove them.
+func (m *TrainingSessionModel) GetCalendarEvents(filters CalendarFilters) ([]*CalendarEvent, error) {
+	query := `
+		SELECT s.id, w.workshop_name, CONCAT_WS(' ', u.first_name, u.last_name), s.session_date, s.start_time, s.end_time, s.location, s.notes,
+			NOT (` + activeSessionCondition + `) OR (te.id IS NOT NULL AND es.status <> '` + EnrollmentStatusWaitlisted + `' AND NOT (` + activeEnrollmentCondition + `)),
+			COALESCE(es.status = '` + EnrollmentStatusWaitlisted + `', false),
+			GREATEST(s.updated_at, COALESCE(te.updated_at, s.updated_at))
+		FROM training_sessions s
+		INNER JOIN workshops w ON w.id = s.workshop_id
+		INNER JOIN users u ON u.id = s.facilitator_id
+		INNER JOIN training_status ts ON ts.id = s.training_status_id
+		LEFT JOIN training_enrollments te ON te.session_id = s.id AND te.officer_id = $6
+		LEFT JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+		LEFT JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		WHERE (s.facilitator_id = $1 OR $1 IS NULL)
+		AND (s.workshop_id = $2 OR $2 IS NULL)
+		AND (s.formation_id = $3 OR $3 IS NULL)
+		AND (s.region_id = $4 OR $4 IS NULL)
+		AND (s.training_status_id = $5 OR $5 IS NULL)
+		AND ($6::bigint IS NULL OR te.id IS NOT NULL)
+		AND ($7::date IS NULL OR s.session_date = $7::date)
+		AND s.session_date >= $8::date
+		AND ` + scopeCondition(9, [2]string{"s.region_id", "s.formation_id"}) + `
+		ORDER BY s.session_date, s.start_time, s.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := append([]any{
+		filters.FacilitatorID,
+		filters.WorkshopID,
+		filters.FormationID,
+		filters.RegionID,
+		filters.StatusID,
+		filters.OfficerID,
+		filters.SessionDate,
+		filters.From,
+	}, filters.Scope.args()...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*CalendarEvent{}
+
+	for rows.Next() {
+		var event CalendarEvent
+		err := rows.Scan(
+			&event.SessionID,
+			&event.WorkshopName,
+			&event.FacilitatorName,
+			&event.SessionDate,
+			&event.StartTime,
+			&event.EndTime,
+			&event.Location,
+			&event.Notes,
+			&event.Cancelled,
+			&event.Waitlisted,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,202 @@
+// FileName: internal/data/session_conflicts.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/************************************************************************************************************/
+// Session Conflict Declarations
+/************************************************************************************************************/
+
+// Kinds of scheduling conflict between two training sessions
+const (
+	SessionConflictFacilitator = "facilitator"
+	SessionConflictLocation    = "location"
+)
+
+// activeSessionCondition excludes cancelled sessions, which never conflict with anything. Both
+// seeded spellings of the status are matched by comparing in lower case.
+const activeSessionCondition = `LOWER(ts.status) <> '` + TrainingStatusCancelled + `'`
+
+// ScheduleConflictError is returned by Insert and Update when a session overlaps another active
+// session on the same day that has the same facilitator or is held at the same location.
+type ScheduleConflictError struct {
+	FacilitatorSessionIDs []int64
+	LocationSessionIDs    []int64
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("schedule conflict: facilitator sessions %v, location sessions %v", e.FacilitatorSessionIDs, e.LocationSessionIDs)
+}
+
+// SessionConflict is a pair of existing sessions that overlap, as reported by GetConflicts
+type SessionConflict struct {
+	Type                 string    `json:"type"`
+	SessionDate          time.Time `json:"session_date"`
+	SessionID            int64     `json:"session_id"`
+	ConflictingSessionID int64     `json:"conflicting_session_id"`
+	FacilitatorID        *int64    `json:"facilitator_id,omitempty"`
+	Location             *string   `json:"location,omitempty"`
+}
+
+// checkScheduleConflicts returns a *ScheduleConflictError if the session would overlap another active
+// session. Checks for the same day are serialised with an advisory lock so two concurrent requests
+// cannot both book the same facilitator or room. Cancelled sessions are never checked.
+func checkScheduleConflicts(ctx context.Context, tx *sql.Tx, session *TrainingSession) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('training_sessions:' || $1::date::text))`, session.SessionDate)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT id, facilitator_clash, location_clash
+		FROM (
+			SELECT s.id,
+				s.facilitator_id = $5 AS facilitator_clash,
+				COALESCE(NULLIF(TRIM($6::text), '') IS NOT NULL AND LOWER(TRIM(s.location)) = LOWER(TRIM($6::text)), false) AS location_clash
+			FROM training_sessions s
+			INNER JOIN training_status ts ON ts.id = s.training_status_id
+			WHERE s.id <> $1
+			AND s.session_date = $2::date
+			AND s.start_time < $4::time AND $3::time < s.end_time
+			AND ` + activeSessionCondition + `
+		) overlapping
+		WHERE (facilitator_clash OR location_clash)
+		AND NOT EXISTS (SELECT 1 FROM training_status ts WHERE ts.id = $7 AND NOT (` + activeSessionCondition + `))
+		ORDER BY id`
+
+	rows, err := tx.QueryContext(ctx, query,
+		session.ID,
+		session.SessionDate,
+		session.StartTime,
+		session.EndTime,
+		session.FacilitatorID,
+		session.Location,
+		session.TrainingStatusID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	conflict := &ScheduleConflictError{}
+
+	for rows.Next() {
+		var (
+			id                              int64
+			facilitatorClash, locationClash bool
+		)
+		if err := rows.Scan(&id, &facilitatorClash, &locationClash); err != nil {
+			return err
+		}
+
+		if facilitatorClash {
+			conflict.FacilitatorSessionIDs = append(conflict.FacilitatorSessionIDs, id)
+		}
+		if locationClash {
+			conflict.LocationSessionIDs = append(conflict.LocationSessionIDs, id)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(conflict.FacilitatorSessionIDs) > 0 || len(conflict.LocationSessionIDs) > 0 {
+		return conflict
+	}
+
+	return nil
+}
+
+// GetConflicts audits existing data for pairs of active sessions that overlap on the same day and share
+// a facilitator or location. conflictType limits the report to one kind of conflict when not empty. A pair
+// is reported when either of its sessions falls inside filters.Scope.
+func (m *TrainingSessionModel) GetConflicts(conflictType string, from, to *time.Time, filters Filters) ([]*SessionConflict, MetaData, error) {
+	conflictScope := scopeCondition(6, [2]string{"a.region_id", "a.formation_id"}, [2]string{"b.region_id", "b.formation_id"})
+
+	query := fmt.Sprintf(`
+		WITH active AS (
+			SELECT s.id, s.facilitator_id, s.session_date, s.start_time, s.end_time, s.location, s.region_id, s.formation_id
+			FROM training_sessions s
+			INNER JOIN training_status ts ON ts.id = s.training_status_id
+			WHERE `+activeSessionCondition+`
+			AND ($2::date IS NULL OR s.session_date >= $2::date)
+			AND ($3::date IS NULL OR s.session_date <= $3::date)
+		), conflicts AS (
+			SELECT '`+SessionConflictFacilitator+`' AS type, a.session_date, a.id AS session_id, b.id AS conflicting_session_id,
+				a.facilitator_id, NULL::text AS location
+			FROM active a
+			INNER JOIN active b ON a.id < b.id
+				AND a.session_date = b.session_date
+				AND a.start_time < b.end_time AND b.start_time < a.end_time
+				AND a.facilitator_id = b.facilitator_id
+			WHERE %[1]s
+			UNION ALL
+			SELECT '`+SessionConflictLocation+`', a.session_date, a.id, b.id,
+				NULL::bigint, a.location
+			FROM active a
+			INNER JOIN active b ON a.id < b.id
+				AND a.session_date = b.session_date
+				AND a.start_time < b.end_time AND b.start_time < a.end_time
+				AND NULLIF(TRIM(a.location), '') IS NOT NULL
+				AND LOWER(TRIM(a.location)) = LOWER(TRIM(b.location))
+			WHERE %[1]s
+		)
+		SELECT COUNT(*) OVER(), type, session_date, session_id, conflicting_session_id, facilitator_id, location
+		FROM conflicts
+		WHERE ($1 = '' OR type = $1)
+		ORDER BY %[2]s %[3]s, session_id ASC, conflicting_session_id ASC
+		LIMIT $4 OFFSET $5`, conflictScope, filters.sortColumn(), filters.sortDirection())
+
+	var fromArg, toArg interface{}
+	if from != nil {
+		fromArg = *from
+	}
+	if to != nil {
+		toArg = *to
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	args := append([]any{conflictType, fromArg, toArg, filters.limit(), filters.offset()}, filters.Scope.args()...)
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+	defer rows.Close()
+
+	var (
+		conflicts    []*SessionConflict
+		totalRecords int
+	)
+
+	for rows.Next() {
+		var conflict SessionConflict
+		if err := rows.Scan(
+			&totalRecords,
+			&conflict.Type,
+			&conflict.SessionDate,
+			&conflict.SessionID,
+			&conflict.ConflictingSessionID,
+			&conflict.FacilitatorID,
+			&conflict.Location,
+		); err != nil {
+			return nil, MetaData{}, err
+		}
+		conflicts = append(conflicts, &conflict)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return conflicts, metadata, nil
+}
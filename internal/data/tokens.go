@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
+	"errors"
 	"time"
 
 	"github.com/Pedro-J-Kukul/police_training/internal/validator"
@@ -16,9 +17,15 @@ import (
 // Declarations
 /*************************************************************************************************************/
 const (
-	ScopeActivation     = "activation"     // Scope for account activation tokens
-	ScopeAuthentication = "authentication" // Scope for authentication tokens
-	ScopePasswordReset  = "password_reset" // Scope for password reset tokens
+	ScopeActivation        = "activation"          // Scope for account activation tokens
+	ScopeAuthentication    = "authentication"      // Scope for authentication tokens
+	ScopePasswordReset     = "password_reset"      // Scope for password reset tokens
+	ScopeCalendar          = "calendar"            // Scope for calendar feed subscription tokens
+	ScopeRefresh           = "refresh"             // Scope for refresh tokens that are exchanged for new authentication tokens
+	ScopeTwoFactor         = "two_factor"          // Scope for tokens proving the password step of a two-factor login
+	ScopeInvitation        = "invitation"          // Scope for tokens that let an invited user choose their password
+	ScopeEmailChange       = "email_change"        // Scope for tokens sent to a new email address to confirm it
+	ScopeEmailChangeCancel = "email_change_cancel" // Scope for tokens sent to the old email address to cancel a change
 )
 
 // Define our token
@@ -28,6 +35,21 @@ type Token struct {
 	UserID    int64     `json:"-"`      // ID of the user the token belongs to
 	Expiry    time.Time `json:"expiry"` // Expiry time of the token
 	Scope     string    `json:"-"`      // Scope of the token (not exposed in JSON)
+	IPAddress string    `json:"-"`      // Address of the client the token was issued to
+	UserAgent string    `json:"-"`      // User agent of the client the token was issued to
+	FamilyID  *int64    `json:"-"`      // Login the token was issued for, shared by its access and refresh tokens
+}
+
+// Session describes an active authentication token without revealing it, so users can see where they
+// are logged in and revoke tokens they do not recognise
+type Session struct {
+	ID         int64      `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Expiry     time.Time  `json:"expiry"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"`
 }
 
 // TokenModel struct wraps a database connection pool
@@ -82,21 +104,164 @@ func (m *TokenModel) New(userID int64, ttl time.Duration, scope string) (*Token,
 	return token, err     // Return the token and any insertion error
 }
 
+// NewAuthentication starts a new token family for a user, issuing a short-lived authentication token and
+// a long-lived refresh token that are recorded against the client they were issued to
+func (m *TokenModel) NewAuthentication(userID int64, accessTTL, refreshTTL time.Duration, ipAddress, userAgent string) (*Token, *Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var familyID int64
+	if err := tx.QueryRowContext(ctx, `SELECT nextval('token_family_seq')`).Scan(&familyID); err != nil {
+		return nil, nil, err
+	}
+
+	access, refresh, err := insertTokenPair(ctx, tx, userID, familyID, accessTTL, refreshTTL, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return access, refresh, tx.Commit()
+}
+
+// Refresh exchanges an unused refresh token for a new authentication and refresh token in the same family.
+// The refresh token is marked used and the family's earlier authentication tokens are revoked. Presenting
+// a refresh token that was already used means it has leaked, so the whole family is revoked and
+// ErrTokenReused returned. Refresh tokens of deleted, deactivated or locked users are revoked along with
+// their family and reported as ErrRecordNotFound.
+func (m *TokenModel) Refresh(refreshPlaintext string, accessTTL, refreshTTL time.Duration, ipAddress, userAgent string) (*Token, *Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT t.user_id, t.family_id, t.used_at IS NOT NULL,
+			u.is_activated AND NOT u.is_deleted AND (u.locked_until IS NULL OR u.locked_until <= $3)
+		FROM tokens t
+		INNER JOIN users u ON u.id = t.user_id
+		WHERE t.hash = $1 AND t.scope = $2 AND t.expiry > $3
+		FOR UPDATE OF t`
+
+	refreshHash := sha256.Sum256([]byte(refreshPlaintext))
+
+	var (
+		userID     int64
+		familyID   int64
+		used       bool
+		userActive bool
+	)
+
+	err = tx.QueryRowContext(ctx, query, refreshHash[:], ScopeRefresh, time.Now()).Scan(&userID, &familyID, &used, &userActive)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, nil, ErrRecordNotFound
+		default:
+			return nil, nil, err
+		}
+	}
+
+	if used || !userActive {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tokens WHERE family_id = $1`, familyID); err != nil {
+			return nil, nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, nil, err
+		}
+		if !used {
+			return nil, nil, ErrRecordNotFound
+		}
+		return nil, nil, ErrTokenReused
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tokens SET used_at = $2 WHERE hash = $1`, refreshHash[:], time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE family_id = $1 AND scope = $2`, familyID, ScopeAuthentication)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	access, refresh, err := insertTokenPair(ctx, tx, userID, familyID, accessTTL, refreshTTL, ipAddress, userAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return access, refresh, tx.Commit()
+}
+
+// insertTokenPair generates and stores an authentication and refresh token in a family
+func insertTokenPair(ctx context.Context, tx *sql.Tx, userID, familyID int64, accessTTL, refreshTTL time.Duration, ipAddress, userAgent string) (*Token, *Token, error) {
+	access, err := generateToken(userID, accessTTL, ScopeAuthentication)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refresh, err := generateToken(userID, refreshTTL, ScopeRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, token := range []*Token{access, refresh} {
+		token.IPAddress = ipAddress
+		token.UserAgent = userAgent
+		token.FamilyID = &familyID
+
+		if err := insertToken(ctx, tx, token); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return access, refresh, nil
+}
+
 // Insert adds a new token to the database
 func (m *TokenModel) Insert(token *Token) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Create a context with a 3-second timeout
+	defer cancel()                                                          // Ensure the context is cancelled to free resources
+
+	return insertToken(ctx, m.DB, token)
+}
+
+// insertToken adds a token to the database through a connection pool or transaction
+func insertToken(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}, token *Token) error {
 	// SQL query to insert a new token into the tokens table
 	query := `
-		INSERT INTO tokens (hash, user_id, expiry, scope)
-		VALUES ($1, $2, $3, $4)`
+		INSERT INTO tokens (hash, user_id, expiry, scope, ip_address, user_agent, family_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	// Prepare the arguments for the query
-	args := []any{token.Hash, token.UserID, token.Expiry, token.Scope}
+	args := []any{token.Hash, token.UserID, token.Expiry, token.Scope, token.IPAddress, token.UserAgent, token.FamilyID}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Create a context with a 3-second timeout
-	defer cancel()                                                          // Ensure the context is cancelled to free resources
+	_, err := db.ExecContext(ctx, query, args...) // Execute the insert query
+	return err                                    // Return any error that occurred during execution
+}
 
-	_, err := m.DB.ExecContext(ctx, query, args...) // Execute the insert query
-	return err                                      // Return any error that occurred during execution
+// revokeAllLogins removes every authentication and refresh token of a user, ending all of their token
+// families, through a connection pool or transaction
+func revokeAllLogins(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}, userID int64) error {
+	query := `
+		DELETE FROM tokens
+		WHERE user_id = $1 AND scope IN ($2, $3)`
+
+	_, err := db.ExecContext(ctx, query, userID, ScopeAuthentication, ScopeRefresh)
+	return err
 }
 
 // DeleteAllForUser removes all tokens for a specific user and scope from the database
@@ -112,3 +277,118 @@ func (m *TokenModel) DeleteAllForUser(scope string, userID int64) error {
 	_, err := m.DB.ExecContext(ctx, query, scope, userID) // Execute the delete query
 	return err                                            // Return any error that occurred during execution
 }
+
+// DeleteForToken removes a token, such as the one a user logs out with, along with the rest of its family
+func (m *TokenModel) DeleteForToken(scope, tokenPlaintext string) error {
+	query := `
+		DELETE FROM tokens
+		WHERE (scope = $1 AND hash = $2)
+		OR family_id IN (SELECT family_id FROM tokens WHERE scope = $1 AND hash = $2)`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, scope, tokenHash[:])
+	return err
+}
+
+// Touch records that a token, and the rest of its family, has just been used. Writes are limited to one
+// a minute per token, so busy clients do not rewrite the rows on every request.
+func (m *TokenModel) Touch(scope, tokenPlaintext string) error {
+	query := `
+		UPDATE tokens
+		SET last_used_at = $3
+		WHERE ((scope = $1 AND hash = $2) OR family_id IN (SELECT family_id FROM tokens WHERE scope = $1 AND hash = $2))
+		AND (last_used_at IS NULL OR last_used_at < $3 - INTERVAL '1 minute')`
+
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, scope, tokenHash[:], time.Now())
+	return err
+}
+
+// GetAllSessions lists a user's active logins, most recently used first. A login is represented by the
+// unused refresh token of its family, or by an authentication token issued outside a family, and lasts
+// as long as that token. The session for currentPlaintext, if any, is marked as current.
+func (m *TokenModel) GetAllSessions(userID int64, currentPlaintext string) ([]*Session, error) {
+	query := `
+		SELECT t.id,
+			COALESCE((SELECT MIN(f.created_at) FROM tokens f WHERE f.family_id = t.family_id), t.created_at),
+			t.last_used_at, t.expiry, t.ip_address, t.user_agent,
+			COALESCE(t.hash = $4 OR t.family_id = (SELECT c.family_id FROM tokens c WHERE c.hash = $4), false)
+		FROM tokens t
+		WHERE t.user_id = $1 AND t.expiry > $5
+		AND ((t.scope = $2 AND t.used_at IS NULL) OR (t.scope = $3 AND t.family_id IS NULL))
+		ORDER BY COALESCE(t.last_used_at, t.created_at) DESC, t.id DESC`
+
+	currentHash := sha256.Sum256([]byte(currentPlaintext))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, ScopeRefresh, ScopeAuthentication, currentHash[:], time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(
+			&session.ID,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.Expiry,
+			&session.IPAddress,
+			&session.UserAgent,
+			&session.Current,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession revokes one of a user's logins by its session id, along with every token in its family.
+// Returns ErrRecordNotFound when the user has no such session.
+func (m *TokenModel) DeleteSession(id, userID int64) error {
+	query := `
+		DELETE FROM tokens
+		WHERE user_id = $2
+		AND (
+			(id = $1 AND scope IN ($3, $4))
+			OR family_id IN (SELECT family_id FROM tokens WHERE id = $1 AND user_id = $2 AND scope IN ($3, $4))
+		)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, userID, ScopeAuthentication, ScopeRefresh)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -17,17 +17,21 @@ import (
 
 // TrainingEnrollment struct to represent a training enrollment in the system
 type TrainingEnrollment struct {
-	ID                 int64      `json:"id"`
-	OfficerID          int64      `json:"officer_id"`
-	SessionID          int64      `json:"session_id"`
-	EnrollmentStatusID int64      `json:"enrollment_status_id"`
-	AttendanceStatusID *int64     `json:"attendance_status_id,omitempty"`
-	ProgressStatusID   int64      `json:"progress_status_id"`
-	CompletionDate     *time.Time `json:"completion_date,omitempty"`
-	CertificateIssued  bool       `json:"certificate_issued"`
-	CertificateNumber  *string    `json:"certificate_number,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
+	ID                  int64      `json:"id"`
+	OfficerID           int64      `json:"officer_id"`
+	SessionID           int64      `json:"session_id"`
+	EnrollmentStatusID  int64      `json:"enrollment_status_id"`
+	AttendanceStatusID  *int64     `json:"attendance_status_id,omitempty"`
+	ProgressStatusID    int64      `json:"progress_status_id"`
+	CompletionDate      *time.Time `json:"completion_date,omitempty"`
+	CertificateIssued   bool       `json:"certificate_issued"`
+	CertificateNumber   *string    `json:"certificate_number,omitempty"`
+	CertificateIssuedBy *int64     `json:"certificate_issued_by,omitempty"`
+	CertificateIssuedAt *time.Time `json:"certificate_issued_at,omitempty"`
+	CertificateRevoked  bool       `json:"certificate_revoked"`
+	WaitlistPosition    *int       `json:"waitlist_position,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 // TrainingEnrollmentModel struct to interact with the training_enrollments table in the database
@@ -43,7 +47,7 @@ func ValidateTrainingEnrollment(v *validator.Validator, enrollment *TrainingEnro
 	v.Check(enrollment.ProgressStatusID > 0, "progress_status_id", "must be provided")
 
 	if enrollment.CertificateNumber != nil {
-		v.Check(len(*enrollment.CertificateNumber) <= 100, "certificate_number", "must not exceed 100 characters")
+		v.Check(len(*enrollment.CertificateNumber) <= 200, "certificate_number", "must not exceed 200 characters")
 	}
 
 	// If certificate is issued, completion date should be set
@@ -52,17 +56,261 @@ func ValidateTrainingEnrollment(v *validator.Validator, enrollment *TrainingEnro
 	}
 }
 
-// Insert creates a new training enrollment.
-func (m *TrainingEnrollmentModel) Insert(enrollment *TrainingEnrollment) error {
+// activeEnrollmentCondition matches enrollments that hold a seat in their session. It expects the
+// enrollment_statuses and progress_statuses tables to be joined as es and ps.
+const activeEnrollmentCondition = `es.status NOT IN ('` + EnrollmentStatusWaitlisted + `', '` + EnrollmentStatusCancelled + `')
+		AND ps.status <> '` + ProgressStatusWithdrawn + `'`
+
+// isActiveEnrollment reports whether an enrollment with the given statuses would hold a seat.
+func isActiveEnrollment(ctx context.Context, tx *sql.Tx, enrollmentStatusID, progressStatusID int64) (bool, error) {
 	query := `
-		INSERT INTO training_enrollments (officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at`
+		SELECT EXISTS (
+			SELECT 1
+			FROM enrollment_statuses es, progress_statuses ps
+			WHERE es.id = $1 AND ps.id = $2
+			AND ` + activeEnrollmentCondition + `
+		)`
+
+	var active bool
+	err := tx.QueryRowContext(ctx, query, enrollmentStatusID, progressStatusID).Scan(&active)
+	return active, err
+}
+
+// enrollmentStatusID looks up the id of an enrollment status by name inside a transaction.
+func enrollmentStatusID(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM enrollment_statuses WHERE status = $1`, name).Scan(&id)
+	return id, err
+}
+
+// lockSession locks the session row for the rest of the transaction and returns its capacity. Holding
+// the row lock serialises seat allocation and waitlist changes for the session. Every path locks the
+// session before the officer lock taken by checkOfficerClash, so single and bulk enrollments cannot
+// deadlock on each other.
+func lockSession(ctx context.Context, tx *sql.Tx, sessionID int64) (sql.NullInt64, error) {
+	var maxCapacity sql.NullInt64
+
+	err := tx.QueryRowContext(ctx, `SELECT max_capacity FROM training_sessions WHERE id = $1 FOR UPDATE`, sessionID).Scan(&maxCapacity)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return maxCapacity, ErrForeignKeyViolation
+		default:
+			return maxCapacity, err
+		}
+	}
+
+	return maxCapacity, nil
+}
+
+// seatsTaken counts the active enrollments in a session other than excludeID. It runs as its own
+// statement after lockSession so it sees every enrollment committed before the lock was granted.
+func seatsTaken(ctx context.Context, tx *sql.Tx, sessionID, excludeID int64) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM training_enrollments te
+		INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		WHERE te.session_id = $1 AND te.id <> $2
+		AND ` + activeEnrollmentCondition
+
+	var taken int64
+	err := tx.QueryRowContext(ctx, query, sessionID, excludeID).Scan(&taken)
+	return taken, err
+}
+
+// reserveSeat locks the session and returns ErrSessionFull when its active enrollments, other than
+// excludeID, already fill max_capacity, so two requests cannot both take the last seat.
+func reserveSeat(ctx context.Context, tx *sql.Tx, sessionID, excludeID int64) error {
+	maxCapacity, err := lockSession(ctx, tx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !maxCapacity.Valid {
+		return nil
+	}
+
+	taken, err := seatsTaken(ctx, tx, sessionID, excludeID)
+	if err != nil {
+		return err
+	}
+
+	if taken >= maxCapacity.Int64 {
+		return ErrSessionFull
+	}
+
+	return nil
+}
+
+// nextWaitlistPosition returns the position at the back of a session's waitlist. The session must
+// already be locked by the transaction.
+func nextWaitlistPosition(ctx context.Context, tx *sql.Tx, sessionID int64) (int, error) {
+	var position int
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(waitlist_position), 0) + 1
+		FROM training_enrollments
+		WHERE session_id = $1`, sessionID).Scan(&position)
+	return position, err
+}
+
+// placeOnWaitlist assigns the enrollment a waitlist position when its status is Waitlisted and clears
+// it otherwise. An enrollment keeps its existing position unless keep is false.
+func placeOnWaitlist(ctx context.Context, tx *sql.Tx, enrollment *TrainingEnrollment, keep bool) error {
+	waitlistedID, err := enrollmentStatusID(ctx, tx, EnrollmentStatusWaitlisted)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if enrollment.EnrollmentStatusID != waitlistedID {
+		enrollment.WaitlistPosition = nil
+		return nil
+	}
+
+	if keep && enrollment.WaitlistPosition != nil {
+		return nil
+	}
+
+	if _, err := lockSession(ctx, tx, enrollment.SessionID); err != nil {
+		return err
+	}
+
+	position, err := nextWaitlistPosition(ctx, tx, enrollment.SessionID)
+	if err != nil {
+		return err
+	}
+
+	enrollment.WaitlistPosition = &position
+	return nil
+}
+
+// ScheduleClashError is returned when an officer would hold seats in two sessions that overlap.
+type ScheduleClashError struct {
+	SessionIDs []int64
+}
+
+func (e *ScheduleClashError) Error() string {
+	return fmt.Sprintf("schedule clash with sessions %v", e.SessionIDs)
+}
+
+// checkOfficerClash returns a *ScheduleClashError if the officer holds a seat in another session, not
+// cancelled, that overlaps the enrollment's session. Checks for the same officer are serialised with
+// an advisory lock so two concurrent enrollments cannot both pass.
+func checkOfficerClash(ctx context.Context, tx *sql.Tx, enrollment *TrainingEnrollment) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('training_enrollments:officer:' || $1::bigint::text))`, enrollment.OfficerID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		SELECT other.id
+		FROM training_enrollments te
+		INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		INNER JOIN training_sessions other ON other.id = te.session_id
+		INNER JOIN training_status ts ON ts.id = other.training_status_id
+		INNER JOIN training_sessions s ON s.id = $2
+		WHERE te.officer_id = $1 AND te.id <> $3 AND other.id <> s.id
+		AND other.session_date = s.session_date
+		AND other.start_time < s.end_time AND s.start_time < other.end_time
+		AND ` + activeEnrollmentCondition + `
+		AND ` + activeSessionCondition + `
+		ORDER BY other.id`
+
+	rows, err := tx.QueryContext(ctx, query, enrollment.OfficerID, enrollment.SessionID, enrollment.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	clash := &ScheduleClashError{}
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		clash.SessionIDs = append(clash.SessionIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(clash.SessionIDs) > 0 {
+		return clash
+	}
 
+	return nil
+}
+
+// Insert creates a new training enrollment. When the session has no seats left the officer is placed
+// at the back of the session's waitlist instead, which callers can detect through WaitlistPosition.
+// Unless allowScheduleClash is set, a *ScheduleClashError is returned if the officer already holds a
+// seat in an overlapping session.
+func (m *TrainingEnrollmentModel) Insert(enrollment *TrainingEnrollment, allowScheduleClash bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	if err := m.DB.QueryRowContext(ctx, query,
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertEnrollment(ctx, tx, enrollment, allowScheduleClash); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertEnrollment runs the checks and seat allocation of Insert inside the caller's transaction.
+func insertEnrollment(ctx context.Context, tx *sql.Tx, enrollment *TrainingEnrollment, allowScheduleClash bool) error {
+	query := `
+		INSERT INTO training_enrollments (officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, waitlist_position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at`
+
+	if _, err := lockSession(ctx, tx, enrollment.SessionID); err != nil {
+		return err
+	}
+
+	active, err := isActiveEnrollment(ctx, tx, enrollment.EnrollmentStatusID, enrollment.ProgressStatusID)
+	if err != nil {
+		return err
+	}
+
+	if active && !allowScheduleClash {
+		if err := checkOfficerClash(ctx, tx, enrollment); err != nil {
+			return err
+		}
+	}
+
+	if active {
+		err := reserveSeat(ctx, tx, enrollment.SessionID, 0)
+		switch {
+		case errors.Is(err, ErrSessionFull):
+			waitlistedID, err := enrollmentStatusID(ctx, tx, EnrollmentStatusWaitlisted)
+			if err != nil {
+				switch {
+				case errors.Is(err, sql.ErrNoRows):
+					return ErrSessionFull
+				default:
+					return err
+				}
+			}
+			enrollment.EnrollmentStatusID = waitlistedID
+		case err != nil:
+			return err
+		}
+	}
+
+	if err := placeOnWaitlist(ctx, tx, enrollment, false); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRowContext(ctx, query,
 		enrollment.OfficerID,
 		enrollment.SessionID,
 		enrollment.EnrollmentStatusID,
@@ -71,6 +319,7 @@ func (m *TrainingEnrollmentModel) Insert(enrollment *TrainingEnrollment) error {
 		enrollment.CompletionDate,
 		enrollment.CertificateIssued,
 		enrollment.CertificateNumber,
+		enrollment.WaitlistPosition,
 	).Scan(&enrollment.ID, &enrollment.CreatedAt, &enrollment.UpdatedAt); err != nil {
 		switch {
 		case isDuplicateKeyViolation(err):
@@ -87,21 +336,30 @@ func (m *TrainingEnrollmentModel) Insert(enrollment *TrainingEnrollment) error {
 
 // Get retrieves a training enrollment by id.
 func (m *TrainingEnrollmentModel) Get(id int64) (*TrainingEnrollment, error) {
+	return m.GetInScope(id, nil)
+}
+
+// GetInScope retrieves a training enrollment by id, returning ErrRecordNotFound when neither its officer
+// nor its session is inside scope
+func (m *TrainingEnrollmentModel) GetInScope(id int64, scope *Scope) (*TrainingEnrollment, error) {
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
 
 	query := `
-		SELECT id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, created_at, updated_at
+		SELECT id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, certificate_issued_by, certificate_issued_at, certificate_revoked, waitlist_position, created_at, updated_at
 		FROM training_enrollments
-		WHERE id = $1`
+		WHERE id = $1
+		AND ` + scopeCondition(2, enrollmentUnits...)
 
 	var enrollment TrainingEnrollment
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+	args := append([]any{id}, scope.args()...)
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
 		&enrollment.ID,
 		&enrollment.OfficerID,
 		&enrollment.SessionID,
@@ -111,6 +369,10 @@ func (m *TrainingEnrollmentModel) Get(id int64) (*TrainingEnrollment, error) {
 		&enrollment.CompletionDate,
 		&enrollment.CertificateIssued,
 		&enrollment.CertificateNumber,
+		&enrollment.CertificateIssuedBy,
+		&enrollment.CertificateIssuedAt,
+		&enrollment.CertificateRevoked,
+		&enrollment.WaitlistPosition,
 		&enrollment.CreatedAt,
 		&enrollment.UpdatedAt,
 	)
@@ -128,20 +390,53 @@ func (m *TrainingEnrollmentModel) Get(id int64) (*TrainingEnrollment, error) {
 
 // GetAll returns training enrollments filtered by various criteria.
 func (m *TrainingEnrollmentModel) GetAll(officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID *int64, filters Filters) ([]*TrainingEnrollment, MetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var enrollments []*TrainingEnrollment
+	totalRecords, err := m.scanAll(ctx, officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID, filters, func(enrollment *TrainingEnrollment) error {
+		enrollments = append(enrollments, enrollment)
+		return nil
+	})
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return enrollments, metadata, nil
+}
+
+// Each calls fn with every training enrollment matching the criteria of GetAll, ignoring the page, as
+// each row is read, so exports never hold the whole list. It stops at the first error fn returns.
+func (m *TrainingEnrollmentModel) Each(officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID *int64, filters Filters, fn func(*TrainingEnrollment) error) error {
+	filters.Unpaged = true
+
+	// Rows are read only as fast as the client takes them, so allow longer than a single page
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := m.scanAll(ctx, officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID, filters, fn)
+	return err
+}
+
+// scanAll runs the query behind GetAll and Each, passing each enrollment to fn, and returns the total
+// number of matching records
+func (m *TrainingEnrollmentModel) scanAll(ctx context.Context, officerID, sessionID, enrollmentStatusID, attendanceStatusID, progressStatusID *int64, filters Filters, fn func(*TrainingEnrollment) error) (int, error) {
 	if filters.Sort == "" {
 		filters.Sort = "created_at"
 	}
 
 	query := fmt.Sprintf(`
-		SELECT COUNT(*) OVER(), id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, created_at, updated_at
+		SELECT COUNT(*) OVER(), id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, certificate_issued_by, certificate_issued_at, certificate_revoked, waitlist_position, created_at, updated_at
 		FROM training_enrollments
 		WHERE ($1 = 0 OR officer_id = $1)
 		AND ($2 = 0 OR session_id = $2)
 		AND ($3 = 0 OR enrollment_status_id = $3)
 		AND ($4 = 0 OR attendance_status_id = $4)
 		AND ($5 = 0 OR progress_status_id = $5)
+		AND %s
 		ORDER BY %s %s, id ASC
-		LIMIT $6 OFFSET $7`, filters.sortColumn(), filters.sortDirection())
+		LIMIT $6 OFFSET $7`, scopeCondition(8, enrollmentUnits...), filters.sortColumn(), filters.sortDirection())
 
 	officerArg := int64(0)
 	if officerID != nil {
@@ -168,20 +463,15 @@ func (m *TrainingEnrollmentModel) GetAll(officerID, sessionID, enrollmentStatusI
 		progressStatusArg = *progressStatusID
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	args := append([]any{officerArg, sessionArg, enrollmentStatusArg, attendanceStatusArg, progressStatusArg, filters.limit(), filters.offset()}, filters.Scope.args()...)
 
-	rows, err := m.DB.QueryContext(ctx, query, officerArg, sessionArg, enrollmentStatusArg, attendanceStatusArg, progressStatusArg, filters.limit(), filters.offset())
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 	defer rows.Close()
 
-	var (
-		enrollments  []*TrainingEnrollment
-		totalRecords int
-	)
-
+	totalRecords := 0
 	for rows.Next() {
 		var enrollment TrainingEnrollment
 		if err := rows.Scan(
@@ -195,42 +485,105 @@ func (m *TrainingEnrollmentModel) GetAll(officerID, sessionID, enrollmentStatusI
 			&enrollment.CompletionDate,
 			&enrollment.CertificateIssued,
 			&enrollment.CertificateNumber,
+			&enrollment.CertificateIssuedBy,
+			&enrollment.CertificateIssuedAt,
+			&enrollment.CertificateRevoked,
+			&enrollment.WaitlistPosition,
 			&enrollment.CreatedAt,
 			&enrollment.UpdatedAt,
 		); err != nil {
-			return nil, MetaData{}, err
+			return 0, err
+		}
+		if err := fn(&enrollment); err != nil {
+			return 0, err
 		}
-		enrollments = append(enrollments, &enrollment)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
-	return enrollments, metadata, nil
+	return totalRecords, nil
 }
 
-// Update modifies an existing training enrollment.
-func (m *TrainingEnrollmentModel) Update(enrollment *TrainingEnrollment) error {
+// Update modifies an existing training enrollment. Moving the enrollment into a session, or making it
+// active again, is refused with ErrSessionFull when that session has no seats left. Enrollments set to
+// Waitlisted join the back of the waitlist; any other status takes them off it. Schedule clashes are
+// checked as in Insert. Certificate fields are left alone; they change only through IssueCertificate and
+// the CertificateModel.
+func (m *TrainingEnrollmentModel) Update(enrollment *TrainingEnrollment, allowScheduleClash bool) error {
 	query := `
 		UPDATE training_enrollments
-		SET officer_id = $1, session_id = $2, enrollment_status_id = $3, attendance_status_id = $4, progress_status_id = $5, completion_date = $6, certificate_issued = $7, certificate_number = $8, updated_at = NOW()
-		WHERE id = $9
+		SET officer_id = $1, session_id = $2, enrollment_status_id = $3, attendance_status_id = $4, progress_status_id = $5, completion_date = $6, waitlist_position = $7, updated_at = NOW()
+		WHERE id = $8
 		RETURNING updated_at`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	if err := m.DB.QueryRowContext(ctx, query,
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		currentOfficerID int64
+		currentSessionID int64
+		currentlyActive  bool
+	)
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT te.officer_id, te.session_id, (`+activeEnrollmentCondition+`)
+		FROM training_enrollments te
+		INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		WHERE te.id = $1
+		FOR UPDATE OF te`, enrollment.ID).Scan(&currentOfficerID, &currentSessionID, &currentlyActive)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	active, err := isActiveEnrollment(ctx, tx, enrollment.EnrollmentStatusID, enrollment.ProgressStatusID)
+	if err != nil {
+		return err
+	}
+
+	changed := !currentlyActive || currentSessionID != enrollment.SessionID
+
+	if _, err := lockSession(ctx, tx, enrollment.SessionID); err != nil {
+		return err
+	}
+
+	if active && !allowScheduleClash && (changed || currentOfficerID != enrollment.OfficerID) {
+		if err := checkOfficerClash(ctx, tx, enrollment); err != nil {
+			return err
+		}
+	}
+
+	if active && changed {
+		if err := reserveSeat(ctx, tx, enrollment.SessionID, enrollment.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := placeOnWaitlist(ctx, tx, enrollment, currentSessionID == enrollment.SessionID); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRowContext(ctx, query,
 		enrollment.OfficerID,
 		enrollment.SessionID,
 		enrollment.EnrollmentStatusID,
 		enrollment.AttendanceStatusID,
 		enrollment.ProgressStatusID,
 		enrollment.CompletionDate,
-		enrollment.CertificateIssued,
-		enrollment.CertificateNumber,
+		enrollment.WaitlistPosition,
 		enrollment.ID,
 	).Scan(&enrollment.UpdatedAt); err != nil {
 		switch {
@@ -245,7 +598,7 @@ func (m *TrainingEnrollmentModel) Update(enrollment *TrainingEnrollment) error {
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // Delete removes a training enrollment from the database
@@ -289,7 +642,7 @@ func (m *TrainingEnrollmentModel) GetBySession(sessionID int64, filters Filters)
 // GetByOfficerAndSession retrieves a specific enrollment by officer and session
 func (m *TrainingEnrollmentModel) GetByOfficerAndSession(officerID, sessionID int64) (*TrainingEnrollment, error) {
 	query := `
-		SELECT id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, created_at, updated_at
+		SELECT id, officer_id, session_id, enrollment_status_id, attendance_status_id, progress_status_id, completion_date, certificate_issued, certificate_number, certificate_issued_by, certificate_issued_at, certificate_revoked, waitlist_position, created_at, updated_at
 		FROM training_enrollments
 		WHERE officer_id = $1 AND session_id = $2`
 
@@ -308,6 +661,10 @@ func (m *TrainingEnrollmentModel) GetByOfficerAndSession(officerID, sessionID in
 		&enrollment.CompletionDate,
 		&enrollment.CertificateIssued,
 		&enrollment.CertificateNumber,
+		&enrollment.CertificateIssuedBy,
+		&enrollment.CertificateIssuedAt,
+		&enrollment.CertificateRevoked,
+		&enrollment.WaitlistPosition,
 		&enrollment.CreatedAt,
 		&enrollment.UpdatedAt,
 	)
@@ -323,29 +680,314 @@ func (m *TrainingEnrollmentModel) GetByOfficerAndSession(officerID, sessionID in
 	return &enrollment, nil
 }
 
-// IssueCertificate marks an enrollment as having a certificate issued
-func (m *TrainingEnrollmentModel) IssueCertificate(enrollmentID int64, certificateNumber string, completionDate time.Time) error {
+// IssueCertificate issues the certificate of a completed enrollment and returns its number. Numbers run
+// sequentially per completion year and session region, e.g. BPD-2026-SOUTH-000123, and are passed
+// through sign when it is not nil. The enrollment's attendance must count as present and its progress
+// must be Completed; otherwise the returned error wraps ErrAttendanceNotPresent and/or
+// ErrProgressIncomplete. Revoked certificates must be reissued through CertificateModel.Reissue.
+func (m *TrainingEnrollmentModel) IssueCertificate(enrollmentID, issuedBy int64, completionDate time.Time, sign func(string) string) (string, error) {
+	if enrollmentID < 1 {
+		return "", ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	state, err := lockCertificateState(ctx, tx, enrollmentID)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case state.issued:
+		return "", ErrCertificateAlreadyIssued
+	case state.revoked:
+		return "", ErrCertificateRevoked
+	}
+
+	number, err := issueCertificate(ctx, tx, state, issuedBy, completionDate, sign)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return number, nil
+}
+
+// enrollmentColumns lists the training_enrollments columns scanned by scanEnrollment
+const enrollmentColumns = `te.id, te.officer_id, te.session_id, te.enrollment_status_id, te.attendance_status_id, te.progress_status_id, te.completion_date, te.certificate_issued, te.certificate_number, te.certificate_issued_by, te.certificate_issued_at, te.certificate_revoked, te.waitlist_position, te.created_at, te.updated_at`
+
+// scanEnrollment reads a row selected with enrollmentColumns.
+func scanEnrollment(rows *sql.Rows) (*TrainingEnrollment, error) {
+	var enrollment TrainingEnrollment
+	err := rows.Scan(
+		&enrollment.ID,
+		&enrollment.OfficerID,
+		&enrollment.SessionID,
+		&enrollment.EnrollmentStatusID,
+		&enrollment.AttendanceStatusID,
+		&enrollment.ProgressStatusID,
+		&enrollment.CompletionDate,
+		&enrollment.CertificateIssued,
+		&enrollment.CertificateNumber,
+		&enrollment.CertificateIssuedBy,
+		&enrollment.CertificateIssuedAt,
+		&enrollment.CertificateRevoked,
+		&enrollment.WaitlistPosition,
+		&enrollment.CreatedAt,
+		&enrollment.UpdatedAt,
+	)
+	return &enrollment, err
+}
+
+// waitlistQuery selects a session's waitlisted enrollments in waitlist order
+const waitlistQuery = `
+	SELECT ` + enrollmentColumns + `
+	FROM training_enrollments te
+	INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+	WHERE te.session_id = $1 AND es.status = '` + EnrollmentStatusWaitlisted + `'
+	ORDER BY te.waitlist_position ASC NULLS LAST, te.id ASC`
+
+// GetWaitlist returns the waitlisted enrollments of a session in the order they will be promoted.
+func (m *TrainingEnrollmentModel) GetWaitlist(sessionID int64) ([]*TrainingEnrollment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, waitlistQuery, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var waitlist []*TrainingEnrollment
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		waitlist = append(waitlist, enrollment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return waitlist, nil
+}
+
+// renumberWaitlist closes any gaps in a session's waitlist positions. The session must already be
+// locked by the transaction.
+func renumberWaitlist(ctx context.Context, tx *sql.Tx, sessionID int64) error {
 	query := `
-		UPDATE training_enrollments
-		SET certificate_issued = true, certificate_number = $1, completion_date = $2, updated_at = NOW()
-		WHERE id = $3`
+		UPDATE training_enrollments te
+		SET waitlist_position = ordered.position
+		FROM (
+			SELECT te.id, ROW_NUMBER() OVER (ORDER BY te.waitlist_position ASC NULLS LAST, te.id ASC) AS position
+			FROM training_enrollments te
+			INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+			WHERE te.session_id = $1 AND es.status = '` + EnrollmentStatusWaitlisted + `'
+		) ordered
+		WHERE te.id = ordered.id AND te.waitlist_position IS DISTINCT FROM ordered.position`
+
+	_, err := tx.ExecContext(ctx, query, sessionID)
+	return err
+}
 
+// PromoteFromWaitlist fills any free seats in a session from the front of its waitlist and returns the
+// enrollments that were promoted. Officers who hold a seat in an overlapping session are passed over and
+// stay on the waitlist. It is safe to call after any change that may have freed a seat.
+func (m *TrainingEnrollmentModel) PromoteFromWaitlist(sessionID int64) ([]*TrainingEnrollment, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, certificateNumber, completionDate, enrollmentID)
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	maxCapacity, err := lockSession(ctx, tx, sessionID)
 	if err != nil {
-		return err
+		switch {
+		case errors.Is(err, ErrForeignKeyViolation):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
 	}
 
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
+	// A NULL limit promotes everyone when the session has no capacity set
+	var free sql.NullInt64
+	if maxCapacity.Valid {
+		taken, err := seatsTaken(ctx, tx, sessionID, 0)
+		if err != nil {
+			return nil, err
+		}
+		free = sql.NullInt64{Int64: max(maxCapacity.Int64-taken, 0), Valid: true}
 	}
 
-	return nil
+	var promoted []*TrainingEnrollment
+
+	if !free.Valid || free.Int64 > 0 {
+		enrolledID, err := enrollmentStatusID(ctx, tx, EnrollmentStatusEnrolled)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil // nothing to promote to
+			}
+			return nil, err
+		}
+
+		candidates, err := waitlistCandidates(ctx, tx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Candidates are taken one at a time so each is checked against the seats they hold elsewhere
+		for _, enrollment := range candidates {
+			if free.Valid && int64(len(promoted)) == free.Int64 {
+				break
+			}
+
+			var clash *ScheduleClashError
+			if err := checkOfficerClash(ctx, tx, enrollment); err != nil {
+				if errors.As(err, &clash) {
+					continue
+				}
+				return nil, err
+			}
+
+			err := tx.QueryRowContext(ctx, `
+				UPDATE training_enrollments
+				SET enrollment_status_id = $1, waitlist_position = NULL, updated_at = NOW()
+				WHERE id = $2
+				RETURNING updated_at`,
+				enrolledID, enrollment.ID,
+			).Scan(&enrollment.UpdatedAt)
+			if err != nil {
+				return nil, err
+			}
+
+			enrollment.EnrollmentStatusID = enrolledID
+			enrollment.WaitlistPosition = nil
+			promoted = append(promoted, enrollment)
+		}
+	}
+
+	// Positions are compacted even when nobody was promoted, as withdrawals leave gaps behind
+	if err := renumberWaitlist(ctx, tx, sessionID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return promoted, nil
+}
+
+// waitlistCandidates returns the waitlisted enrollments of a session that have not withdrawn, in the
+// order they are offered seats. The session must already be locked by the transaction.
+func waitlistCandidates(ctx context.Context, tx *sql.Tx, sessionID int64) ([]*TrainingEnrollment, error) {
+	query := `
+		SELECT ` + enrollmentColumns + `
+		FROM training_enrollments te
+		INNER JOIN enrollment_statuses es ON es.id = te.enrollment_status_id
+		INNER JOIN progress_statuses ps ON ps.id = te.progress_status_id
+		WHERE te.session_id = $1
+		AND es.status = '` + EnrollmentStatusWaitlisted + `'
+		AND ps.status <> '` + ProgressStatusWithdrawn + `'
+		ORDER BY te.waitlist_position ASC NULLS LAST, te.id ASC`
+
+	rows, err := tx.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*TrainingEnrollment
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, enrollment)
+	}
+
+	return candidates, rows.Err()
+}
+
+// ReorderWaitlist sets the waitlist order of a session. enrollmentIDs must contain every waitlisted
+// enrollment of the session exactly once, otherwise ErrWaitlistMismatch is returned.
+func (m *TrainingEnrollmentModel) ReorderWaitlist(sessionID int64, enrollmentIDs []int64) ([]*TrainingEnrollment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockSession(ctx, tx, sessionID); err != nil {
+		switch {
+		case errors.Is(err, ErrForeignKeyViolation):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, waitlistQuery, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[int64]bool)
+	for rows.Next() {
+		enrollment, err := scanEnrollment(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		current[enrollment.ID] = true
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(enrollmentIDs) != len(current) {
+		return nil, ErrWaitlistMismatch
+	}
+
+	for position, id := range enrollmentIDs {
+		if !current[id] {
+			return nil, ErrWaitlistMismatch
+		}
+		delete(current, id)
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE training_enrollments
+			SET waitlist_position = $1, updated_at = NOW()
+			WHERE id = $2`, position+1, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m.GetWaitlist(sessionID)
 }
@@ -59,7 +59,8 @@ func ValidateTrainingSession(v *validator.Validator, session *TrainingSession) {
 	}
 }
 
-// Insert creates a new training session.
+// Insert creates a new training session. It returns a *ScheduleConflictError if the session overlaps
+// another session with the same facilitator or location.
 func (m *TrainingSessionModel) Insert(session *TrainingSession) error {
 	query := `
 		INSERT INTO training_sessions (facilitator_id, workshop_id, formation_id, region_id, session_date, start_time, end_time, location, max_capacity, training_status_id, notes)
@@ -69,7 +70,17 @@ func (m *TrainingSessionModel) Insert(session *TrainingSession) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	if err := m.DB.QueryRowContext(ctx, query,
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkScheduleConflicts(ctx, tx, session); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRowContext(ctx, query,
 		session.FacilitatorID,
 		session.WorkshopID,
 		session.FormationID,
@@ -92,11 +103,17 @@ func (m *TrainingSessionModel) Insert(session *TrainingSession) error {
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // Get retrieves a training session by id.
 func (m *TrainingSessionModel) Get(id int64) (*TrainingSession, error) {
+	return m.GetInScope(id, nil)
+}
+
+// GetInScope retrieves a training session by id, returning ErrRecordNotFound when the session is outside
+// scope
+func (m *TrainingSessionModel) GetInScope(id int64, scope *Scope) (*TrainingSession, error) {
 	if id < 1 {
 		return nil, ErrRecordNotFound
 	}
@@ -104,14 +121,17 @@ func (m *TrainingSessionModel) Get(id int64) (*TrainingSession, error) {
 	query := `
 		SELECT id, facilitator_id, workshop_id, formation_id, region_id, session_date, start_time, end_time, location, max_capacity, training_status_id, notes, created_at, updated_at
 		FROM training_sessions
-		WHERE id = $1`
+		WHERE id = $1
+		AND ` + scopeCondition(2, unitColumns)
 
 	var session TrainingSession
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+	args := append([]any{id}, scope.args()...)
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(
 		&session.ID,
 		&session.FacilitatorID,
 		&session.WorkshopID,
@@ -141,6 +161,38 @@ func (m *TrainingSessionModel) Get(id int64) (*TrainingSession, error) {
 
 // GetAll returns training sessions filtered by various criteria.
 func (m *TrainingSessionModel) GetAll(facilitatorID, workshopID, formationID, regionID, statusID *int64, sessionDate *time.Time, filters Filters) ([]*TrainingSession, MetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var sessions []*TrainingSession
+	totalRecords, err := m.scanAll(ctx, facilitatorID, workshopID, formationID, regionID, statusID, sessionDate, filters, func(session *TrainingSession) error {
+		sessions = append(sessions, session)
+		return nil
+	})
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return sessions, metadata, nil
+}
+
+// Each calls fn with every training session matching the criteria of GetAll, ignoring the page, as each
+// row is read, so exports never hold the whole list. It stops at the first error fn returns.
+func (m *TrainingSessionModel) Each(facilitatorID, workshopID, formationID, regionID, statusID *int64, sessionDate *time.Time, filters Filters, fn func(*TrainingSession) error) error {
+	filters.Unpaged = true
+
+	// Rows are read only as fast as the client takes them, so allow longer than a single page
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := m.scanAll(ctx, facilitatorID, workshopID, formationID, regionID, statusID, sessionDate, filters, fn)
+	return err
+}
+
+// scanAll runs the query behind GetAll and Each, passing each session to fn, and returns the total
+// number of matching records
+func (m *TrainingSessionModel) scanAll(ctx context.Context, facilitatorID, workshopID, formationID, regionID, statusID *int64, sessionDate *time.Time, filters Filters, fn func(*TrainingSession) error) (int, error) {
 	if filters.Sort == "" {
 		filters.Sort = "id" // Add this line
 	}
@@ -153,8 +205,9 @@ func (m *TrainingSessionModel) GetAll(facilitatorID, workshopID, formationID, re
 		AND ($4 = 0 OR region_id = $4)
 		AND ($5 = 0 OR training_status_id = $5)
 		AND ($6::date IS NULL OR session_date = $6::date)
+		AND %s
 		ORDER BY %s %s, id ASC
-		LIMIT $7 OFFSET $8`, filters.sortColumn(), filters.sortDirection())
+		LIMIT $7 OFFSET $8`, scopeCondition(9, unitColumns), filters.sortColumn(), filters.sortDirection())
 
 	facilitatorArg := int64(0)
 	if facilitatorID != nil {
@@ -186,20 +239,15 @@ func (m *TrainingSessionModel) GetAll(facilitatorID, workshopID, formationID, re
 		dateArg = *sessionDate
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	args := append([]any{facilitatorArg, workshopArg, formationArg, regionArg, statusArg, dateArg, filters.limit(), filters.offset()}, filters.Scope.args()...)
 
-	rows, err := m.DB.QueryContext(ctx, query, facilitatorArg, workshopArg, formationArg, regionArg, statusArg, dateArg, filters.limit(), filters.offset())
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 	defer rows.Close()
 
-	var (
-		sessions     []*TrainingSession
-		totalRecords int
-	)
-
+	totalRecords := 0
 	for rows.Next() {
 		var session TrainingSession
 		if err := rows.Scan(
@@ -219,20 +267,22 @@ func (m *TrainingSessionModel) GetAll(facilitatorID, workshopID, formationID, re
 			&session.CreatedAt,
 			&session.UpdatedAt,
 		); err != nil {
-			return nil, MetaData{}, err
+			return 0, err
+		}
+		if err := fn(&session); err != nil {
+			return 0, err
 		}
-		sessions = append(sessions, &session)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
-	return sessions, metadata, nil
+	return totalRecords, nil
 }
 
-// Update modifies an existing training session.
+// Update modifies an existing training session. It returns a *ScheduleConflictError if the new schedule
+// overlaps another session with the same facilitator or location.
 func (m *TrainingSessionModel) Update(session *TrainingSession) error {
 	query := `
 		UPDATE training_sessions
@@ -243,7 +293,17 @@ func (m *TrainingSessionModel) Update(session *TrainingSession) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	if err := m.DB.QueryRowContext(ctx, query,
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkScheduleConflicts(ctx, tx, session); err != nil {
+		return err
+	}
+
+	if err := tx.QueryRowContext(ctx, query,
 		session.FacilitatorID,
 		session.WorkshopID,
 		session.FormationID,
@@ -269,7 +329,7 @@ func (m *TrainingSessionModel) Update(session *TrainingSession) error {
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // Delete removes a training session from the database
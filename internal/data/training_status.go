@@ -21,6 +21,10 @@ type TrainingStatus struct {
 	Status string `json:"status"`
 }
 
+// TrainingStatusCancelled names the status of a session that will not run. The seeded data uses both
+// "cancelled" and "Cancelled", so compare against it in lower case.
+const TrainingStatusCancelled = "cancelled"
+
 // TrainingStatusModel struct to interact with the training_status table in the database
 type TrainingStatusModel struct {
 	DB *sql.DB
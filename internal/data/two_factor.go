@@ -0,0 +1,350 @@
+// FileName: internal/data/two_factor.go
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Pedro-J-Kukul/police_training/internal/totp"
+	"github.com/Pedro-J-Kukul/police_training/internal/validator"
+	"github.com/lib/pq"
+)
+
+/************************************************************************************************************/
+// Two-Factor Declarations
+/************************************************************************************************************/
+
+// recoveryCodeCount is the number of recovery codes issued at a time
+const recoveryCodeCount = 10
+
+// TwoFactor is a user's TOTP enrollment. It is enabled once ConfirmedAt is set.
+type TwoFactor struct {
+	UserID       int64      `json:"-"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-"`
+}
+
+// TwoFactorStatus summarises a user's two-factor enrollment without revealing the secret
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorModel wraps the database connection pool for TOTP enrollments and recovery codes
+type TwoFactorModel struct {
+	DB *sql.DB
+}
+
+// ValidateTwoFactorCode checks that a code looks like a TOTP code or a recovery code
+func ValidateTwoFactorCode(v *validator.Validator, code string) {
+	v.Check(code != "", "code", "must be provided")
+	v.Check(len(code) <= 16, "code", "must not be more than 16 characters long")
+}
+
+// generateRecoveryCode returns a random recovery code formatted as two groups of five characters
+func generateRecoveryCode() (string, error) {
+	randomBytes := make([]byte, 10)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(randomBytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes so codes can be typed loosely
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hash[:]
+}
+
+/************************************************************************************************************/
+// Two-Factor Methods
+/************************************************************************************************************/
+
+// Get retrieves a user's TOTP enrollment, confirmed or not
+func (m *TwoFactorModel) Get(userID int64) (*TwoFactor, error) {
+	query := `
+		SELECT user_id, secret, confirmed_at, last_used_step
+		FROM user_totp
+		WHERE user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var twoFactor TwoFactor
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.Secret,
+		&twoFactor.ConfirmedAt,
+		&twoFactor.LastUsedStep,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &twoFactor, nil
+}
+
+// IsEnabled reports whether a user has confirmed a TOTP enrollment
+func (m *TwoFactorModel) IsEnabled(userID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_at IS NOT NULL)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var enabled bool
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// GetStatus summarises a user's enrollment and how many unused recovery codes they have left
+func (m *TwoFactorModel) GetStatus(userID int64) (*TwoFactorStatus, error) {
+	query := `
+		SELECT t.confirmed_at,
+			(SELECT COUNT(*) FROM user_recovery_codes c WHERE c.user_id = $1 AND c.used_at IS NULL)
+		FROM (SELECT 1) AS one
+		LEFT JOIN user_totp t ON t.user_id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var status TwoFactorStatus
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&status.ConfirmedAt, &status.RecoveryCodesRemaining)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Enabled = status.ConfirmedAt != nil
+	return &status, nil
+}
+
+// Setup starts a TOTP enrollment with a new secret, replacing any unconfirmed one. Returns
+// ErrTwoFactorEnabled when the user already has a confirmed enrollment.
+func (m *TwoFactorModel) Setup(userID int64) (*TwoFactor, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = NOW()
+		WHERE user_totp.confirmed_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	return &TwoFactor{UserID: userID, Secret: secret}, nil
+}
+
+// Verify checks a TOTP code against a user's enrollment, confirmed or not. A code is accepted at most
+// once: the step it was generated for is recorded and earlier steps are refused from then on.
+func (m *TwoFactorModel) Verify(userID int64, code string) (bool, error) {
+	twoFactor, err := m.Get(userID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	step, ok := totp.Match(twoFactor.Secret, code, time.Now())
+	if !ok || step <= twoFactor.LastUsedStep {
+		return false, nil
+	}
+
+	// Recording the step in the same statement that checks it stops two requests using one code
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// UseRecoveryCode spends one of a user's unused recovery codes, reporting whether it was valid
+func (m *TwoFactorModel) UseRecoveryCode(userID int64, code string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND hash = $2 AND used_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, hashRecoveryCode(code))
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Confirm enables a user's pending enrollment and issues their recovery codes, which are only ever
+// returned here. Returns ErrRecordNotFound when there is no pending enrollment.
+func (m *TwoFactorModel) Confirm(userID int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE user_totp SET confirmed_at = NOW() WHERE user_id = $1 AND confirmed_at IS NULL`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, tx.Commit()
+}
+
+// RegenerateRecoveryCodes replaces all of a user's recovery codes, used or not, with new ones
+func (m *TwoFactorModel) RegenerateRecoveryCodes(userID int64) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, tx.Commit()
+}
+
+// replaceRecoveryCodes deletes a user's recovery codes and stores the hashes of a new set
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64) ([]string, error) {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO user_recovery_codes (user_id, hash) VALUES ($1, $2)`, userID, hashRecoveryCode(code))
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// NeedsEnrollment reports whether a user holds any of the given permissions without having two-factor
+// authentication enabled
+func (m *TwoFactorModel) NeedsEnrollment(userID int64, permissionCodes []string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM roles_users ru
+			INNER JOIN roles_permissions rp ON rp.role_id = ru.role_id
+			INNER JOIN permissions p ON p.id = rp.permission_id
+			WHERE ru.user_id = $1 AND p.code = ANY($2)
+		)
+		AND NOT EXISTS (SELECT 1 FROM user_totp WHERE user_id = $1 AND confirmed_at IS NOT NULL)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var needs bool
+	err := m.DB.QueryRowContext(ctx, query, userID, pq.Array(permissionCodes)).Scan(&needs)
+	return needs, err
+}
+
+// Disable removes a user's TOTP enrollment and recovery codes
+func (m *TwoFactorModel) Disable(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
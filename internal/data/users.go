@@ -48,13 +48,21 @@ type UserModel struct {
 // AnonymousUser is a sentinel anonymous user instance.
 var AnonymousUser = &User{}
 
+// LockoutPolicy decides when failed logins in a row lock an account and for how long. Each failure past
+// the threshold doubles the lock, up to MaxDuration. A Threshold of zero disables lockout.
+type LockoutPolicy struct {
+	Threshold    int           // failed logins in a row that lock the account
+	BaseDuration time.Duration // length of the first lock
+	MaxDuration  time.Duration // longest an account stays locked
+}
+
 /************************************************************************************************************/
 // Password helpers
 /************************************************************************************************************/
 
-// Set hashes the supplied plaintext password.
-func (p *Password) Set(plaintext string) error {
-	hash, err := bcrypt.GenerateFromPassword([]byte(plaintext), 12)
+// Set hashes the supplied plaintext password with Argon2id.
+func (p *Password) Set(plaintext string, params PasswordParams) error {
+	hash, err := hashArgon2id(plaintext, params)
 	if err != nil {
 		return err
 	}
@@ -64,11 +72,29 @@ func (p *Password) Set(plaintext string) error {
 	return nil
 }
 
-// Matches verifies that the supplied plaintext password matches the stored hash.
+// SetUnusable stores a hash that no password matches, for accounts whose owner chooses a password
+// later, such as invited or imported users
+func (p *Password) SetUnusable(params PasswordParams) error {
+	hash, err := unusableArgon2id(params)
+	if err != nil {
+		return err
+	}
+
+	p.hash = hash
+	p.plaintext = nil
+	return nil
+}
+
+// Matches verifies that the supplied plaintext password matches the stored hash, whichever format it
+// was stored in.
 func (p *Password) Matches(plaintext string) (bool, error) {
+	if !isBcryptHash(p.hash) {
+		return matchArgon2id(p.hash, plaintext)
+	}
+
 	err := bcrypt.CompareHashAndPassword(p.hash, []byte(plaintext))
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) || errors.Is(err, bcrypt.ErrPasswordTooLong) {
 			return false, nil
 		}
 		return false, err
@@ -76,6 +102,17 @@ func (p *Password) Matches(plaintext string) (bool, error) {
 	return true, nil
 }
 
+// NeedsRehash reports whether the stored hash is bcrypt or Argon2id with parameters other than params,
+// so it should be replaced the next time the plaintext is known.
+func (p *Password) NeedsRehash(params PasswordParams) bool {
+	if isBcryptHash(p.hash) {
+		return true
+	}
+
+	stored, _, _, err := decodeArgon2id(p.hash)
+	return err != nil || stored != params
+}
+
 /************************************************************************************************************/
 // User Validation
 /************************************************************************************************************/
@@ -203,10 +240,37 @@ func (m *UserModel) GetByEmail(email string) (*User, error) {
 	return &user, nil // Return the user data
 }
 
-// Update modifies an existing user in the database
+// Update modifies an existing user in the database. Deactivating a user ends all of their logins.
 func (m *UserModel) Update(user *User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Context with a timeout for the database operation
+	defer cancel()                                                          // Ensure the context is cancelled to free resources
+
+	return updateUser(ctx, m.DB, user)
+}
+
+// updateUser runs the update behind Update on a connection or transaction
+func updateUser(ctx context.Context, db interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}, user *User) error {
 	// SQL query to update a user
+	// A changed password hash pushes the old one onto the user's password history, trimmed to
+	// PasswordHistoryLimit entries, so it cannot be reused
 	query := `
+		WITH revoked AS (
+			DELETE FROM tokens
+			WHERE NOT $6::boolean AND scope IN ($12, $13)
+			AND user_id IN (SELECT id FROM users WHERE id = $9 AND version = $10)
+		), previous AS (
+			INSERT INTO password_history (user_id, hash)
+			SELECT id, password_hash FROM users
+			WHERE id = $9 AND version = $10 AND password_hash <> $5
+			RETURNING user_id
+		), pruned AS (
+			DELETE FROM password_history
+			WHERE user_id IN (SELECT user_id FROM previous) AND id NOT IN (
+				SELECT id FROM password_history WHERE user_id = $9 ORDER BY id DESC LIMIT $11
+			)
+		)
 		UPDATE users
 		SET first_name = $1, last_name = $2, email = $3, gender = $4, 
 		    password_hash = $5, is_activated = $6, is_facilitator = $7, 
@@ -216,23 +280,23 @@ func (m *UserModel) Update(user *User) error {
 
 	// Arguments for the SQL query
 	args := []any{
-		user.FirstName,     // $1
-		user.LastName,      // $2
-		user.Email,         // $3
-		user.Gender,        // $4
-		user.Password.hash, // $5
-		user.IsActivated,   // $6
-		user.IsFacilitator, // $7
-		user.IsOfficer,     // $8
-		user.ID,            // $9
-		user.Version,       // $10
+		user.FirstName,           // $1
+		user.LastName,            // $2
+		user.Email,               // $3
+		user.Gender,              // $4
+		user.Password.hash,       // $5
+		user.IsActivated,         // $6
+		user.IsFacilitator,       // $7
+		user.IsOfficer,           // $8
+		user.ID,                  // $9
+		user.Version,             // $10
+		PasswordHistoryLimit - 1, // $11 - older entries kept beside the one being added
+		ScopeAuthentication,      // $12 - tokens revoked on deactivation
+		ScopeRefresh,             // $13
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Context with a timeout for the database operation
-	defer cancel()                                                          // Ensure the context is cancelled to free resources
-
 	// Execute the query and scan the returned values into the user struct
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.UpdatedAt, &user.Version)
+	err := db.QueryRowContext(ctx, query, args...).Scan(&user.UpdatedAt, &user.Version)
 	if err != nil {
 		switch {
 		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
@@ -247,14 +311,25 @@ func (m *UserModel) Update(user *User) error {
 	return nil // Everything went well
 }
 
-// UpdatePassword updates a user's password securely.
-func (m *UserModel) UpdatePassword(id int64, newPlaintext string) error {
+// UpdatePassword updates a user's password securely, keeping the old one in the password history.
+func (m *UserModel) UpdatePassword(id int64, newPlaintext string, params PasswordParams) error {
 	var password Password
-	if err := password.Set(newPlaintext); err != nil {
+	if err := password.Set(newPlaintext, params); err != nil {
 		return err
 	}
 
 	query := `
+		WITH previous AS (
+			INSERT INTO password_history (user_id, hash)
+			SELECT id, password_hash FROM users
+			WHERE id = $2
+			RETURNING user_id
+		), pruned AS (
+			DELETE FROM password_history
+			WHERE user_id IN (SELECT user_id FROM previous) AND id NOT IN (
+				SELECT id FROM password_history WHERE user_id = $2 ORDER BY id DESC LIMIT $3
+			)
+		)
 		UPDATE users
 		SET password_hash = $1, updated_at = now(), version = version + 1
 		WHERE id = $2
@@ -265,7 +340,7 @@ func (m *UserModel) UpdatePassword(id int64, newPlaintext string) error {
 
 	var updatedAt time.Time
 	var version int
-	err := m.DB.QueryRowContext(ctx, query, password.hash, id).Scan(&updatedAt, &version)
+	err := m.DB.QueryRowContext(ctx, query, password.hash, id, PasswordHistoryLimit-1).Scan(&updatedAt, &version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ErrRecordNotFound
@@ -275,7 +350,161 @@ func (m *UserModel) UpdatePassword(id int64, newPlaintext string) error {
 	return nil
 }
 
-// SoftDelete marks a user as deleted without removing their record.
+// RehashPassword replaces a user's password hash with one made with the current parameters, after the
+// plaintext has been checked at login. The version is left alone as nothing the user can see changed,
+// and a password changed in the meantime is not overwritten.
+func (m *UserModel) RehashPassword(user *User, plaintext string, params PasswordParams) error {
+	previous := user.Password.hash
+	if err := user.Password.Set(plaintext, params); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE users
+		SET password_hash = $1
+		WHERE id = $2 AND password_hash = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, user.Password.hash, user.ID, previous)
+	return err
+}
+
+// PasswordReused reports whether a plaintext password matches a user's current password or any of the
+// ones before it, checking count passwords in all
+func (m *UserModel) PasswordReused(id int64, plaintext string, count int) (bool, error) {
+	if count <= 0 {
+		return false, nil
+	}
+
+	query := `
+		SELECT password_hash FROM users WHERE id = $1
+		UNION ALL
+		(SELECT hash FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, id, min(count, PasswordHistoryLimit+1)-1)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return false, err
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	// The hashes are compared after the rows are closed, as each comparison is deliberately slow
+	for _, hash := range hashes {
+		previous := Password{hash: hash}
+		match, err := previous.Matches(plaintext)
+		if err != nil {
+			return false, err
+		}
+		if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetLockedUntil returns when a user's account unlocks, or nil when it is not locked.
+func (m *UserModel) GetLockedUntil(id int64) (*time.Time, error) {
+	query := `
+		SELECT locked_until
+		FROM users
+		WHERE id = $1 AND locked_until > $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var lockedUntil time.Time
+	err := m.DB.QueryRowContext(ctx, query, id, time.Now()).Scan(&lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &lockedUntil, nil
+}
+
+// RecordFailedLogin counts a failed login against a user and locks the account once the policy's
+// threshold is reached, ending all of the user's logins. It returns when the account unlocks if this
+// failure locked it, or nil.
+func (m *UserModel) RecordFailedLogin(id int64, policy LockoutPolicy) (*time.Time, error) {
+	if policy.Threshold <= 0 {
+		return nil, nil
+	}
+
+	// The lock is worked out in the update itself so concurrent failures cannot undercount
+	query := `
+		UPDATE users
+		SET failed_login_attempts = failed_login_attempts + 1,
+			locked_until = CASE
+				WHEN failed_login_attempts + 1 >= $2
+				THEN $5::timestamptz + make_interval(secs => LEAST($3::float8 * power(2, LEAST(failed_login_attempts + 1 - $2, 30)), $4::float8))
+				ELSE locked_until
+			END
+		WHERE id = $1
+		RETURNING CASE WHEN failed_login_attempts >= $2 THEN locked_until END`
+
+	args := []any{id, policy.Threshold, policy.BaseDuration.Seconds(), policy.MaxDuration.Seconds(), time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var lockedUntil *time.Time
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if lockedUntil != nil {
+		if err := revokeAllLogins(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return lockedUntil, tx.Commit()
+}
+
+// ClearFailedLogins forgets a user's failed logins and unlocks their account.
+func (m *UserModel) ClearFailedLogins(id int64) error {
+	query := `
+		UPDATE users
+		SET failed_login_attempts = 0, locked_until = NULL
+		WHERE id = $1 AND (failed_login_attempts > 0 OR locked_until IS NOT NULL)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// SoftDelete marks a user as deleted without removing their record, ending all of their logins. Deleting
+// the last national admin returns ErrLastAdmin.
 func (m *UserModel) SoftDelete(id int64) error {
 	query := `
 		UPDATE users
@@ -286,8 +515,18 @@ func (m *UserModel) SoftDelete(id int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := guardLastAdmin(ctx, tx, id); err != nil {
+		return err
+	}
+
 	var returnedID int64
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(&returnedID)
+	err = tx.QueryRowContext(ctx, query, id).Scan(&returnedID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ErrRecordNotFound
@@ -295,7 +534,11 @@ func (m *UserModel) SoftDelete(id int64) error {
 		return err
 	}
 
-	return nil
+	if err := revokeAllLogins(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // Restore revokes the soft deletion of a user.
@@ -321,7 +564,8 @@ func (m *UserModel) Restore(id int64) error {
 	return nil
 }
 
-// HardDelete permanently removes a user from the database.
+// HardDelete permanently removes a user from the database. Deleting the last national admin returns
+// ErrLastAdmin.
 func (m *UserModel) HardDelete(id int64) error {
 	query := `
 		DELETE FROM users
@@ -330,7 +574,17 @@ func (m *UserModel) HardDelete(id int64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := guardLastAdmin(ctx, tx, id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -341,7 +595,7 @@ func (m *UserModel) HardDelete(id int64) error {
 	if rows == 0 {
 		return ErrRecordNotFound
 	}
-	return nil
+	return tx.Commit()
 }
 
 // Get retrieves a user by their ID
@@ -386,6 +640,38 @@ func (m *UserModel) Get(id int64) (*User, error) {
 
 // GetAll retrieves all users from the database
 func (m *UserModel) GetAll(fname, lname, email, gender string, activated *bool, facilitator *bool, officer *bool, deleted *bool, filters Filters) ([]*User, MetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Context with a timeout for the database operation
+	defer cancel()                                                          // Ensure the context is cancelled to free resources
+
+	users := []*User{} // Slice to hold the retrieved users
+	totalRecords, err := m.scanAll(ctx, fname, lname, email, gender, activated, facilitator, officer, deleted, filters, func(user *User) error {
+		users = append(users, user)
+		return nil
+	})
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize) // Calculate pagination metadata
+	return users, metadata, nil
+}
+
+// Each calls fn with every user matching the criteria of GetAll, ignoring the page, as each row is read,
+// so exports never hold the whole list. It stops at the first error fn returns.
+func (m *UserModel) Each(fname, lname, email, gender string, activated *bool, facilitator *bool, officer *bool, deleted *bool, filters Filters, fn func(*User) error) error {
+	filters.Unpaged = true
+
+	// Rows are read only as fast as the client takes them, so allow longer than a single page
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := m.scanAll(ctx, fname, lname, email, gender, activated, facilitator, officer, deleted, filters, fn)
+	return err
+}
+
+// scanAll runs the query behind GetAll and Each, passing each user to fn, and returns the total number
+// of matching records
+func (m *UserModel) scanAll(ctx context.Context, fname, lname, email, gender string, activated *bool, facilitator *bool, officer *bool, deleted *bool, filters Filters, fn func(*User) error) (int, error) {
 	// SQL query to select users with filtering, sorting, and pagination
 	query := fmt.Sprintf(`
 		SELECT COUNT(*) OVER(), id, first_name, last_name, email, gender, is_activated, is_facilitator, is_officer, is_deleted, created_at, updated_at, version
@@ -401,19 +687,15 @@ func (m *UserModel) GetAll(fname, lname, email, gender string, activated *bool,
 		ORDER BY %s %s, id ASC
 		LIMIT $9 OFFSET $10`, filters.sortColumn(), filters.sortDirection())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second) // Context with a timeout for the database operation
-	defer cancel()                                                          // Ensure the context is cancelled to free resources
-
 	// execute the query
 	rows, err := m.DB.QueryContext(ctx, query, fname, lname, email, gender, activated, facilitator, officer, deleted, filters.limit(), filters.offset())
 	if err != nil {
-		return nil, MetaData{}, err // Return any error encountered while executing the query
+		return 0, err // Return any error encountered while executing the query
 	}
 	defer rows.Close() // Ensure the rows are closed after reading
 
-	totalRecords := 0  // Variable to hold the total number of records
-	users := []*User{} // Slice to hold the retrieved users
-	for rows.Next() {  // Iterate over the rows
+	totalRecords := 0 // Variable to hold the total number of records
+	for rows.Next() { // Iterate over the rows
 		var user User     // Variable to hold the user data
 		err := rows.Scan( // Scan the row into the user struct
 			&totalRecords,
@@ -431,16 +713,17 @@ func (m *UserModel) GetAll(fname, lname, email, gender string, activated *bool,
 			&user.Version,
 		)
 		if err != nil {
-			return nil, MetaData{}, err // Return any error encountered while scanning the row
+			return 0, err // Return any error encountered while scanning the row
+		}
+		if err := fn(&user); err != nil {
+			return 0, err
 		}
-		users = append(users, &user) // Add the user to the slice
 	}
 	if err := rows.Err(); err != nil {
-		return nil, MetaData{}, err // Return any error encountered while iterating over the rows
+		return 0, err // Return any error encountered while iterating over the rows
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize) // Calculate pagination metadata
-	return users, metadata, nil
+	return totalRecords, nil
 }
 
 /*************************************************************************************************************/
@@ -0,0 +1,40 @@
+// Filename: internal/data/users_test.go
+package data
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testPasswordParams keep hashing fast in unit tests
+var testPasswordParams = PasswordParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func TestPasswordSetUnusable(t *testing.T) {
+	var first, second Password
+	if err := first.SetUnusable(testPasswordParams); err != nil {
+		t.Fatalf("Failed to set unusable password: %v", err)
+	}
+	if err := second.SetUnusable(testPasswordParams); err != nil {
+		t.Fatalf("Failed to set unusable password: %v", err)
+	}
+
+	if first.plaintext != nil {
+		t.Error("Expected no plaintext to be kept for an unusable password")
+	}
+	if bytes.Equal(first.hash, second.hash) {
+		t.Error("Expected every unusable password to get its own hash")
+	}
+	if first.NeedsRehash(testPasswordParams) {
+		t.Error("Expected an unusable hash to carry the configured parameters")
+	}
+
+	for _, candidate := range []string{"", "password", "Pa55word!", string(first.hash)} {
+		matches, err := first.Matches(candidate)
+		if err != nil {
+			t.Fatalf("Expected an unusable hash to be well formed: %v", err)
+		}
+		if matches {
+			t.Errorf("Expected %q not to match an unusable password", candidate)
+		}
+	}
+}
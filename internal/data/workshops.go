@@ -149,6 +149,38 @@ func (m *WorkshopModel) GetByName(workshopName string) (*Workshop, error) {
 
 // GetAll returns workshops filtered by name, category, type, or active state.
 func (m *WorkshopModel) GetAll(name string, categoryID, typeID *int64, isActive *bool, filters Filters) ([]*Workshop, MetaData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var workshops []*Workshop
+	totalRecords, err := m.scanAll(ctx, name, categoryID, typeID, isActive, filters, func(workshop *Workshop) error {
+		workshops = append(workshops, workshop)
+		return nil
+	})
+	if err != nil {
+		return nil, MetaData{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	return workshops, metadata, nil
+}
+
+// Each calls fn with every workshop matching the criteria of GetAll, ignoring the page, as each row is
+// read, so exports never hold the whole list. It stops at the first error fn returns.
+func (m *WorkshopModel) Each(name string, categoryID, typeID *int64, isActive *bool, filters Filters, fn func(*Workshop) error) error {
+	filters.Unpaged = true
+
+	// Rows are read only as fast as the client takes them, so allow longer than a single page
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	_, err := m.scanAll(ctx, name, categoryID, typeID, isActive, filters, fn)
+	return err
+}
+
+// scanAll runs the query behind GetAll and Each, passing each workshop to fn, and returns the total
+// number of matching records
+func (m *WorkshopModel) scanAll(ctx context.Context, name string, categoryID, typeID *int64, isActive *bool, filters Filters, fn func(*Workshop) error) (int, error) {
 	if filters.Sort == "" {
 		filters.Sort = "workshop_name"
 	}
@@ -178,20 +210,13 @@ func (m *WorkshopModel) GetAll(name string, categoryID, typeID *int64, isActive
 		activeArg = *isActive
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
 	rows, err := m.DB.QueryContext(ctx, query, name, categoryArg, typeArg, activeArg, filters.limit(), filters.offset())
 	if err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 	defer rows.Close()
 
-	var (
-		workshops    []*Workshop
-		totalRecords int
-	)
-
+	totalRecords := 0
 	for rows.Next() {
 		var workshop Workshop
 		if err := rows.Scan(
@@ -206,17 +231,18 @@ func (m *WorkshopModel) GetAll(name string, categoryID, typeID *int64, isActive
 			&workshop.CreatedAt,
 			&workshop.UpdatedAt,
 		); err != nil {
-			return nil, MetaData{}, err
+			return 0, err
+		}
+		if err := fn(&workshop); err != nil {
+			return 0, err
 		}
-		workshops = append(workshops, &workshop)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, MetaData{}, err
+		return 0, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
-	return workshops, metadata, nil
+	return totalRecords, nil
 }
 
 // Update modifies an existing workshop.
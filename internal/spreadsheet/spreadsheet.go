@@ -0,0 +1,268 @@
+// Filename: internal/spreadsheet/spreadsheet.go
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX
+var ErrUnsupportedFormat = errors.New("unsupported file format, upload a .csv or .xlsx file")
+
+// maxPartSize limits how much of any single file inside an XLSX archive is decompressed
+const maxPartSize = 32 << 20
+
+// Read returns the rows of a CSV file, or of the first sheet of an XLSX workbook, choosing the format
+// from the file extension. Row i of the result is line i+1 of the file; blank lines are kept as empty
+// rows so callers can report line numbers.
+func Read(filename string, content []byte) ([][]string, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return ReadCSV(content)
+	case ".xlsx":
+		return ReadXLSX(content)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// ReadCSV parses a comma separated file. Rows may have different numbers of fields and a leading
+// byte order mark is ignored.
+func ReadCSV(content []byte) ([][]string, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+
+	rows := [][]string{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// The reader skips blank lines, so pad with empty rows to keep rows aligned with line numbers
+		line, _ := reader.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, record)
+	}
+
+	return rows, nil
+}
+
+/************************************************************************************************************/
+// XLSX
+/************************************************************************************************************/
+
+// xlsxWorkbook lists the sheets in a workbook
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships maps relationship IDs to the parts of the archive they point to
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a shared or inline string, either plain or made of formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String joins the runs of a formatted string
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// xlsxSharedStrings is the workbook string table referenced by cells of type s
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxSheet holds the populated rows of a worksheet
+type xlsxSheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX returns the cell text of the first sheet in an Office Open XML workbook. Formulas are read
+// as their cached results and numbers as they are stored.
+func ReadXLSX(content []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := parts[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("workbook sheet %s is missing", sheetPath)
+	}
+
+	var sheet xlsxSheet
+	if err := decodePart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, row := range sheet.Rows {
+		number := row.Number
+		if number < 1 {
+			number = len(rows) + 1
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, nil)
+		}
+
+		record := []string{}
+		for _, cell := range row.Cells {
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = len(record)
+			}
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				i, err := strconv.Atoi(cell.Value)
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", cell.Ref)
+				}
+				record[column] = shared.Items[i].String()
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			case "b":
+				record[column] = strings.ToUpper(strconv.FormatBool(cell.Value == "1"))
+			default:
+				record[column] = cell.Value
+			}
+		}
+
+		rows = append(rows, record)
+	}
+
+	return rows, nil
+}
+
+// firstSheetPath finds the archive path of the first sheet listed in the workbook
+func firstSheetPath(parts map[string]*zip.File) (string, error) {
+	workbookFile, ok := parts["xl/workbook.xml"]
+	if !ok {
+		return "", ErrUnsupportedFormat
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodePart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook does not contain any sheets")
+	}
+
+	relsFile, ok := parts["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+
+	var rels xlsxRelationships
+	if err := decodePart(relsFile, &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelationshipID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+
+	return "", errors.New("workbook sheet relationship is missing")
+}
+
+// decodePart unmarshals an XML file from the archive, refusing to inflate it beyond maxPartSize
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+	if err != nil {
+		return err
+	}
+	if len(content) > maxPartSize {
+		return fmt.Errorf("workbook part %s is too large", f.Name)
+	}
+
+	return xml.Unmarshal(content, v)
+}
+
+// columnIndex converts the letters of a cell reference such as "AB12" to a zero-based column index,
+// returning -1 when the reference has no column
+func columnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
@@ -0,0 +1,258 @@
+// Filename: internal/spreadsheet/spreadsheet_test.go
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Workbook parts shared by the XLSX tests
+const (
+	testWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Officers" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	testRels     = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/officers.xml"/></Relationships>`
+	testShared   = `<sst><si><t>First Name</t></si><si><t>Email</t></si><si><r><t>Jane </t></r><r><t>Doe</t></r></si></sst>`
+)
+
+// buildXLSX zips the given parts into an in-memory workbook
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to build workbook: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		expected [][]string
+		wantErr  error
+	}{
+		{name: "CSV extension", filename: "officers.csv", content: []byte("a,b\n"), expected: [][]string{{"a", "b"}}},
+		{name: "Extension is not case sensitive", filename: "OFFICERS.CSV", content: []byte("a,b\n"), expected: [][]string{{"a", "b"}}},
+		{name: "Unsupported extension", filename: "officers.txt", content: []byte("a,b\n"), wantErr: ErrUnsupportedFormat},
+		{name: "No extension", filename: "officers", content: []byte("a,b\n"), wantErr: ErrUnsupportedFormat},
+		{name: "XLSX extension on a file that is not a workbook", filename: "officers.xlsx", content: []byte("a,b\n"), wantErr: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Read(tt.filename, tt.content)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected error %v; got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.expected) {
+				t.Errorf("Expected %q; got %q", tt.expected, rows)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected [][]string
+		wantErr  bool
+	}{
+		{
+			name:     "Header and rows",
+			content:  "first_name,email\nJane,jane@example.com\n",
+			expected: [][]string{{"first_name", "email"}, {"Jane", "jane@example.com"}},
+		},
+		{
+			name:     "Byte order mark is ignored",
+			content:  "\xef\xbb\xbffirst_name,email\n",
+			expected: [][]string{{"first_name", "email"}},
+		},
+		{
+			name:     "Blank lines keep their place",
+			content:  "first_name\n\nJane\n\n\nJohn\n",
+			expected: [][]string{{"first_name"}, nil, {"Jane"}, nil, nil, {"John"}},
+		},
+		{
+			name:     "Rows with different numbers of fields",
+			content:  "a,b,c\nd\ne,f\n",
+			expected: [][]string{{"a", "b", "c"}, {"d"}, {"e", "f"}},
+		},
+		{
+			name:     "Quoted fields",
+			content:  "\"Doe, Jane\",\"said \"\"hi\"\"\"\n",
+			expected: [][]string{{"Doe, Jane", `said "hi"`}},
+		},
+		{
+			name:     "Empty file",
+			content:  "",
+			expected: [][]string{},
+		},
+		{
+			name:    "Unterminated quote",
+			content: "a,\"b\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadCSV([]byte(tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.expected) {
+				t.Errorf("Expected %q; got %q", tt.expected, rows)
+			}
+		})
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    map[string]string
+		expected [][]string
+		wantErr  bool
+	}{
+		{
+			name: "Shared, inline, rich and boolean cells",
+			parts: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": testRels,
+				"xl/sharedStrings.xml":       testShared,
+				"xl/worksheets/officers.xml": `<worksheet><sheetData>
+					<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+					<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="inlineStr"><is><t>jane@example.com</t></is></c><c r="C2" t="b"><v>1</v></c><c r="D2"><v>42</v></c></row>
+				</sheetData></worksheet>`,
+			},
+			expected: [][]string{{"First Name", "Email"}, {"Jane Doe", "jane@example.com", "TRUE", "42"}},
+		},
+		{
+			name: "Skipped rows and columns are left empty",
+			parts: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": testRels,
+				"xl/worksheets/officers.xml": `<worksheet><sheetData>
+					<row r="1"><c r="A1"><v>1</v></c></row>
+					<row r="3"><c r="C3"><v>2</v></c></row>
+				</sheetData></worksheet>`,
+			},
+			expected: [][]string{{"1"}, nil, {"", "", "2"}},
+		},
+		{
+			name: "Absolute relationship target",
+			parts: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="/xl/worksheets/officers.xml"/></Relationships>`,
+				"xl/worksheets/officers.xml": `<worksheet><sheetData><row r="1"><c r="A1"><v>x</v></c></row></sheetData></worksheet>`,
+			},
+			expected: [][]string{{"x"}},
+		},
+		{
+			name: "Missing relationships fall back to the first sheet",
+			parts: map[string]string{
+				"xl/workbook.xml":          testWorkbook,
+				"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1"><v>x</v></c></row></sheetData></worksheet>`,
+			},
+			expected: [][]string{{"x"}},
+		},
+		{
+			name: "Missing workbook",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": `<worksheet><sheetData/></worksheet>`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Workbook without sheets",
+			parts: map[string]string{
+				"xl/workbook.xml": `<workbook><sheets/></workbook>`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing sheet part",
+			parts: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": testRels,
+			},
+			wantErr: true,
+		},
+		{
+			name: "Shared string index out of range",
+			parts: map[string]string{
+				"xl/workbook.xml":            testWorkbook,
+				"xl/_rels/workbook.xml.rels": testRels,
+				"xl/sharedStrings.xml":       testShared,
+				"xl/worksheets/officers.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>9</v></c></row></sheetData></worksheet>`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadXLSX(buildXLSX(t, tt.parts))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error; got rows %q", rows)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.expected) {
+				t.Errorf("Expected %q; got %q", tt.expected, rows)
+			}
+		})
+	}
+}
+
+func TestColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected int
+	}{
+		{ref: "A1", expected: 0},
+		{ref: "B12", expected: 1},
+		{ref: "Z3", expected: 25},
+		{ref: "AA1", expected: 26},
+		{ref: "AB12", expected: 27},
+		{ref: "12", expected: -1},
+		{ref: "", expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := columnIndex(tt.ref); got != tt.expected {
+				t.Errorf("Expected %d; got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Filename: internal/totp/totp_test.go
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors, "12345678901234567890", base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, keeping the last six of the eight digits given there
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{unix: 59, expected: "287082"},
+		{unix: 1111111109, expected: "081804"},
+		{unix: 1111111111, expected: "050471"},
+		{unix: 1234567890, expected: "005924"},
+		{unix: 2000000000, expected: "279037"},
+		{unix: 20000000000, expected: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if code != tt.expected {
+				t.Errorf("Expected %s; got %s", tt.expected, code)
+			}
+		})
+	}
+}
+
+func TestCodeSecretFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{name: "Upper case", secret: rfcSecret},
+		{name: "Lower case", secret: strings.ToLower(rfcSecret)},
+		{name: "Not base32", secret: "not-a-secret!", wantErr: true},
+		{name: "Padded", secret: rfcSecret + "====", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(tt.secret, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error; got code %s", code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(code) != Digits {
+				t.Errorf("Expected a %d digit code; got %q", Digits, code)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Failed to generate code: %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name         string
+		secret       string
+		code         string
+		expectedStep int64
+		expectedOK   bool
+	}{
+		{name: "Current step", secret: rfcSecret, code: codeAt(current), expectedStep: current, expectedOK: true},
+		{name: "Previous step within skew", secret: rfcSecret, code: codeAt(current - 1), expectedStep: current - 1, expectedOK: true},
+		{name: "Next step within skew", secret: rfcSecret, code: codeAt(current + 1), expectedStep: current + 1, expectedOK: true},
+		{name: "Surrounding spaces are ignored", secret: rfcSecret, code: " " + codeAt(current) + " ", expectedStep: current, expectedOK: true},
+		{name: "Outside skew", secret: rfcSecret, code: codeAt(current - 2), expectedOK: false},
+		{name: "Too short", secret: rfcSecret, code: codeAt(current)[:Digits-1], expectedOK: false},
+		{name: "Too long", secret: rfcSecret, code: codeAt(current) + "0", expectedOK: false},
+		{name: "Empty", secret: rfcSecret, code: "", expectedOK: false},
+		{name: "Invalid secret", secret: "not-a-secret!", code: "123456", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Match(tt.secret, tt.code, now)
+			if ok != tt.expectedOK {
+				t.Fatalf("Expected match %v; got %v", tt.expectedOK, ok)
+			}
+			if ok && step != tt.expectedStep {
+				t.Errorf("Expected step %d; got %d", tt.expectedStep, step)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if first == second {
+		t.Error("Expected secrets to differ")
+	}
+
+	key, err := encoding.DecodeString(first)
+	if err != nil {
+		t.Fatalf("Expected an unpadded base32 secret: %v", err)
+	}
+	if len(key) != 20 {
+		t.Errorf("Expected a 160-bit secret; got %d bytes", len(key))
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Police Training", "jane@police-training.bz", rfcSecret)
+
+	if strings.Contains(uri, "+") {
+		t.Errorf("Expected spaces to be percent-encoded, got %s", uri)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("Failed to parse URI: %v", err)
+	}
+
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("Expected an otpauth://totp URI, got %s", uri)
+	}
+	if parsed.Path != "/Police Training:jane@police-training.bz" {
+		t.Errorf("Unexpected label %q", parsed.Path)
+	}
+
+	expected := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Police Training",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := parsed.Query()
+	for key, value := range expected {
+		if got := query.Get(key); got != value {
+			t.Errorf("Expected %s=%q; got %q", key, value, got)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+-- Permission for the compliance reports
+INSERT INTO permissions (code)
+SELECT 'reports:view'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'reports:view');
+
+-- Reports are available to the Admin role
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'reports:view'
+ON CONFLICT DO NOTHING;
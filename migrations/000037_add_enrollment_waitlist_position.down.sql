@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_training_enrollments_waitlist;
+
+ALTER TABLE "training_enrollments" DROP COLUMN IF EXISTS "waitlist_position";
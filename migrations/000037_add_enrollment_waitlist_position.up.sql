@@ -0,0 +1,5 @@
+-- Ordered waitlist for sessions that are at capacity
+ALTER TABLE "training_enrollments" ADD COLUMN "waitlist_position" integer;
+
+CREATE INDEX idx_training_enrollments_waitlist ON "training_enrollments" ("session_id", "waitlist_position")
+WHERE "waitlist_position" IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Permission to enroll an officer in a session that overlaps one they already attend
+INSERT INTO permissions (code)
+SELECT 'training:enrollments:override'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'training:enrollments:override');
+
+-- Overriding schedule clashes is reserved for the Admin role
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'training:enrollments:override'
+ON CONFLICT DO NOTHING;
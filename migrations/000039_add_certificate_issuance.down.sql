@@ -0,0 +1,11 @@
+DELETE FROM permissions WHERE code = 'certificates:issue';
+
+DROP INDEX IF EXISTS training_enrollments_certificate_number_key;
+
+ALTER TABLE training_enrollments
+    DROP COLUMN IF EXISTS certificate_issued_at,
+    DROP COLUMN IF EXISTS certificate_issued_by;
+
+DROP TABLE IF EXISTS certificate_sequences;
+
+ALTER TABLE regions DROP COLUMN IF EXISTS certificate_code;
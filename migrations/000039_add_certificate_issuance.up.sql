@@ -0,0 +1,35 @@
+-- Short region codes used in certificate numbers, e.g. BPD-2026-SOUTH-000123
+ALTER TABLE regions ADD COLUMN IF NOT EXISTS certificate_code text;
+
+UPDATE regions SET certificate_code = 'NORTH' WHERE region = 'Northern Region';
+UPDATE regions SET certificate_code = 'EAST' WHERE region = 'Eastern Division';
+UPDATE regions SET certificate_code = 'WEST' WHERE region = 'Western Region';
+UPDATE regions SET certificate_code = 'SOUTH' WHERE region = 'Southern Region';
+
+-- Last certificate number handed out for each year and region
+CREATE TABLE IF NOT EXISTS certificate_sequences (
+    "year" integer NOT NULL,
+    "region_id" bigint NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+    "last_number" integer NOT NULL DEFAULT 0,
+    PRIMARY KEY ("year", "region_id")
+);
+
+-- Who issued each certificate and when
+ALTER TABLE training_enrollments
+    ADD COLUMN IF NOT EXISTS certificate_issued_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS certificate_issued_at timestamp with time zone;
+
+CREATE UNIQUE INDEX IF NOT EXISTS training_enrollments_certificate_number_key
+    ON training_enrollments (certificate_number)
+    WHERE certificate_number IS NOT NULL;
+
+-- Permission for issuing certificates
+INSERT INTO permissions (code)
+SELECT 'certificates:issue'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'certificates:issue');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'certificates:issue'
+ON CONFLICT DO NOTHING;
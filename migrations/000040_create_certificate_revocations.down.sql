@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code = 'certificates:revoke';
+
+DROP TABLE IF EXISTS certificate_revocations;
+
+ALTER TABLE training_enrollments DROP COLUMN IF EXISTS certificate_revoked;
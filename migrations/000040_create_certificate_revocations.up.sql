@@ -0,0 +1,27 @@
+-- Enrollments whose certificate has been revoked and not yet reissued
+ALTER TABLE training_enrollments ADD COLUMN IF NOT EXISTS certificate_revoked boolean NOT NULL DEFAULT false;
+
+-- History of revoked certificate numbers
+CREATE TABLE IF NOT EXISTS certificate_revocations (
+    "id" bigserial PRIMARY KEY,
+    "enrollment_id" bigint NOT NULL REFERENCES training_enrollments(id) ON DELETE CASCADE,
+    "certificate_number" text NOT NULL,
+    "reason" text NOT NULL,
+    "revoked_by" bigint REFERENCES users(id) ON DELETE SET NULL,
+    "revoked_at" timestamp with time zone NOT NULL DEFAULT NOW(),
+    "reissued_number" text
+);
+
+CREATE INDEX IF NOT EXISTS certificate_revocations_enrollment_id_idx ON certificate_revocations (enrollment_id);
+CREATE INDEX IF NOT EXISTS certificate_revocations_certificate_number_idx ON certificate_revocations (certificate_number);
+
+-- Permission for revoking and reissuing certificates
+INSERT INTO permissions (code)
+SELECT 'certificates:revoke'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'certificates:revoke');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'certificates:revoke'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE code = 'audit:view';
+
+DROP TABLE IF EXISTS audit_events;
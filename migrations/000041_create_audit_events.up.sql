@@ -0,0 +1,27 @@
+-- Record of every change made through the API
+CREATE TABLE IF NOT EXISTS audit_events (
+    "id" bigserial PRIMARY KEY,
+    "actor_id" bigint REFERENCES users(id) ON DELETE SET NULL,
+    "entity_type" text NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "action" text NOT NULL,
+    "changes" jsonb NOT NULL DEFAULT '{}',
+    "request_id" text NOT NULL DEFAULT '',
+    "ip_address" text NOT NULL DEFAULT '',
+    "created_at" timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_actor_id_idx ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS audit_events_entity_idx ON audit_events (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_events_created_at_idx ON audit_events (created_at);
+
+-- Permission for reading the audit log
+INSERT INTO permissions (code)
+SELECT 'audit:view'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'audit:view');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'audit:view'
+ON CONFLICT DO NOTHING;
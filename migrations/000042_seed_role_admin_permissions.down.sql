@@ -0,0 +1 @@
+DELETE FROM permissions WHERE code IN ('roles:view', 'roles:edit');
@@ -0,0 +1,12 @@
+-- Permissions for viewing and managing roles, their permissions and the roles held by users
+INSERT INTO permissions (code)
+SELECT code
+FROM (VALUES ('roles:view'), ('roles:edit')) AS new_permissions (code)
+WHERE NOT EXISTS (SELECT 1 FROM permissions p WHERE p.code = new_permissions.code);
+
+-- Role administration is reserved for the Admin role
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code IN ('roles:view', 'roles:edit')
+ON CONFLICT DO NOTHING;
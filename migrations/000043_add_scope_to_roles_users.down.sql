@@ -0,0 +1,3 @@
+ALTER TABLE roles_users DROP CONSTRAINT IF EXISTS roles_users_single_scope_check;
+ALTER TABLE roles_users DROP COLUMN IF EXISTS formation_id;
+ALTER TABLE roles_users DROP COLUMN IF EXISTS region_id;
@@ -0,0 +1,6 @@
+-- A role can be held nationally or only within one region or one formation
+ALTER TABLE roles_users ADD COLUMN IF NOT EXISTS region_id bigint REFERENCES regions (id) ON DELETE CASCADE;
+ALTER TABLE roles_users ADD COLUMN IF NOT EXISTS formation_id bigint REFERENCES formations (id) ON DELETE CASCADE;
+
+ALTER TABLE roles_users ADD CONSTRAINT roles_users_single_scope_check
+    CHECK (region_id IS NULL OR formation_id IS NULL);
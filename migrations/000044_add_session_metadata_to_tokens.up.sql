@@ -0,0 +1,20 @@
+-- Identify tokens without exposing their hash, and record where and when authentication tokens are used
+ALTER TABLE tokens
+    ADD COLUMN id bigserial UNIQUE,
+    ADD COLUMN created_at timestamp NOT NULL DEFAULT NOW(),
+    ADD COLUMN last_used_at timestamp,
+    ADD COLUMN ip_address text NOT NULL DEFAULT '',
+    ADD COLUMN user_agent text NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS tokens_user_id_scope_idx ON tokens (user_id, scope);
+
+-- Forcing another user's sessions to end is reserved for the Admin role
+INSERT INTO permissions (code)
+SELECT 'sessions:revoke'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'sessions:revoke');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'sessions:revoke'
+ON CONFLICT DO NOTHING;
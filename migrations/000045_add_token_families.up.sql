@@ -0,0 +1,9 @@
+-- Access and refresh tokens issued by one login share a family, so replaying a rotated refresh token
+-- can revoke every token the login produced
+CREATE SEQUENCE IF NOT EXISTS token_family_seq;
+
+ALTER TABLE tokens
+    ADD COLUMN family_id bigint,
+    ADD COLUMN used_at timestamp;
+
+CREATE INDEX IF NOT EXISTS tokens_family_id_idx ON tokens (family_id);
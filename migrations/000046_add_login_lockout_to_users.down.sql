@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE code = 'users:unlock';
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS failed_login_attempts;
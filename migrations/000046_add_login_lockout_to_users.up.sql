@@ -0,0 +1,15 @@
+-- Count failed logins in a row so accounts can be locked against password guessing
+ALTER TABLE users
+    ADD COLUMN failed_login_attempts integer NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until timestamp with time zone;
+
+-- Unlocking accounts before their lock expires is reserved for the Admin role
+INSERT INTO permissions (code)
+SELECT 'users:unlock'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'users:unlock');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'users:unlock'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+-- TOTP secrets for two-factor authentication. A secret is only enforced once the user has confirmed it
+-- with a code; last_used_step stops a code being replayed within its window.
+CREATE TABLE IF NOT EXISTS user_totp (
+    "user_id" bigint PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    "secret" text NOT NULL,
+    "confirmed_at" timestamp with time zone,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+-- Single-use recovery codes for when the authenticator is lost, stored hashed like tokens
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "hash" bytea NOT NULL,
+    "used_at" timestamp with time zone,
+    UNIQUE ("user_id", "hash")
+);
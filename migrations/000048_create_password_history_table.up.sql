@@ -0,0 +1,10 @@
+-- Hashes of users' previous passwords, newest last, so a password change can refuse recent ones. Users'
+-- current passwords stay in users.password_hash.
+CREATE TABLE IF NOT EXISTS password_history (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "hash" bytea NOT NULL,
+    "created_at" timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS password_history_user_id_idx ON password_history (user_id, id);
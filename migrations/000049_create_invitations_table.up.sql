@@ -0,0 +1,21 @@
+-- Invitations to join, one per invited user. The user is created inactive when invited, with their roles
+-- and officer record, and chooses a password when they accept with the token sent to them.
+CREATE TABLE IF NOT EXISTS invitations (
+    "id" bigserial PRIMARY KEY,
+    "user_id" bigint NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    "invited_by" bigint REFERENCES users (id) ON DELETE SET NULL,
+    "expiry" timestamp with time zone NOT NULL,
+    "accepted_at" timestamp with time zone,
+    "created_at" timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+-- Inviting users replaces open registration and is reserved for the Admin role
+INSERT INTO permissions (code)
+SELECT 'users:invite'
+WHERE NOT EXISTS (SELECT 1 FROM permissions WHERE code = 'users:invite');
+
+INSERT INTO roles_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.role = 'Admin' AND p.code = 'users:invite'
+ON CONFLICT DO NOTHING;
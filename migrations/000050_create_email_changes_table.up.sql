@@ -0,0 +1,9 @@
+-- Email address changes waiting for the new address to be confirmed, at most one per user. The user's
+-- email is only replaced once the token sent to the new address is used.
+CREATE TABLE IF NOT EXISTS email_changes (
+    "user_id" bigint PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    "new_email" text NOT NULL,
+    "requested_by" bigint REFERENCES users (id) ON DELETE SET NULL,
+    "expiry" timestamp with time zone NOT NULL,
+    "created_at" timestamp with time zone NOT NULL DEFAULT NOW()
+);